@@ -0,0 +1,87 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"yandex-team.ru/bstask/internal/app"
+	"yandex-team.ru/bstask/internal/usecase/order"
+)
+
+type assignOutput struct {
+	Date           string                `json:"date"`
+	Strategy       string                `json:"strategy"`
+	DryRun         bool                  `json:"dry_run"`
+	OrdersAssigned int                   `json:"orders_assigned"`
+	Couriers       []assignOutputCourier `json:"couriers"`
+	SLABreaching   []uint64              `json:"sla_breaching"`
+}
+
+type assignOutputCourier struct {
+	CourierId uint64              `json:"courier_id"`
+	Groups    []assignOutputGroup `json:"orders"`
+}
+
+type assignOutputGroup struct {
+	GroupOrderId uint64   `json:"group_order_id"`
+	OrderIds     []uint64 `json:"order_ids"`
+}
+
+func assign(ctx context.Context, a *app.App, args []string) error {
+	fs := flag.NewFlagSet("assign", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+
+	date := fs.String("date", time.Now().UTC().Format("2006-01-02"), "day to assign, YYYY-MM-DD")
+	strategy := fs.String("strategy", order.DEFAULT_ASSIGN_STRATEGY, "one of: "+strings.Join(order.AssignStrategies(), ", "))
+	dryRun := fs.Bool("dry-run", false, "print assignment without saving it")
+
+	if err := fs.Parse(args); err != nil || fs.NArg() != 0 {
+		return errUsage
+	}
+
+	assignDate, err := time.Parse("2006-01-02", *date)
+	if err != nil {
+		return err
+	}
+
+	res, err := a.OrderUseCase.Assign(ctx, assignDate, order.AssignOptions{
+		Strategy: *strategy,
+		DryRun:   *dryRun,
+	})
+	if err != nil {
+		return err
+	}
+
+	out := assignOutput{
+		Date:           assignDate.Format("2006-01-02"),
+		Strategy:       *strategy,
+		DryRun:         *dryRun,
+		OrdersAssigned: res.OrdersCount(),
+		Couriers:       []assignOutputCourier{},
+		SLABreaching:   []uint64{},
+	}
+	for _, c := range res.Couriers {
+		oc := assignOutputCourier{CourierId: c.CourierId, Groups: []assignOutputGroup{}}
+		for _, g := range c.Orders {
+			og := assignOutputGroup{GroupOrderId: g.GroupOrderId, OrderIds: []uint64{}}
+			for _, o := range g.Orders {
+				og.OrderIds = append(og.OrderIds, o.ID)
+			}
+			oc.Groups = append(oc.Groups, og)
+		}
+		out.Couriers = append(out.Couriers, oc)
+	}
+	for _, o := range res.SLABreaching {
+		out.SLABreaching = append(out.SLABreaching, o.ID)
+	}
+
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+
+	return enc.Encode(out)
+}
@@ -0,0 +1,251 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"yandex-team.ru/bstask/internal/app"
+	"yandex-team.ru/bstask/internal/entity"
+	"yandex-team.ru/bstask/internal/importer"
+	"yandex-team.ru/bstask/internal/usecase/bulk"
+	"yandex-team.ru/bstask/internal/usecase/courier"
+	"yandex-team.ru/bstask/internal/usecase/order"
+	"yandex-team.ru/bstask/pkg/geo"
+)
+
+// JSON files have the same shape as bodies of POST /couriers and POST /orders,
+// CSV and NDJSON ones are streamed like in POST /couriers/import.
+
+type couriersFile struct {
+	Couriers []struct {
+		CourierType                 string            `json:"courier_type"`
+		Regions                     []int32           `json:"regions"`
+		WorkingHours                []string          `json:"working_hours"`
+		Breaks                      []string          `json:"breaks"`
+		MaxDeliveriesPerShift       *uint32           `json:"max_deliveries_per_shift"`
+		MaxContinuousDrivingMinutes *uint32           `json:"max_continuous_driving_minutes"`
+		Capabilities                []string          `json:"capabilities"`
+		ShiftStores                 map[string]uint64 `json:"shift_stores"`
+	} `json:"couriers"`
+}
+
+type ordersFile struct {
+	Orders []struct {
+		Weight        float64    `json:"weight"`
+		Regions       int32      `json:"regions"`
+		DeliveryHours []string   `json:"delivery_hours"`
+		Cost          uint32     `json:"cost"`
+		Lat           *float64   `json:"lat"`
+		Lon           *float64   `json:"lon"`
+		Priority      string     `json:"priority"`
+		Deadline      *time.Time `json:"deadline"`
+		Volume        *float64   `json:"volume"`
+		Dimensions    *struct {
+			Length float64 `json:"length"`
+			Width  float64 `json:"width"`
+			Height float64 `json:"height"`
+		} `json:"dimensions"`
+		Items []struct {
+			SKU        string  `json:"sku"`
+			Quantity   uint32  `json:"quantity"`
+			UnitWeight float64 `json:"unit_weight"`
+		} `json:"items"`
+		Handling []string `json:"handling"`
+		StoreID  *uint64  `json:"store_id"`
+	} `json:"orders"`
+}
+
+type importFlags struct {
+	path   string
+	format string
+	mode   bulk.Mode
+}
+
+func parseImportFlags(name string, args []string) (importFlags, error) {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+
+	format := fs.String("format", "", "json, csv or ndjson, by default guessed from file extension")
+	mode := fs.String("mode", string(bulk.MODE_ATOMIC), "atomic or best_effort, for csv and ndjson")
+
+	if err := fs.Parse(args); err != nil || fs.NArg() != 1 || !bulk.ValidMode(*mode) {
+		return importFlags{}, errUsage
+	}
+
+	f := importFlags{path: fs.Arg(0), format: *format, mode: bulk.Mode(*mode)}
+	if f.format == "" {
+		switch strings.ToLower(filepath.Ext(f.path)) {
+		case ".csv":
+			f.format = "csv"
+		case ".ndjson", ".jsonl":
+			f.format = "ndjson"
+		default:
+			f.format = "json"
+		}
+	}
+
+	return f, nil
+}
+
+func (f importFlags) streamFormat() (importer.Format, bool) {
+	switch f.format {
+	case "csv":
+		return importer.FORMAT_CSV, true
+	case "ndjson":
+		return importer.FORMAT_NDJSON, true
+	}
+
+	return "", false
+}
+
+func importCouriers(ctx context.Context, a *app.App, args []string) error {
+	f, err := parseImportFlags("couriers import", args)
+	if err != nil {
+		return err
+	}
+
+	src, err := openInput(f.path)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	if format, ok := f.streamFormat(); ok {
+		rows, err := importer.NewCourierReader(format, src)
+		if err != nil {
+			return err
+		}
+
+		res, err := a.CourierUseCase.Import(ctx, rows, f.mode)
+		if err != nil {
+			return err
+		}
+
+		return printImportResult("couriers", res)
+	}
+
+	var file couriersFile
+	if err := json.NewDecoder(src).Decode(&file); err != nil {
+		return err
+	}
+
+	toCreate := []courier.CourierToCreateDTO{}
+	for _, c := range file.Couriers {
+		toCreate = append(toCreate, courier.CourierToCreateDTO{
+			CourierType:                 c.CourierType,
+			Regions:                     c.Regions,
+			WorkingHours:                c.WorkingHours,
+			Breaks:                      c.Breaks,
+			MaxDeliveriesPerShift:       c.MaxDeliveriesPerShift,
+			MaxContinuousDrivingMinutes: c.MaxContinuousDrivingMinutes,
+			Capabilities:                c.Capabilities,
+			ShiftStores:                 c.ShiftStores,
+		})
+	}
+
+	saved, err := a.CourierUseCase.CreateCouriers(ctx, toCreate)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("imported %d couriers\n", len(*saved))
+
+	return nil
+}
+
+func importOrders(ctx context.Context, a *app.App, args []string) error {
+	f, err := parseImportFlags("orders import", args)
+	if err != nil {
+		return err
+	}
+
+	src, err := openInput(f.path)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	if format, ok := f.streamFormat(); ok {
+		rows, err := importer.NewOrderReader(format, src)
+		if err != nil {
+			return err
+		}
+
+		res, err := a.OrderUseCase.Import(ctx, rows, f.mode)
+		if err != nil {
+			return err
+		}
+
+		return printImportResult("orders", res)
+	}
+
+	var file ordersFile
+	if err := json.NewDecoder(src).Decode(&file); err != nil {
+		return err
+	}
+
+	toCreate := []order.OrderToCreateDTO{}
+	for _, o := range file.Orders {
+		dto := order.OrderToCreateDTO{
+			Weight:        o.Weight,
+			Regions:       o.Regions,
+			DeliveryHours: o.DeliveryHours,
+			Cost:          o.Cost,
+			Priority:      o.Priority,
+			Deadline:      o.Deadline,
+			Volume:        o.Volume,
+			StoreID:       o.StoreID,
+		}
+		if d := o.Dimensions; d != nil {
+			dto.Dimensions = &entity.Dimensions{Length: d.Length, Width: d.Width, Height: d.Height}
+		}
+		for _, i := range o.Items {
+			dto.Items = append(dto.Items, order.OrderItemToCreateDTO{SKU: i.SKU, Quantity: i.Quantity, UnitWeight: i.UnitWeight})
+		}
+		dto.Handling = o.Handling
+		if o.Lat != nil && o.Lon != nil {
+			dto.Location = &geo.Point{Lat: *o.Lat, Lon: *o.Lon}
+		}
+
+		toCreate = append(toCreate, dto)
+	}
+
+	saved, err := a.OrderUseCase.CreateOrders(ctx, toCreate)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("imported %d orders\n", len(*saved))
+
+	return nil
+}
+
+// printImportResult fails the command if any row was rejected.
+func printImportResult(entity string, res *bulk.Result) error {
+	for _, e := range res.Errors {
+		fmt.Fprintf(os.Stderr, "line %d: %s\n", e.Line, e.Err)
+	}
+
+	fmt.Printf("imported %d %s, rejected %d (%s)\n", res.Imported, entity, res.Failed, res.Mode)
+
+	if res.Failed > 0 {
+		return fmt.Errorf("%d rows rejected", res.Failed)
+	}
+
+	return nil
+}
+
+func openInput(path string) (io.ReadCloser, error) {
+	if path == "-" {
+		return io.NopCloser(os.Stdin), nil
+	}
+
+	return os.Open(path)
+}
@@ -0,0 +1,115 @@
+// bstaskctl runs batch operations against the service database
+// using the same usecases as the http server.
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"go.uber.org/zap"
+	"yandex-team.ru/bstask/config"
+	"yandex-team.ru/bstask/internal/app"
+	"yandex-team.ru/bstask/pkg/db/postgresql"
+	"yandex-team.ru/bstask/pkg/logger"
+)
+
+const CONNECT_TIMEOUT = 30 * time.Second
+
+const usage = `usage: bstaskctl <command> [flags] [args]
+
+commands:
+  couriers import [--format F] [--mode M] <file>
+                                               create couriers from json, csv or ndjson file
+  orders import [--format F] [--mode M] <file>
+                                               create orders from json, csv or ndjson file
+  assign --date YYYY-MM-DD [--strategy NAME] [--dry-run]
+                                               assign orders of the day to couriers
+  report payroll --month YYYY-MM               print earnings of couriers as csv
+  db seed --fake [--couriers N] [--orders N]   fill database with fake data
+
+file "-" means stdin.`
+
+// errUsage makes main print usage and exit with code 2.
+var errUsage = errors.New("invalid usage")
+
+type command func(ctx context.Context, a *app.App, args []string) error
+
+var commands = map[string]command{
+	"couriers import": importCouriers,
+	"orders import":   importOrders,
+	"assign":          assign,
+	"report payroll":  reportPayroll,
+	"db seed":         seed,
+}
+
+func main() {
+	os.Exit(run(os.Args[1:]))
+}
+
+func run(args []string) int {
+	cmd, args := lookupCommand(args)
+	if cmd == nil {
+		fmt.Fprintln(os.Stderr, usage)
+		return 2
+	}
+
+	appConf := config.NewAppConfig()
+
+	log, err := logger.New(appConf.Env)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	defer log.Sync()
+	logger.SetGlobal(log)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	db := app.OpenDB(config.DatabaseConf())
+
+	connectCtx, cancel := context.WithTimeout(ctx, CONNECT_TIMEOUT)
+	defer cancel()
+	if err := postgresql.WaitConnected(connectCtx, db); err != nil {
+		log.Error("database is unavailable", zap.Error(err))
+		return 1
+	}
+
+	a, err := app.New(db)
+	if err != nil {
+		log.Error("failed to init app", zap.Error(err))
+		return 1
+	}
+
+	if err := cmd(ctx, a, args); err != nil {
+		if errors.Is(err, errUsage) {
+			fmt.Fprintln(os.Stderr, usage)
+			return 2
+		}
+		log.Error("command failed", logger.ErrorFields(err)...)
+		return 1
+	}
+
+	return 0
+}
+
+// lookupCommand matches one or two leading words against known commands.
+func lookupCommand(args []string) (command, []string) {
+	if len(args) >= 2 {
+		if cmd, ok := commands[args[0]+" "+args[1]]; ok {
+			return cmd, args[2:]
+		}
+	}
+	if len(args) >= 1 {
+		if cmd, ok := commands[args[0]]; ok {
+			return cmd, args[1:]
+		}
+	}
+
+	return nil, nil
+}
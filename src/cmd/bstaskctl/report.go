@@ -0,0 +1,54 @@
+package main
+
+import (
+	"context"
+	"encoding/csv"
+	"flag"
+	"io"
+	"os"
+	"strconv"
+	"time"
+
+	"yandex-team.ru/bstask/internal/app"
+)
+
+func reportPayroll(ctx context.Context, a *app.App, args []string) error {
+	fs := flag.NewFlagSet("report payroll", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+
+	month := fs.String("month", time.Now().UTC().Format("2006-01"), "month of report, YYYY-MM")
+
+	if err := fs.Parse(args); err != nil || fs.NArg() != 0 {
+		return errUsage
+	}
+
+	startDate, err := time.Parse("2006-01", *month)
+	if err != nil {
+		return err
+	}
+	endDate := startDate.AddDate(0, 1, 0)
+
+	payroll, err := a.CourierUseCase.Payroll(ctx, startDate, endDate)
+	if err != nil {
+		return err
+	}
+
+	w := csv.NewWriter(os.Stdout)
+	w.Write([]string{"courier_id", "courier_type", "earnings", "rating"})
+	for _, p := range payroll {
+		rating := ""
+		if p.Rating != nil {
+			rating = strconv.Itoa(int(*p.Rating))
+		}
+
+		w.Write([]string{
+			strconv.FormatUint(p.Courier.ID, 10),
+			string(p.Courier.CourierType),
+			strconv.Itoa(int(p.Earnings)),
+			rating,
+		})
+	}
+	w.Flush()
+
+	return w.Error()
+}
@@ -0,0 +1,122 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"math"
+	"math/rand"
+	"time"
+
+	"yandex-team.ru/bstask/internal/app"
+	"yandex-team.ru/bstask/internal/entity"
+	"yandex-team.ru/bstask/internal/usecase/courier"
+	"yandex-team.ru/bstask/internal/usecase/order"
+)
+
+// Entities are created in chunks to keep transactions short.
+const SEED_CHUNK_SIZE = 500
+
+func seed(ctx context.Context, a *app.App, args []string) error {
+	fs := flag.NewFlagSet("db seed", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+
+	fake := fs.Bool("fake", false, "generate random couriers and orders")
+	couriersCount := fs.Int("couriers", 20, "number of couriers")
+	ordersCount := fs.Int("orders", 200, "number of orders")
+	regions := fs.Int("regions", 5, "regions are picked from 1..N")
+	randSeed := fs.Int64("seed", time.Now().UnixNano(), "seed of random generator")
+
+	if err := fs.Parse(args); err != nil || fs.NArg() != 0 {
+		return errUsage
+	}
+	// the only supported source for now
+	if !*fake || *regions < 1 {
+		return errUsage
+	}
+
+	r := rand.New(rand.NewSource(*randSeed))
+
+	for created := 0; created < *couriersCount; {
+		n := min(SEED_CHUNK_SIZE, *couriersCount-created)
+
+		chunk := make([]courier.CourierToCreateDTO, 0, n)
+		for i := 0; i < n; i++ {
+			chunk = append(chunk, fakeCourier(r, *regions))
+		}
+
+		if _, err := a.CourierUseCase.CreateCouriers(ctx, chunk); err != nil {
+			return err
+		}
+		created += n
+	}
+
+	for created := 0; created < *ordersCount; {
+		n := min(SEED_CHUNK_SIZE, *ordersCount-created)
+
+		chunk := make([]order.OrderToCreateDTO, 0, n)
+		for i := 0; i < n; i++ {
+			chunk = append(chunk, fakeOrder(r, *regions))
+		}
+
+		if _, err := a.OrderUseCase.CreateOrders(ctx, chunk); err != nil {
+			return err
+		}
+		created += n
+	}
+
+	fmt.Printf("seeded %d couriers and %d orders (seed %d)\n", *couriersCount, *ordersCount, *randSeed)
+
+	return nil
+}
+
+func fakeCourier(r *rand.Rand, regions int) courier.CourierToCreateDTO {
+	types := entity.ValidCourierTypes()
+
+	picked := r.Perm(regions)[:1+r.Intn(min(3, regions))]
+	courierRegions := []int32{}
+	for _, p := range picked {
+		courierRegions = append(courierRegions, int32(p+1))
+	}
+
+	// morning shift, optionally followed by evening one
+	workingHours := []string{fakeInterval(r, 7, 13, 3)}
+	if r.Intn(2) == 0 {
+		workingHours = append(workingHours, fakeInterval(r, 15, 20, 3))
+	}
+
+	return courier.CourierToCreateDTO{
+		CourierType:  types[r.Intn(len(types))],
+		Regions:      courierRegions,
+		WorkingHours: workingHours,
+	}
+}
+
+func fakeOrder(r *rand.Rand, regions int) order.OrderToCreateDTO {
+	return order.OrderToCreateDTO{
+		Weight:        math.Round((0.1+r.Float64()*20)*100) / 100,
+		Regions:       int32(1 + r.Intn(regions)),
+		DeliveryHours: []string{fakeInterval(r, 8, 20, 2)},
+		Cost:          uint32(100 + r.Intn(1900)),
+	}
+}
+
+// fakeInterval returns HH:MM-HH:MM interval starting in [fromHour, toHour)
+// and lasting 1..maxHours hours.
+func fakeInterval(r *rand.Rand, fromHour, toHour, maxHours int) string {
+	start := fromHour + r.Intn(toHour-fromHour)
+	end := start + 1 + r.Intn(maxHours)
+	if end > 23 {
+		end = 23
+	}
+
+	return fmt.Sprintf("%02d:%02d-%02d:00", start, 30*r.Intn(2), end)
+}
+
+func min(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+
+	"gorm.io/gorm"
+	"yandex-team.ru/bstask/migrations"
+	"yandex-team.ru/bstask/pkg/db/migrator"
+)
+
+const migrateUsage = "app migrate up|down|status|goto N"
+
+// runMigrate handles `app migrate ...` subcommands.
+func runMigrate(db *gorm.DB, args []string) error {
+	if len(args) == 0 {
+		return errors.New("usage: " + migrateUsage)
+	}
+
+	sqlDB, err := db.DB()
+	if err != nil {
+		return err
+	}
+
+	m, err := migrator.New(sqlDB, migrations.FS, MIGRATIONS_TABLE)
+	if err != nil {
+		return err
+	}
+	defer m.Close()
+
+	switch args[0] {
+	case "up":
+		err = m.Up()
+	case "down":
+		err = m.Down()
+	case "goto":
+		if len(args) != 2 {
+			return errors.New("usage: " + migrateUsage)
+		}
+
+		var version uint64
+		version, err = strconv.ParseUint(args[1], 10, 32)
+		if err != nil {
+			return fmt.Errorf("invalid version %q: %w", args[1], err)
+		}
+
+		err = m.Goto(uint(version))
+	case "status":
+	default:
+		return errors.New("usage: " + migrateUsage)
+	}
+	if err != nil {
+		return err
+	}
+
+	status, err := m.Status()
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("version: %d\ndirty: %t\nlatest: %d\npending: %t\n", status.Version, status.Dirty, status.Latest, status.Pending())
+
+	return nil
+}
+
+// autoMigrate applies pending migrations on boot.
+func autoMigrate(db *gorm.DB) error {
+	sqlDB, err := db.DB()
+	if err != nil {
+		return err
+	}
+
+	m, err := migrator.New(sqlDB, migrations.FS, MIGRATIONS_TABLE)
+	if err != nil {
+		return err
+	}
+	defer m.Close()
+
+	return m.Up()
+}
@@ -1,60 +1,155 @@
 package main
 
 import (
-	trmgorm "github.com/avito-tech/go-transaction-manager/gorm"
-	"github.com/avito-tech/go-transaction-manager/trm/manager"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	nethttp "net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
 	"yandex-team.ru/bstask/config"
+	"yandex-team.ru/bstask/internal/app"
+	"yandex-team.ru/bstask/internal/health"
 	"yandex-team.ru/bstask/internal/http"
 	"yandex-team.ru/bstask/internal/http/controller"
-	"yandex-team.ru/bstask/internal/repository/repositories"
-	"yandex-team.ru/bstask/internal/usecase/courier"
-	"yandex-team.ru/bstask/internal/usecase/order"
+	"yandex-team.ru/bstask/internal/metrics"
+	"yandex-team.ru/bstask/migrations"
+	"yandex-team.ru/bstask/pkg/db/migrator"
 	"yandex-team.ru/bstask/pkg/db/postgresql"
+	"yandex-team.ru/bstask/pkg/logger"
+	"yandex-team.ru/bstask/pkg/tracing"
 )
 
+const SHUTDOWN_TIMEOUT = 10 * time.Second
+
+// Table where golang-migrate keeps schema version.
+const MIGRATIONS_TABLE = "migrations"
+
 // TODO поработать с созданием и проверкой delivery_groups
 // TODO проверить, что количество регионов соответствую типу курьера
 func main() {
 
+	appConf := config.NewAppConfig()
+
+	flag.BoolVar(&appConf.AutoMigrate, "auto-migrate", appConf.AutoMigrate, "apply pending migrations before serving")
+	flag.Usage = func() {
+		fmt.Fprintln(flag.CommandLine.Output(), "usage: app [-auto-migrate]")
+		fmt.Fprintln(flag.CommandLine.Output(), "       "+migrateUsage)
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	log, err := logger.New(appConf.Env)
+	if err != nil {
+		panic(err)
+	}
+	defer log.Sync()
+	logger.SetGlobal(log)
+
 	dbConf := config.DatabaseConf()
-	db := postgresql.GetInstance(
-		dbConf.Pgsql.Host,
-		dbConf.Pgsql.Username,
-		dbConf.Pgsql.Password,
-		dbConf.Pgsql.Database,
-		dbConf.Pgsql.Port,
-	)
+	db := app.OpenDB(dbConf)
 
-	appConf := config.NewAppConfig()
+	switch flag.Arg(0) {
+	case "":
+		serve(appConf, dbConf, db)
+	case "migrate":
+		if err := runMigrate(db, flag.Args()[1:]); err != nil {
+			log.Fatal("migration failed", zap.Error(err))
+		}
+	default:
+		flag.Usage()
+		os.Exit(2)
+	}
+}
+
+func serve(appConf config.AppConfig, dbConf *config.DatabaseConfig, db *gorm.DB) {
+
+	log := logger.Global()
 
-	// db.AutoMigrate(
-	// 	&repositories.Courier{},
-	// 	&repositories.CourierWorkingHours{},
-	// 	&repositories.Order{},
-	// 	&repositories.OrderDeliveryHours{},
-	// 	&repositories.DeliveryGroup{},
-	// )
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
 
-	courierRepo := repositories.NewCourierRepo(db, trmgorm.DefaultCtxGetter)
-	orderRepo := repositories.NewOrderRepo(db, trmgorm.DefaultCtxGetter)
-	deliveryGroupRepo := repositories.NewOrderGroupRepo(db, trmgorm.DefaultCtxGetter)
+	if appConf.AutoMigrate {
+		if err := postgresql.WaitConnected(ctx, db); err != nil {
+			log.Fatal("database is unavailable", zap.Error(err))
+		}
+		if err := autoMigrate(db); err != nil {
+			log.Fatal("failed to apply migrations", zap.Error(err))
+		}
+		log.Info("migrations applied")
+	}
+
+	shutdownTracing, err := tracing.Init(ctx, tracing.Config{
+		ServiceName: "bstask",
+		Exporter:    appConf.Tracing.Exporter,
+		File:        appConf.Tracing.File,
+		SampleRatio: appConf.Tracing.SampleRatio,
+	})
+	if err != nil {
+		log.Fatal("failed to init tracing", zap.Error(err))
+	}
 
-	m, err := manager.New(trmgorm.NewDefaultFactory(db))
+	a, err := app.New(db)
 	if err != nil {
 		panic(err)
 	}
 
-	courierUseCase := courier.New(m, courierRepo, orderRepo, deliveryGroupRepo)
-	orderUseCase := order.New(m, orderRepo, courierRepo, deliveryGroupRepo)
+	if err := db.Use(tracing.GormPlugin{}); err != nil {
+		log.Fatal("failed to register db tracing", zap.Error(err))
+	}
+	if err := metrics.RegisterDB(db); err != nil {
+		log.Fatal("failed to register db metrics", zap.Error(err))
+	}
+	if err := metrics.RegisterUnassignedOrders(a.OrderRepo.CountUnassigned); err != nil {
+		log.Fatal("failed to register orders metrics", zap.Error(err))
+	}
+
+	latestMigration, err := migrator.LatestVersion(migrations.FS)
+	if err != nil {
+		log.Fatal("failed to read migrations", zap.Error(err))
+	}
+
+	readiness := health.NewChecker().
+		Add("postgres", health.PostgresPing(db)).
+		Add("migrations", health.Migrations(db, MIGRATIONS_TABLE, latestMigration)).
+		Add("pool", health.PoolSaturation(db, dbConf.Pool.SaturationThreshold))
 
 	cs := http.Controllers{
-		CourierController: controller.NewCourierController(courierUseCase),
-		OrderController:   controller.NewOrderController(orderUseCase),
+		CourierController: controller.NewCourierController(a.CourierUseCase),
+		OrderController:   controller.NewOrderController(a.OrderUseCase),
+		HealthController:  controller.NewHealthController(readiness),
+		ExportController:  controller.NewExportController(a.OrderUseCase, a.CourierUseCase),
+		RegionController:  controller.NewRegionController(a.RegionUseCase),
+		StoreController:   controller.NewStoreController(a.StoreUseCase),
 	}
-	r := http.NewRouter(cs)
+	r := http.NewRouter(cs, appConf.Http)
 
 	e := http.NewHttpServer(appConf)
 	r.SetupRoutes(e)
 
-	e.Logger.Fatal(e.Start(":8080"))
+	go func() {
+		log.Info("starting http server", zap.String("addr", ":8080"))
+		if err := e.Start(":8080"); err != nil && !errors.Is(err, nethttp.ErrServerClosed) {
+			log.Fatal("http server stopped", zap.Error(err))
+		}
+	}()
+
+	<-ctx.Done()
+	log.Info("shutting down")
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), SHUTDOWN_TIMEOUT)
+	defer cancel()
+
+	if err := e.Shutdown(shutdownCtx); err != nil {
+		log.Error("failed to shutdown http server", zap.Error(err))
+	}
+	if err := shutdownTracing(shutdownCtx); err != nil {
+		log.Error("failed to flush traces", zap.Error(err))
+	}
 }
@@ -1,16 +1,116 @@
 package config
 
-import "os"
+import (
+	"os"
+	"strconv"
+	"time"
+)
 
 type AppConfig struct {
-	Env string // test, dev or prod
+	Env     string // test, dev or prod
+	Http    HttpConfig
+	Tracing TracingConfig
+	// Apply pending migrations before serving.
+	AutoMigrate bool
+}
+
+type HttpConfig struct {
+	// Default deadline for every request.
+	RequestTimeout time.Duration
+	// Deadline for heavy routes, like POST /orders/assign.
+	LongRequestTimeout time.Duration
+	// Deadline for streaming exports.
+	ExportTimeout time.Duration
+}
+
+type TracingConfig struct {
+	// none, otlp, stdout or file
+	Exporter string
+	// Target of `file` exporter.
+	File        string
+	SampleRatio float64
 }
 
 func NewAppConfig() AppConfig {
 
 	conf := AppConfig{
 		Env: os.Getenv("APP_ENV"),
+		Http: HttpConfig{
+			RequestTimeout:     durationFromEnv("APP_REQUEST_TIMEOUT", 10*time.Second),
+			LongRequestTimeout: durationFromEnv("APP_LONG_REQUEST_TIMEOUT", 2*time.Minute),
+		},
+		Tracing: TracingConfig{
+			Exporter:    stringFromEnv("APP_TRACES_EXPORTER", "none"),
+			File:        stringFromEnv("APP_TRACES_FILE", "traces.json"),
+			SampleRatio: floatFromEnv("APP_TRACES_SAMPLE_RATIO", 1),
+		},
+		AutoMigrate: boolFromEnv("APP_AUTO_MIGRATE", false),
 	}
 
 	return conf
 }
+
+func durationFromEnv(key string, def time.Duration) time.Duration {
+	v := os.Getenv(key)
+	if v == "" {
+		return def
+	}
+
+	d, err := time.ParseDuration(v)
+	if err != nil {
+		return def
+	}
+
+	return d
+}
+
+func stringFromEnv(key string, def string) string {
+	v := os.Getenv(key)
+	if v == "" {
+		return def
+	}
+
+	return v
+}
+
+func floatFromEnv(key string, def float64) float64 {
+	v := os.Getenv(key)
+	if v == "" {
+		return def
+	}
+
+	f, err := strconv.ParseFloat(v, 64)
+	if err != nil {
+		return def
+	}
+
+	return f
+}
+
+func intFromEnv(key string, def int) int {
+	v := os.Getenv(key)
+	if v == "" {
+		return def
+	}
+
+	i, err := strconv.Atoi(v)
+	if err != nil {
+		return def
+	}
+
+	return i
+}
+
+func boolFromEnv(key string, def bool) bool {
+	v := os.Getenv(key)
+	if v == "" {
+		return def
+	}
+
+	b, err := strconv.ParseBool(v)
+	if err != nil {
+		return def
+	}
+
+	return b
+}
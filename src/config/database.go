@@ -1,5 +1,7 @@
 package config
 
+import "time"
+
 type PgsqlConnectionConf struct {
 	Host     string
 	Port     int
@@ -8,8 +10,17 @@ type PgsqlConnectionConf struct {
 	Password string
 }
 
+type PgsqlPoolConf struct {
+	MaxOpenConns    int
+	MaxIdleConns    int
+	ConnMaxLifetime time.Duration
+	// Share of busy connections, after which service is not ready.
+	SaturationThreshold float64
+}
+
 type DatabaseConfig struct {
 	Pgsql PgsqlConnectionConf
+	Pool  PgsqlPoolConf
 }
 
 func DatabaseConf() *DatabaseConfig {
@@ -21,5 +32,11 @@ func DatabaseConf() *DatabaseConfig {
 			Username: "postgres",
 			Password: "password",
 		},
+		Pool: PgsqlPoolConf{
+			MaxOpenConns:        intFromEnv("APP_DB_MAX_OPEN_CONNS", 20),
+			MaxIdleConns:        intFromEnv("APP_DB_MAX_IDLE_CONNS", 10),
+			ConnMaxLifetime:     durationFromEnv("APP_DB_CONN_MAX_LIFETIME", 30*time.Minute),
+			SaturationThreshold: floatFromEnv("APP_DB_POOL_SATURATION_THRESHOLD", 0.9),
+		},
 	}
 }
@@ -0,0 +1,76 @@
+// Package app wires repositories and usecases, shared by the server and bstaskctl.
+package app
+
+import (
+	trmgorm "github.com/avito-tech/go-transaction-manager/gorm"
+	"github.com/avito-tech/go-transaction-manager/trm"
+	"github.com/avito-tech/go-transaction-manager/trm/manager"
+	"gorm.io/gorm"
+	"yandex-team.ru/bstask/config"
+	"yandex-team.ru/bstask/internal/repository/repositories"
+	"yandex-team.ru/bstask/internal/usecase/courier"
+	"yandex-team.ru/bstask/internal/usecase/order"
+	"yandex-team.ru/bstask/internal/usecase/region"
+	"yandex-team.ru/bstask/internal/usecase/store"
+	"yandex-team.ru/bstask/pkg/db/postgresql"
+	"yandex-team.ru/bstask/pkg/tracing"
+)
+
+type App struct {
+	DB  *gorm.DB
+	Trm trm.Manager
+
+	CourierRepo       *repositories.CourierRepo
+	OrderRepo         *repositories.OrderRepo
+	DeliveryGroupRepo *repositories.DeliveryGroupRepo
+	RegionRepo        *repositories.RegionRepo
+	StoreRepo         *repositories.StoreRepo
+	CourierLiveRepo   *repositories.CourierLiveRepo
+
+	CourierUseCase *courier.CourierUseCase
+	OrderUseCase   *order.OrderUseCase
+	RegionUseCase  *region.RegionUseCase
+	StoreUseCase   *store.StoreUseCase
+}
+
+func OpenDB(dbConf *config.DatabaseConfig) *gorm.DB {
+	return postgresql.GetInstance(
+		dbConf.Pgsql.Host,
+		dbConf.Pgsql.Username,
+		dbConf.Pgsql.Password,
+		dbConf.Pgsql.Database,
+		dbConf.Pgsql.Port,
+		postgresql.PoolConfig{
+			MaxOpenConns:    dbConf.Pool.MaxOpenConns,
+			MaxIdleConns:    dbConf.Pool.MaxIdleConns,
+			ConnMaxLifetime: dbConf.Pool.ConnMaxLifetime,
+		},
+	)
+}
+
+func New(db *gorm.DB) (*App, error) {
+
+	trManager, err := manager.New(trmgorm.NewDefaultFactory(db))
+	if err != nil {
+		return nil, err
+	}
+	m := tracing.NewManager(trManager)
+
+	a := &App{
+		DB:                db,
+		Trm:               m,
+		CourierRepo:       repositories.NewCourierRepo(db, trmgorm.DefaultCtxGetter),
+		OrderRepo:         repositories.NewOrderRepo(db, trmgorm.DefaultCtxGetter),
+		DeliveryGroupRepo: repositories.NewOrderGroupRepo(db, trmgorm.DefaultCtxGetter),
+		RegionRepo:        repositories.NewRegionRepo(db, trmgorm.DefaultCtxGetter),
+		StoreRepo:         repositories.NewStoreRepo(db, trmgorm.DefaultCtxGetter),
+		CourierLiveRepo:   repositories.NewCourierLiveRepo(db, trmgorm.DefaultCtxGetter),
+	}
+
+	a.CourierUseCase = courier.New(m, a.CourierRepo, a.OrderRepo, a.DeliveryGroupRepo, a.RegionRepo, a.StoreRepo, a.CourierLiveRepo)
+	a.OrderUseCase = order.New(m, a.OrderRepo, a.CourierRepo, a.DeliveryGroupRepo, a.RegionRepo, a.StoreRepo)
+	a.RegionUseCase = region.New(m, a.RegionRepo, a.CourierRepo)
+	a.StoreUseCase = store.New(m, a.StoreRepo, a.RegionRepo)
+
+	return a, nil
+}
@@ -0,0 +1,44 @@
+package entity
+
+import "time"
+
+// OnDate places clock time of day on the date of day.
+func OnDate(day, clock time.Time) time.Time {
+	return time.Date(
+		day.Year(),
+		day.Month(),
+		day.Day(),
+		clock.Hour(),
+		clock.Minute(),
+		clock.Second(),
+		clock.Nanosecond(),
+		day.Location(),
+	)
+}
+
+// IntervalOnDate places clock interval starting on the date of day.
+// An interval ending not after its start crosses midnight and ends next day.
+// Equal ends would be a whole day here but an empty interval in SQL, so they
+// are rejected on input.
+func IntervalOnDate(day, start, end time.Time) (time.Time, time.Time) {
+	s := OnDate(day, start)
+	e := OnDate(day, end)
+	if !e.After(s) {
+		e = e.AddDate(0, 0, 1)
+	}
+
+	return s, e
+}
+
+// ClockWithin reports whether clock interval start-end lies inside
+// outerStart-outerEnd, both may cross midnight.
+func ClockWithin(outerStart, outerEnd, start, end time.Time) bool {
+	from, to := IntervalOnDate(outerStart, outerStart, outerEnd)
+	s, e := IntervalOnDate(outerStart, start, end)
+	if s.Before(from) {
+		// starts after midnight of the outer interval
+		s, e = s.AddDate(0, 0, 1), e.AddDate(0, 0, 1)
+	}
+
+	return !e.After(to)
+}
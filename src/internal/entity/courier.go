@@ -11,10 +11,21 @@ type Courier struct {
 	CourierType  CourierType
 	Regions      []int32
 	WorkingHours []string
+	// Rest periods inside working hours, no deliveries during them.
+	Breaks []string
+	// Shift limits, nil means no limit.
+	MaxDeliveriesPerShift *uint32
+	MaxContinuousDriving  *time.Duration
+	Capabilities          []Capability
+	// Store each working interval starts at, shifts without one start at
+	// the region depot.
+	ShiftStores map[string]uint64
 }
 
 type DeliveryPotential struct {
-	MaxWeight  float64
+	MaxWeight float64
+	// Litres
+	MaxVolume  float64
 	MaxOrders  uint
 	MaxRegions uint
 }
@@ -82,18 +93,21 @@ func DeliveryPotentialForType(t CourierType) (DeliveryPotential, error) {
 	case FOOT:
 		return DeliveryPotential{
 			MaxWeight:  10,
+			MaxVolume:  40,
 			MaxOrders:  2,
 			MaxRegions: 1,
 		}, nil
 	case BIKE:
 		return DeliveryPotential{
 			MaxWeight:  20,
+			MaxVolume:  80,
 			MaxOrders:  4,
 			MaxRegions: 2,
 		}, nil
 	case AUTO:
 		return DeliveryPotential{
 			MaxWeight:  40,
+			MaxVolume:  400,
 			MaxOrders:  7,
 			MaxRegions: 3,
 		}, nil
@@ -0,0 +1,40 @@
+package entity
+
+import (
+	"time"
+
+	"yandex-team.ru/bstask/pkg/geo"
+)
+
+// CourierStatus is what a courier is doing right now, reported by the courier app.
+type CourierStatus string
+
+const (
+	COURIER_STATUS_OFFLINE    CourierStatus = "OFFLINE"
+	COURIER_STATUS_IDLE       CourierStatus = "IDLE"
+	COURIER_STATUS_PICKING    CourierStatus = "PICKING"
+	COURIER_STATUS_DELIVERING CourierStatus = "DELIVERING"
+	COURIER_STATUS_BREAK      CourierStatus = "BREAK"
+)
+
+// Couriers who never reported a status are offline.
+const DEFAULT_COURIER_STATUS = COURIER_STATUS_OFFLINE
+
+func IsValidCourierStatus(s string) bool {
+	switch CourierStatus(s) {
+	case COURIER_STATUS_OFFLINE, COURIER_STATUS_IDLE, COURIER_STATUS_PICKING,
+		COURIER_STATUS_DELIVERING, COURIER_STATUS_BREAK:
+		return true
+	}
+	return false
+}
+
+// CourierLive is the latest reported state of a courier. Times are when
+// the state was observed by the courier app, nil if never reported.
+type CourierLive struct {
+	CourierID    uint64
+	Status       CourierStatus
+	StatusTime   *time.Time
+	Location     *geo.Point
+	LocationTime *time.Time
+}
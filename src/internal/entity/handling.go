@@ -0,0 +1,58 @@
+package entity
+
+// HandlingFlag is a special requirement of an order delivery.
+type HandlingFlag string
+
+const (
+	HANDLING_COLD_CHAIN HandlingFlag = "COLD_CHAIN"
+	HANDLING_FRAGILE    HandlingFlag = "FRAGILE"
+	HANDLING_ID_CHECK   HandlingFlag = "ID_CHECK"
+	HANDLING_OVERSIZED  HandlingFlag = "OVERSIZED"
+)
+
+// Capability is equipment or training of a courier.
+type Capability string
+
+const (
+	CAPABILITY_THERMAL_BAG      Capability = "THERMAL_BAG"
+	CAPABILITY_FRAGILE_HANDLING Capability = "FRAGILE_HANDLING"
+	CAPABILITY_AGE_VERIFICATION Capability = "AGE_VERIFICATION"
+	CAPABILITY_LARGE_CARGO      Capability = "LARGE_CARGO"
+)
+
+// requiredCapabilities lists what a courier needs to deliver an order with the flag.
+var requiredCapabilities = map[HandlingFlag]Capability{
+	HANDLING_COLD_CHAIN: CAPABILITY_THERMAL_BAG,
+	HANDLING_FRAGILE:    CAPABILITY_FRAGILE_HANDLING,
+	HANDLING_ID_CHECK:   CAPABILITY_AGE_VERIFICATION,
+	HANDLING_OVERSIZED:  CAPABILITY_LARGE_CARGO,
+}
+
+func IsValidHandlingFlag(f string) bool {
+	_, ok := requiredCapabilities[HandlingFlag(f)]
+	return ok
+}
+
+func IsValidCapability(c string) bool {
+	for _, required := range requiredCapabilities {
+		if string(required) == c {
+			return true
+		}
+	}
+	return false
+}
+
+// HandledFlags returns flags of orders a courier with capabilities may deliver.
+func HandledFlags(capabilities []Capability) []HandlingFlag {
+	res := []HandlingFlag{}
+	for flag, required := range requiredCapabilities {
+		for _, c := range capabilities {
+			if c == required {
+				res = append(res, flag)
+				break
+			}
+		}
+	}
+
+	return res
+}
@@ -1,6 +1,10 @@
 package entity
 
-import "time"
+import (
+	"time"
+
+	"yandex-team.ru/bstask/pkg/geo"
+)
 
 type Order struct {
 	ID              uint64
@@ -10,6 +14,61 @@ type Order struct {
 	Cost            uint32
 	CompletedTime   *time.Time
 	DeliveryGroupID *uint64
+	// Set once a courier confirms the delivery, until then CompletedTime
+	// of an assigned order is only planned.
+	DeliveredTime *time.Time
+	// Optional delivery address.
+	Location *geo.Point
+	// Position of the stop in its delivery group and planned arrival,
+	// set on assignment.
+	DeliverySequence *int32
+	ETA              *time.Time
+	// One of DeliveryHours the delivery is planned in.
+	DeliveryWindowID *uint64
+	Priority         OrderPriority
+	// Optional hard deadline of delivery.
+	Deadline *time.Time
+	// Optional size, litres. Derived from Dimensions when only they are known.
+	Volume     *float64
+	Dimensions *Dimensions
+	// Optional contents of the parcel.
+	Items    []OrderItem
+	Handling []HandlingFlag
+	// Optional store the order is picked up at.
+	StoreID *uint64
+}
+
+type OrderItem struct {
+	ID       uint64
+	SKU      string
+	Quantity uint32
+	// Kilograms
+	UnitWeight float64
+}
+
+// Dimensions of a parcel in centimetres.
+type Dimensions struct {
+	Length float64
+	Width  float64
+	Height float64
+}
+
+func (d Dimensions) Valid() bool {
+	return d.Length > 0 && d.Width > 0 && d.Height > 0
+}
+
+// Volume of the box in litres.
+func (d Dimensions) Volume() float64 {
+	return d.Length * d.Width * d.Height / 1000
+}
+
+// VolumeOrZero returns Volume, orders of unknown size take no space.
+func (o *Order) VolumeOrZero() float64 {
+	if o.Volume == nil {
+		return 0
+	}
+
+	return *o.Volume
 }
 
 type OrderDeliveryHours struct {
@@ -17,3 +76,69 @@ type OrderDeliveryHours struct {
 	StartTime time.Time
 	EndTime   time.Time
 }
+
+type OrderStatus string
+
+const (
+	ORDER_STATUS_UNASSIGNED OrderStatus = "unassigned"
+	ORDER_STATUS_ASSIGNED   OrderStatus = "assigned"
+	ORDER_STATUS_COMPLETED  OrderStatus = "completed"
+)
+
+func IsValidOrderStatus(s string) bool {
+	switch OrderStatus(s) {
+	case ORDER_STATUS_UNASSIGNED, ORDER_STATUS_ASSIGNED, ORDER_STATUS_COMPLETED:
+		return true
+	}
+	return false
+}
+
+// OrderPriority is the service level of an order, assignment takes
+// higher priorities first.
+type OrderPriority string
+
+const (
+	ORDER_PRIORITY_EXPRESS  OrderPriority = "EXPRESS"
+	ORDER_PRIORITY_STANDARD OrderPriority = "STANDARD"
+	ORDER_PRIORITY_ECONOMY  OrderPriority = "ECONOMY"
+)
+
+const DEFAULT_ORDER_PRIORITY = ORDER_PRIORITY_STANDARD
+
+func IsValidOrderPriority(p string) bool {
+	switch OrderPriority(p) {
+	case ORDER_PRIORITY_EXPRESS, ORDER_PRIORITY_STANDARD, ORDER_PRIORITY_ECONOMY:
+		return true
+	}
+	return false
+}
+
+// In shows times of the order in loc, e.g. local time of its region.
+func (o *Order) In(loc *time.Location) {
+	if o.CompletedTime != nil {
+		t := o.CompletedTime.In(loc)
+		o.CompletedTime = &t
+	}
+	if o.DeliveredTime != nil {
+		t := o.DeliveredTime.In(loc)
+		o.DeliveredTime = &t
+	}
+	if o.ETA != nil {
+		t := o.ETA.In(loc)
+		o.ETA = &t
+	}
+	if o.Deadline != nil {
+		t := o.Deadline.In(loc)
+		o.Deadline = &t
+	}
+}
+
+func (o *Order) Status() OrderStatus {
+	if o.DeliveredTime != nil {
+		return ORDER_STATUS_COMPLETED
+	}
+	if o.DeliveryGroupID != nil {
+		return ORDER_STATUS_ASSIGNED
+	}
+	return ORDER_STATUS_UNASSIGNED
+}
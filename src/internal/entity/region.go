@@ -0,0 +1,179 @@
+package entity
+
+import (
+	"time"
+	// zones are resolved in any runtime image
+	_ "time/tzdata"
+
+	"yandex-team.ru/bstask"
+	"yandex-team.ru/bstask/pkg/geo"
+)
+
+const DEFAULT_TIME_ZONE = "UTC"
+
+type Region struct {
+	ID         int32
+	Name       string
+	Active     bool
+	Neighbours []int32
+	// Where couriers pick up orders of the region, optional.
+	Depot *geo.Point
+	// IANA name; working and delivery hours in the region are local clock.
+	TimeZone string
+}
+
+// LoadTimeZone resolves IANA time zone name, "Local" is not accepted.
+func LoadTimeZone(name string) (*time.Location, error) {
+	const op = "entity.LoadTimeZone"
+
+	loc, err := time.LoadLocation(name)
+	if err != nil || name == "" || name == "Local" {
+		return nil, &bstask.Error{
+			Op:      op,
+			Code:    bstask.EINVALID,
+			Err:     err,
+			Message: "unknown time zone",
+			Fields:  map[string]interface{}{"time_zone": name},
+		}
+	}
+
+	return loc, nil
+}
+
+// RegionCatalog is a snapshot of managed regions. While the catalog is empty
+// regions are not managed and any region is accepted.
+type RegionCatalog struct {
+	regions   map[int32]Region
+	adjacency map[int32]map[int32]bool
+	locations map[int32]*time.Location
+}
+
+// NewRegionCatalog builds catalog of regions. Adjacency is symmetric:
+// a region listed by one side is a neighbour of both.
+func NewRegionCatalog(regions []Region) *RegionCatalog {
+	c := &RegionCatalog{
+		regions:   make(map[int32]Region, len(regions)),
+		adjacency: make(map[int32]map[int32]bool),
+		locations: make(map[int32]*time.Location, len(regions)),
+	}
+
+	for _, r := range regions {
+		c.regions[r.ID] = r
+		if loc, err := LoadTimeZone(r.TimeZone); err == nil {
+			c.locations[r.ID] = loc
+		}
+	}
+
+	for _, r := range regions {
+		for _, n := range r.Neighbours {
+			if _, ok := c.regions[n]; !ok || n == r.ID {
+				continue
+			}
+			c.link(r.ID, n)
+			c.link(n, r.ID)
+		}
+	}
+
+	return c
+}
+
+func (c *RegionCatalog) link(a, b int32) {
+	if c.adjacency[a] == nil {
+		c.adjacency[a] = make(map[int32]bool)
+	}
+	c.adjacency[a][b] = true
+}
+
+func (c *RegionCatalog) IsEmpty() bool {
+	return len(c.regions) == 0
+}
+
+func (c *RegionCatalog) Get(id int32) (Region, bool) {
+	r, ok := c.regions[id]
+	return r, ok
+}
+
+// CheckActive fails with EINVALID on the first region which is unknown or inactive.
+func (c *RegionCatalog) CheckActive(ids ...int32) error {
+	const op = "entity.RegionCatalog.CheckActive"
+
+	if c.IsEmpty() {
+		return nil
+	}
+
+	for _, id := range ids {
+		if r, ok := c.regions[id]; !ok || !r.Active {
+			return &bstask.Error{
+				Op:      op,
+				Code:    bstask.EINVALID,
+				Message: "unknown or inactive region",
+				Fields:  map[string]interface{}{"region": id},
+			}
+		}
+	}
+
+	return nil
+}
+
+// Adjacent reports whether both regions are active and neighbours.
+func (c *RegionCatalog) Adjacent(a, b int32) bool {
+	if !c.adjacency[a][b] {
+		return false
+	}
+
+	return c.regions[a].Active && c.regions[b].Active
+}
+
+// Depot returns location of depot of the region, nil if unknown.
+func (c *RegionCatalog) Depot(id int32) *geo.Point {
+	return c.regions[id].Depot
+}
+
+// Location returns time zone of the region, UTC if unknown.
+func (c *RegionCatalog) Location(id int32) *time.Location {
+	if loc, ok := c.locations[id]; ok {
+		return loc
+	}
+
+	return time.UTC
+}
+
+// LocationOf returns time zone of a set of regions, e.g. of a courier,
+// which are kept in one zone by CheckSameTimeZone.
+func (c *RegionCatalog) LocationOf(ids []int32) *time.Location {
+	if len(ids) == 0 {
+		return time.UTC
+	}
+
+	return c.Location(ids[0])
+}
+
+// CheckSameTimeZone fails with EINVALID if regions are in different time
+// zones, e.g. for courier working hours to have one meaning.
+func (c *RegionCatalog) CheckSameTimeZone(ids ...int32) error {
+	const op = "entity.RegionCatalog.CheckSameTimeZone"
+
+	if len(ids) == 0 {
+		return nil
+	}
+
+	for _, id := range ids {
+		if c.Location(id).String() != c.Location(ids[0]).String() {
+			return &bstask.Error{
+				Op:      op,
+				Code:    bstask.EINVALID,
+				Message: "regions are in different time zones",
+				Fields:  map[string]interface{}{"regions": ids},
+			}
+		}
+	}
+
+	return nil
+}
+
+// LocalizeOrders shows times of orders in local time of their regions.
+func (c *RegionCatalog) LocalizeOrders(orders []Order) {
+	for i := range orders {
+		orders[i].In(c.Location(orders[i].Regions))
+	}
+}
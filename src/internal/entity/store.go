@@ -0,0 +1,72 @@
+package entity
+
+import (
+	"time"
+
+	"yandex-team.ru/bstask"
+	"yandex-team.ru/bstask/pkg/geo"
+)
+
+const DEFAULT_PICKUP_MINUTES = 5
+
+// Store is a dark store couriers pick up orders at.
+type Store struct {
+	ID       uint64
+	Name     string
+	Region   int32
+	Location geo.Point
+	// Time to collect orders of a delivery group.
+	PickupTime time.Duration
+}
+
+// StoreCatalog is a snapshot of stores.
+type StoreCatalog struct {
+	stores map[uint64]Store
+}
+
+func NewStoreCatalog(stores []Store) *StoreCatalog {
+	c := &StoreCatalog{stores: make(map[uint64]Store, len(stores))}
+	for _, s := range stores {
+		c.stores[s.ID] = s
+	}
+
+	return c
+}
+
+// Get returns store by optional id, ok is false for nil or unknown id.
+func (c *StoreCatalog) Get(id *uint64) (Store, bool) {
+	if id == nil {
+		return Store{}, false
+	}
+
+	s, ok := c.stores[*id]
+	return s, ok
+}
+
+// CheckExists fails with EINVALID if the store is set but unknown.
+func (c *StoreCatalog) CheckExists(id *uint64) error {
+	const op = "entity.StoreCatalog.CheckExists"
+
+	if id == nil {
+		return nil
+	}
+	if _, ok := c.stores[*id]; !ok {
+		return &bstask.Error{
+			Op:      op,
+			Code:    bstask.EINVALID,
+			Message: "unknown store",
+			Fields:  map[string]interface{}{"store_id": *id},
+		}
+	}
+
+	return nil
+}
+
+// SameStore reports whether optional store ids are equal, no store equals no store.
+func SameStore(a, b *uint64) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+
+	return *a == *b
+}
@@ -0,0 +1,85 @@
+package entity
+
+import (
+	"time"
+
+	"yandex-team.ru/bstask"
+	"yandex-team.ru/bstask/pkg/geo"
+)
+
+// TravelTimeModel estimates how long a courier takes to deliver the next order.
+// from is the previous stop or region depot, nil if unknown; to is nil when
+// the order has no coordinates. ordersInRegion is the number of orders the
+// courier has already delivered in the region of the order within the batch.
+type TravelTimeModel interface {
+	TravelTime(t CourierType, from, to *geo.Point, ordersInRegion uint) (time.Duration, error)
+}
+
+// FixedTravelTime ignores coordinates and uses fixed minutes per order.
+type FixedTravelTime struct{}
+
+func (FixedTravelTime) TravelTime(t CourierType, from, to *geo.Point, ordersInRegion uint) (time.Duration, error) {
+	return NextDeliveryTimeInRegion(t, ordersInRegion)
+}
+
+// StraightLineTravelTime moves courier along the great circle with the
+// speed of its type and adds service time at the stop. Falls back to
+// FixedTravelTime if any point is unknown.
+type StraightLineTravelTime struct{}
+
+func (StraightLineTravelTime) TravelTime(t CourierType, from, to *geo.Point, ordersInRegion uint) (time.Duration, error) {
+	if from == nil || to == nil {
+		return NextDeliveryTimeInRegion(t, ordersInRegion)
+	}
+
+	speed, err := TravelSpeedForType(t)
+	if err != nil {
+		return 0, err
+	}
+
+	service, err := ServiceTimeForType(t, ordersInRegion)
+	if err != nil {
+		return 0, err
+	}
+
+	hours := geo.Distance(*from, *to) / speed
+
+	return time.Duration(hours*float64(time.Hour)).Round(time.Second) + service, nil
+}
+
+// ServiceTimeForType returns time to park and hand over an order at a stop.
+// The first stop in a region takes longer, next ones are in a known area.
+func ServiceTimeForType(t CourierType, ordersInRegion uint) (time.Duration, error) {
+	const op = "entity.ServiceTimeForType"
+
+	switch t {
+	case FOOT, BIKE:
+		if ordersInRegion > 0 {
+			return 2 * time.Minute, nil
+		}
+		return 4 * time.Minute, nil
+	case AUTO:
+		if ordersInRegion > 0 {
+			return 3 * time.Minute, nil
+		}
+		return 6 * time.Minute, nil
+	default:
+		return 0, &bstask.Error{Op: op, Code: bstask.EINVALID, Message: "invalid courier type"}
+	}
+}
+
+// TravelSpeedForType returns average speed of courier in km/h.
+func TravelSpeedForType(t CourierType) (float64, error) {
+	const op = "entity.TravelSpeedForType"
+
+	switch t {
+	case FOOT:
+		return 5, nil
+	case BIKE:
+		return 15, nil
+	case AUTO:
+		return 30, nil
+	default:
+		return 0, &bstask.Error{Op: op, Code: bstask.EINVALID, Message: "invalid courier type"}
+	}
+}
@@ -0,0 +1,88 @@
+package health
+
+import (
+	"context"
+	"sync"
+)
+
+const (
+	STATUS_OK   = "ok"
+	STATUS_FAIL = "fail"
+)
+
+type CheckResult struct {
+	Status  string                 `json:"status"`
+	Error   string                 `json:"error,omitempty"`
+	Details map[string]interface{} `json:"details,omitempty"`
+}
+
+type Report struct {
+	Status string                 `json:"status"`
+	Checks map[string]CheckResult `json:"checks"`
+}
+
+func (r Report) Ok() bool {
+	return r.Status == STATUS_OK
+}
+
+// Check inspects one dependency of the service.
+type Check func(ctx context.Context) CheckResult
+
+type namedCheck struct {
+	name  string
+	check Check
+}
+
+// Checker runs readiness checks of the service dependencies.
+type Checker struct {
+	checks []namedCheck
+}
+
+func NewChecker() *Checker {
+	return &Checker{}
+}
+
+func (c *Checker) Add(name string, check Check) *Checker {
+	c.checks = append(c.checks, namedCheck{name: name, check: check})
+	return c
+}
+
+// Run runs all checks concurrently, report is failed if any of checks is failed.
+func (c *Checker) Run(ctx context.Context) Report {
+	report := Report{
+		Status: STATUS_OK,
+		Checks: make(map[string]CheckResult, len(c.checks)),
+	}
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+
+	for _, nc := range c.checks {
+		wg.Add(1)
+		go func(nc namedCheck) {
+			defer wg.Done()
+
+			res := nc.check(ctx)
+
+			mu.Lock()
+			defer mu.Unlock()
+
+			report.Checks[nc.name] = res
+			if res.Status != STATUS_OK {
+				report.Status = STATUS_FAIL
+			}
+		}(nc)
+	}
+
+	wg.Wait()
+
+	return report
+}
+
+func ok(details map[string]interface{}) CheckResult {
+	return CheckResult{Status: STATUS_OK, Details: details}
+}
+
+func fail(err error, details map[string]interface{}) CheckResult {
+	return CheckResult{Status: STATUS_FAIL, Error: err.Error(), Details: details}
+}
@@ -0,0 +1,84 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"gorm.io/gorm"
+	"yandex-team.ru/bstask/pkg/db/postgresql"
+)
+
+// PostgresPing checks that database accepts connections.
+func PostgresPing(db *gorm.DB) Check {
+	return func(ctx context.Context) CheckResult {
+		if err := postgresql.Ping(ctx, db); err != nil {
+			return fail(err, nil)
+		}
+
+		return ok(nil)
+	}
+}
+
+// Migrations checks that schema is migrated up to `latest` version
+// and the last migration is not dirty.
+func Migrations(db *gorm.DB, table string, latest uint) Check {
+	return func(ctx context.Context) CheckResult {
+		var row struct {
+			Version int64
+			Dirty   bool
+		}
+
+		err := db.WithContext(ctx).Table(table).Select("version", "dirty").Limit(1).Scan(&row).Error
+		if err != nil {
+			return fail(err, nil)
+		}
+
+		details := map[string]interface{}{
+			"version": row.Version,
+			"latest":  latest,
+			"dirty":   row.Dirty,
+		}
+
+		if row.Dirty {
+			return fail(errors.New("last migration failed, schema is dirty"), details)
+		}
+
+		if row.Version < int64(latest) {
+			return fail(errors.New("schema has pending migrations"), details)
+		}
+
+		return ok(details)
+	}
+}
+
+// PoolSaturation fails when share of busy connections reaches threshold.
+func PoolSaturation(db *gorm.DB, threshold float64) Check {
+	return func(ctx context.Context) CheckResult {
+		sqlDB, err := db.DB()
+		if err != nil {
+			return fail(err, nil)
+		}
+
+		stats := sqlDB.Stats()
+		details := map[string]interface{}{
+			"in_use":     stats.InUse,
+			"idle":       stats.Idle,
+			"max_open":   stats.MaxOpenConnections,
+			"wait_count": stats.WaitCount,
+		}
+
+		if stats.MaxOpenConnections <= 0 {
+			return ok(details)
+		}
+
+		saturation := float64(stats.InUse) / float64(stats.MaxOpenConnections)
+		details["saturation"] = saturation
+
+		if saturation >= threshold {
+			return fail(fmt.Errorf("pool saturation %.2f reached threshold %.2f", saturation, threshold), details)
+		}
+
+		return ok(details)
+	}
+}
@@ -1,14 +1,17 @@
 package controller
 
 import (
-	"context"
 	"math"
 	"net/http"
 	"strconv"
 	"time"
 
 	"github.com/labstack/echo/v4"
+	"yandex-team.ru/bstask/internal/entity"
+	"yandex-team.ru/bstask/internal/importer"
+	"yandex-team.ru/bstask/internal/repository/repositories"
 	"yandex-team.ru/bstask/internal/usecase/courier"
+	"yandex-team.ru/bstask/pkg/geo"
 )
 
 type CourierController struct {
@@ -16,10 +19,37 @@ type CourierController struct {
 }
 
 type CourierDto struct {
-	CourierId    uint64   `json:"courier_id"`
-	CourierType  string   `json:"courier_type"`
-	Regions      []int32  `json:"regions"`
-	WorkingHours []string `json:"working_hours"`
+	CourierId                   uint64   `json:"courier_id"`
+	CourierType                 string   `json:"courier_type"`
+	Regions                     []int32  `json:"regions"`
+	WorkingHours                []string `json:"working_hours"`
+	Breaks                      []string `json:"breaks,omitempty"`
+	MaxDeliveriesPerShift       *uint32  `json:"max_deliveries_per_shift,omitempty"`
+	MaxContinuousDrivingMinutes *uint32  `json:"max_continuous_driving_minutes,omitempty"`
+	Capabilities                []string `json:"capabilities,omitempty"`
+	// Store each working interval starts at.
+	ShiftStores map[string]uint64 `json:"shift_stores,omitempty"`
+}
+
+func toCourierDto(courier entity.Courier) CourierDto {
+	dto := CourierDto{
+		CourierId:             courier.ID,
+		CourierType:           string(courier.CourierType),
+		Regions:               courier.Regions,
+		WorkingHours:          courier.WorkingHours,
+		Breaks:                courier.Breaks,
+		MaxDeliveriesPerShift: courier.MaxDeliveriesPerShift,
+		ShiftStores:           courier.ShiftStores,
+	}
+	if courier.MaxContinuousDriving != nil {
+		minutes := uint32(courier.MaxContinuousDriving.Minutes())
+		dto.MaxContinuousDrivingMinutes = &minutes
+	}
+	for _, c := range courier.Capabilities {
+		dto.Capabilities = append(dto.Capabilities, string(c))
+	}
+
+	return dto
 }
 
 func NewCourierController(uc *courier.CourierUseCase) CourierController {
@@ -73,7 +103,7 @@ func (c *CourierController) Assignments(ctx echo.Context) error {
 		courierIDs = append(courierIDs, uint64(id))
 	}
 
-	co := context.Background()
+	co := ctx.Request().Context()
 	assignments, err := c.uc.Assignments(co, courierIDs, date)
 	if err != nil {
 		return err
@@ -94,20 +124,7 @@ func (c *CourierController) Assignments(ctx echo.Context) error {
 				GroupOrderId: orderGroup.GroupOrderId,
 			}
 			for _, order := range orderGroup.Orders {
-
-				dh := []string{}
-				for _, t := range order.DeliveryHours {
-					dh = append(dh, t.StartTime.Format("15:04")+"-"+t.EndTime.Format("15:04"))
-				}
-
-				assignOrdersGroup.Orders = append(assignOrdersGroup.Orders, OrderDto{
-					ID:            order.ID,
-					Weight:        order.Weight,
-					Regions:       order.Regions,
-					DeliveryHours: dh,
-					Cost:          order.Cost,
-					CompletedTime: order.CompletedTime,
-				})
+				assignOrdersGroup.Orders = append(assignOrdersGroup.Orders, toOrderDto(order))
 			}
 
 			assignResponseGroupItem.Orders = append(assignResponseGroupItem.Orders, assignOrdersGroup)
@@ -129,6 +146,9 @@ type CourierGetAllReponse struct {
 	Couriers []CourierDto `json:"couriers"`
 	Offset   int32        `json:"offset"`
 	Limit    int32        `json:"limit"`
+	// Keyset pagination, when `cursor` param is passed.
+	NextCursor *string `json:"next_cursor,omitempty"`
+	Total      *int64  `json:"total,omitempty"`
 }
 
 func (c *CourierController) GetAll(ctx echo.Context) error {
@@ -153,7 +173,7 @@ func (c *CourierController) GetAll(ctx echo.Context) error {
 		}
 	}
 
-	couriers, err := c.uc.PaginatedGetAll(context.Background(), int32(offset), int32(limit))
+	filter, err := courierFilterParams(ctx)
 	if err != nil {
 		return err
 	}
@@ -161,13 +181,32 @@ func (c *CourierController) GetAll(ctx echo.Context) error {
 	res := CourierGetAllReponse{
 		Couriers: []CourierDto{},
 	}
-	for _, courier := range *couriers {
-		res.Couriers = append(res.Couriers, CourierDto{
-			CourierId:    courier.ID,
-			CourierType:  string(courier.CourierType),
-			Regions:      courier.Regions,
-			WorkingHours: courier.WorkingHours,
-		})
+
+	var couriers []entity.Courier
+	if ctx.QueryParams().Has("cursor") {
+		if offsetParam != "" {
+			return echo.NewHTTPError(400, "'offset' and 'cursor' params are mutually exclusive")
+		}
+
+		page, err := c.uc.KeysetGetAll(ctx.Request().Context(), filter, ctx.QueryParam("cursor"), int32(limit), ctx.QueryParam("total") == "true")
+		if err != nil {
+			return err
+		}
+
+		couriers = page.Couriers
+		res.NextCursor = page.NextCursor
+		res.Total = page.Total
+	} else {
+		page, err := c.uc.PaginatedGetAll(ctx.Request().Context(), filter, int32(offset), int32(limit))
+		if err != nil {
+			return err
+		}
+
+		couriers = *page
+	}
+
+	for _, courier := range couriers {
+		res.Couriers = append(res.Couriers, toCourierDto(courier))
 	}
 	res.Offset = int32(offset)
 	res.Limit = int32(limit)
@@ -175,6 +214,33 @@ func (c *CourierController) GetAll(ctx echo.Context) error {
 	return ctx.JSON(200, res)
 }
 
+// courierFilterParams reads `courier_type`, `region`, `available_at=HH:MM`
+// and `available_between=HH:MM-HH:MM` of courier listing.
+func courierFilterParams(ctx echo.Context) (repositories.CourierFilter, error) {
+
+	var f repositories.CourierFilter
+
+	if t := ctx.QueryParam("courier_type"); t != "" {
+		f.CourierType = &t
+	}
+
+	if region, err := queryInt32(ctx, "region"); err != nil {
+		return f, err
+	} else if region != 0 {
+		f.Region = &region
+	}
+
+	var err error
+	if f.AvailableAt, err = queryClock(ctx, "available_at"); err != nil {
+		return f, err
+	}
+	if f.AvailableBetween, err = queryTimeWindow(ctx, "available_between"); err != nil {
+		return f, err
+	}
+
+	return f, nil
+}
+
 // ====================================
 // ========== POST /couriers ==========
 // ====================================
@@ -183,9 +249,16 @@ type CourierCreateRequest struct {
 }
 
 type CourierRequestCreateDto struct {
-	CourierType  string   `json:"courier_type" validate:"required"`
-	Regions      []int32  `json:"regions" validate:"required,min=1,max=1000"`
-	WorkingHours []string `json:"working_hours" validate:"required,min=1,max=1000"`
+	CourierType                 string   `json:"courier_type" validate:"required"`
+	Regions                     []int32  `json:"regions" validate:"required,min=1,max=1000"`
+	WorkingHours                []string `json:"working_hours" validate:"required,min=1,max=1000"`
+	Breaks                      []string `json:"breaks" validate:"max=1000"`
+	MaxDeliveriesPerShift       *uint32  `json:"max_deliveries_per_shift"`
+	MaxContinuousDrivingMinutes *uint32  `json:"max_continuous_driving_minutes"`
+	// THERMAL_BAG, FRAGILE_HANDLING, AGE_VERIFICATION or LARGE_CARGO
+	Capabilities []string `json:"capabilities" validate:"max=4"`
+	// Store of single working intervals, the rest start at the region depot.
+	ShiftStores map[string]uint64 `json:"shift_stores"`
 }
 
 type CourierCreateResponse struct {
@@ -207,13 +280,18 @@ func (c *CourierController) Create(ctx echo.Context) error {
 	for _, newCourier := range req.Couriers {
 
 		newCouriers = append(newCouriers, courier.CourierToCreateDTO{
-			CourierType:  newCourier.CourierType,
-			Regions:      newCourier.Regions,
-			WorkingHours: newCourier.WorkingHours,
+			CourierType:                 newCourier.CourierType,
+			Regions:                     newCourier.Regions,
+			WorkingHours:                newCourier.WorkingHours,
+			Breaks:                      newCourier.Breaks,
+			MaxDeliveriesPerShift:       newCourier.MaxDeliveriesPerShift,
+			MaxContinuousDrivingMinutes: newCourier.MaxContinuousDrivingMinutes,
+			Capabilities:                newCourier.Capabilities,
+			ShiftStores:                 newCourier.ShiftStores,
 		})
 	}
 
-	savedCouriers, err := c.uc.CreateCouriers(context.Background(), newCouriers)
+	savedCouriers, err := c.uc.CreateCouriers(ctx.Request().Context(), newCouriers)
 	if err != nil {
 		return err
 	}
@@ -221,12 +299,7 @@ func (c *CourierController) Create(ctx echo.Context) error {
 	res := CourierCreateResponse{}
 
 	for _, newCourier := range *savedCouriers {
-		res.Couriers = append(res.Couriers, CourierDto{
-			CourierId:    newCourier.ID,
-			CourierType:  string(newCourier.CourierType),
-			Regions:      newCourier.Regions,
-			WorkingHours: newCourier.WorkingHours,
-		})
+		res.Couriers = append(res.Couriers, toCourierDto(newCourier))
 	}
 
 	return ctx.JSON(200, res)
@@ -234,6 +307,32 @@ func (c *CourierController) Create(ctx echo.Context) error {
 
 // ====================================
 
+// ===========================================
+// ========== POST /couriers/import ==========
+// ===========================================
+
+func (c *CourierController) Import(ctx echo.Context) error {
+
+	format, mode, err := importParams(ctx)
+	if err != nil {
+		return err
+	}
+
+	rows, err := importer.NewCourierReader(format, ctx.Request().Body)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	res, err := c.uc.Import(ctx.Request().Context(), rows, mode)
+	if err != nil {
+		return err
+	}
+
+	return importResult(ctx, res)
+}
+
+// ===========================================
+
 // ================================================
 // ========== GET /couriers/{courier_id} ==========
 // ================================================
@@ -247,17 +346,12 @@ func (c *CourierController) GetById(ctx echo.Context) error {
 		return echo.NewHTTPError(http.StatusBadRequest, ":courier_id must be valid int64")
 	}
 
-	courier, err := c.uc.GetById(context.Background(), uint64(courierId))
+	courier, err := c.uc.GetById(ctx.Request().Context(), uint64(courierId))
 	if err != nil {
 		return err
 	}
 
-	return ctx.JSON(200, CourierDto{
-		CourierId:    courier.ID,
-		CourierType:  string(courier.CourierType),
-		Regions:      courier.Regions,
-		WorkingHours: courier.WorkingHours,
-	})
+	return ctx.JSON(200, toCourierDto(*courier))
 }
 
 // ================================================
@@ -289,7 +383,7 @@ func (c *CourierController) MetaByCourierId(ctx echo.Context) error {
 		return echo.NewHTTPError(http.StatusBadRequest, "invalid :endDate param")
 	}
 
-	co := context.Background()
+	co := ctx.Request().Context()
 
 	courier, err := c.uc.GetById(co, uint64(courierId))
 	if err != nil {
@@ -302,12 +396,7 @@ func (c *CourierController) MetaByCourierId(ctx echo.Context) error {
 	}
 
 	res := CourierMetaByIDResponse{
-		CourierDto: CourierDto{
-			CourierId:    courier.ID,
-			CourierType:  string(courier.CourierType),
-			Regions:      courier.Regions,
-			WorkingHours: courier.WorkingHours,
-		},
+		CourierDto: toCourierDto(*courier),
 	}
 
 	if meta.Rating != nil {
@@ -320,3 +409,151 @@ func (c *CourierController) MetaByCourierId(ctx echo.Context) error {
 
 	return ctx.JSON(http.StatusOK, res)
 }
+
+// ===========================================================
+// ========== POST /couriers/{courier_id}/status =============
+// ===========================================================
+
+type CourierStatusRequest struct {
+	// OFFLINE, IDLE, PICKING, DELIVERING or BREAK
+	Status string `json:"status" validate:"required"`
+	// When the status was observed, now if not set.
+	Timestamp *time.Time `json:"timestamp"`
+}
+
+type CourierLiveDto struct {
+	CourierId    uint64     `json:"courier_id"`
+	Status       string     `json:"status"`
+	StatusTime   *time.Time `json:"status_time,omitempty"`
+	Location     *PointDto  `json:"location,omitempty"`
+	LocationTime *time.Time `json:"location_time,omitempty"`
+}
+
+func toCourierLiveDto(live entity.CourierLive) CourierLiveDto {
+	dto := CourierLiveDto{
+		CourierId:    live.CourierID,
+		Status:       string(live.Status),
+		StatusTime:   live.StatusTime,
+		LocationTime: live.LocationTime,
+	}
+	if live.Location != nil {
+		dto.Location = &PointDto{Lat: live.Location.Lat, Lon: live.Location.Lon}
+	}
+
+	return dto
+}
+
+func (c *CourierController) ReportStatus(ctx echo.Context) error {
+
+	courierId, err := courierIdParam(ctx)
+	if err != nil {
+		return err
+	}
+
+	var req CourierStatusRequest
+	if err := ctx.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err)
+	}
+
+	if err := ctx.Validate(req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err)
+	}
+
+	dto := courier.CourierStatusDTO{Status: req.Status}
+	if req.Timestamp != nil {
+		dto.ReportedAt = *req.Timestamp
+	}
+
+	co := ctx.Request().Context()
+
+	if err := c.uc.ReportStatus(co, courierId, dto); err != nil {
+		return err
+	}
+
+	live, err := c.uc.Live(co, courierId)
+	if err != nil {
+		return err
+	}
+
+	return ctx.JSON(http.StatusOK, toCourierLiveDto(*live))
+}
+
+// ===========================================================
+
+// ===========================================================
+// ========== POST /couriers/{courier_id}/location ===========
+// ===========================================================
+
+type CourierLocationRequest struct {
+	Lat *float64 `json:"lat" validate:"required"`
+	Lon *float64 `json:"lon" validate:"required"`
+	// When the position was observed, now if not set.
+	Timestamp *time.Time `json:"timestamp"`
+}
+
+func (c *CourierController) ReportLocation(ctx echo.Context) error {
+
+	courierId, err := courierIdParam(ctx)
+	if err != nil {
+		return err
+	}
+
+	var req CourierLocationRequest
+	if err := ctx.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err)
+	}
+
+	if err := ctx.Validate(req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err)
+	}
+
+	dto := courier.CourierLocationDTO{Location: geo.Point{Lat: *req.Lat, Lon: *req.Lon}}
+	if req.Timestamp != nil {
+		dto.ReportedAt = *req.Timestamp
+	}
+
+	co := ctx.Request().Context()
+
+	if err := c.uc.ReportLocation(co, courierId, dto); err != nil {
+		return err
+	}
+
+	live, err := c.uc.Live(co, courierId)
+	if err != nil {
+		return err
+	}
+
+	return ctx.JSON(http.StatusOK, toCourierLiveDto(*live))
+}
+
+// ===========================================================
+
+// ===========================================================
+// ========== GET /couriers/{courier_id}/live ================
+// ===========================================================
+
+func (c *CourierController) Live(ctx echo.Context) error {
+
+	courierId, err := courierIdParam(ctx)
+	if err != nil {
+		return err
+	}
+
+	live, err := c.uc.Live(ctx.Request().Context(), courierId)
+	if err != nil {
+		return err
+	}
+
+	return ctx.JSON(http.StatusOK, toCourierLiveDto(*live))
+}
+
+// ===========================================================
+
+func courierIdParam(ctx echo.Context) (uint64, error) {
+	id, err := strconv.ParseInt(ctx.Param("courier_id"), 10, 64)
+	if err != nil || id <= 0 || id > math.MaxInt64 {
+		return 0, echo.NewHTTPError(http.StatusBadRequest, ":courier_id must be valid int64")
+	}
+
+	return uint64(id), nil
+}
@@ -0,0 +1,373 @@
+package controller
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
+	"yandex-team.ru/bstask/internal/entity"
+	"yandex-team.ru/bstask/internal/repository/repositories"
+	"yandex-team.ru/bstask/internal/usecase/courier"
+	"yandex-team.ru/bstask/internal/usecase/order"
+	"yandex-team.ru/bstask/pkg/logger"
+)
+
+// Response is flushed to the client every EXPORT_FLUSH_ROWS rows.
+const EXPORT_FLUSH_ROWS = 1000
+
+type ExportController struct {
+	orders   *order.OrderUseCase
+	couriers *courier.CourierUseCase
+}
+
+func NewExportController(orders *order.OrderUseCase, couriers *courier.CourierUseCase) ExportController {
+	return ExportController{
+		orders:   orders,
+		couriers: couriers,
+	}
+}
+
+// exportWriter streams rows as CSV or NDJSON. Status and headers are sent
+// with the first row, so errors raised before it still get a proper response.
+type exportWriter struct {
+	ctx    echo.Context
+	name   string
+	format string
+	header []string
+	csv    *csv.Writer
+	json   *json.Encoder
+	rows   int
+}
+
+func newExportWriter(ctx echo.Context, name string, header []string) (*exportWriter, error) {
+	format := ctx.QueryParam("format")
+	if format == "" {
+		format = "csv"
+	}
+	if format != "csv" && format != "ndjson" {
+		return nil, echo.NewHTTPError(http.StatusBadRequest, ":format must be csv or ndjson")
+	}
+
+	return &exportWriter{
+		ctx:    ctx,
+		name:   name,
+		format: format,
+		header: header,
+	}, nil
+}
+
+func (w *exportWriter) start() error {
+	res := w.ctx.Response()
+
+	contentType := "application/x-ndjson"
+	if w.format == "csv" {
+		contentType = "text/csv; charset=utf-8"
+	}
+	res.Header().Set(echo.HeaderContentType, contentType)
+	res.Header().Set(echo.HeaderContentDisposition, `attachment; filename="`+w.name+`.`+w.format+`"`)
+	res.WriteHeader(http.StatusOK)
+
+	if w.format == "csv" {
+		w.csv = csv.NewWriter(res)
+		return w.csv.Write(w.header)
+	}
+
+	w.json = json.NewEncoder(res)
+
+	return nil
+}
+
+// Write sends record in CSV or v in NDJSON.
+func (w *exportWriter) Write(record []string, v interface{}) error {
+	if !w.ctx.Response().Committed {
+		if err := w.start(); err != nil {
+			return err
+		}
+	}
+
+	var err error
+	if w.csv != nil {
+		err = w.csv.Write(record)
+	} else {
+		err = w.json.Encode(v)
+	}
+	if err != nil {
+		return err
+	}
+
+	w.rows++
+	if w.rows%EXPORT_FLUSH_ROWS == 0 {
+		return w.flush()
+	}
+
+	return nil
+}
+
+func (w *exportWriter) Close() error {
+	if !w.ctx.Response().Committed {
+		if err := w.start(); err != nil {
+			return err
+		}
+	}
+
+	return w.flush()
+}
+
+// Finish closes the stream. Once rows are sent the status can't be changed,
+// so a failure is only logged and the client gets a truncated body.
+func (w *exportWriter) Finish(err error) error {
+	if err == nil {
+		return w.Close()
+	}
+
+	if w.ctx.Response().Committed {
+		logger.FromContext(w.ctx.Request().Context()).Error(
+			"export aborted",
+			append(logger.ErrorFields(err), zap.Int("rows", w.rows))...,
+		)
+		return nil
+	}
+
+	return err
+}
+
+func (w *exportWriter) flush() error {
+	if w.csv != nil {
+		w.csv.Flush()
+		if err := w.csv.Error(); err != nil {
+			return err
+		}
+	}
+	w.ctx.Response().Flush()
+
+	return nil
+}
+
+func formatTime(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
+
+func formatUint(i *uint64) string {
+	if i == nil {
+		return ""
+	}
+	return strconv.FormatUint(*i, 10)
+}
+
+// ========================================
+// ========== GET /export/orders ==========
+// ========================================
+
+type OrderExportItem struct {
+	OrderId         uint64     `json:"order_id"`
+	Weight          float64    `json:"weight"`
+	Regions         int32      `json:"regions"`
+	DeliveryHours   []string   `json:"delivery_hours"`
+	Cost            uint32     `json:"cost"`
+	Status          string     `json:"status"`
+	CourierId       *uint64    `json:"courier_id"`
+	DeliveryGroupId *uint64    `json:"group_order_id"`
+	CompletedTime   *time.Time `json:"completed_time"`
+}
+
+func (c *ExportController) Orders(ctx echo.Context) error {
+
+	var filter repositories.OrderExportFilter
+	var err error
+
+	if filter.From, err = queryDate(ctx, "from"); err != nil {
+		return err
+	}
+	if filter.To, err = queryDate(ctx, "to"); err != nil {
+		return err
+	}
+	if filter.Region, err = queryInt32(ctx, "region"); err != nil {
+		return err
+	}
+	if filter.CourierID, err = queryUint64(ctx, "courier_id"); err != nil {
+		return err
+	}
+	filter.Status = entity.OrderStatus(ctx.QueryParam("status"))
+
+	w, err := newExportWriter(ctx, "orders", []string{
+		"order_id", "weight", "regions", "delivery_hours", "cost", "status", "courier_id", "group_order_id", "completed_time",
+	})
+	if err != nil {
+		return err
+	}
+
+	err = c.orders.Export(ctx.Request().Context(), filter, func(o repositories.OrderExportDTO) error {
+		item := OrderExportItem{
+			OrderId:         o.ID,
+			Weight:          o.Weight,
+			Regions:         o.Regions,
+			DeliveryHours:   splitHours(o.DeliveryHours),
+			Cost:            o.Cost,
+			Status:          string(o.Status()),
+			CourierId:       o.CourierID,
+			DeliveryGroupId: o.DeliveryGroupID,
+			CompletedTime:   o.CompletedTime,
+		}
+
+		return w.Write([]string{
+			strconv.FormatUint(o.ID, 10),
+			strconv.FormatFloat(o.Weight, 'f', -1, 64),
+			strconv.Itoa(int(o.Regions)),
+			o.DeliveryHours,
+			strconv.Itoa(int(o.Cost)),
+			item.Status,
+			formatUint(o.CourierID),
+			formatUint(o.DeliveryGroupID),
+			formatTime(o.CompletedTime),
+		}, item)
+	})
+
+	return w.Finish(err)
+}
+
+// ========================================
+
+// ==========================================
+// ========== GET /export/couriers ==========
+// ==========================================
+
+func (c *ExportController) Couriers(ctx echo.Context) error {
+
+	var filter repositories.CourierExportFilter
+	var err error
+
+	if filter.Region, err = queryInt32(ctx, "region"); err != nil {
+		return err
+	}
+	if filter.CourierID, err = queryUint64(ctx, "courier_id"); err != nil {
+		return err
+	}
+	filter.CourierType = ctx.QueryParam("courier_type")
+
+	w, err := newExportWriter(ctx, "couriers", []string{"courier_id", "courier_type", "regions", "working_hours"})
+	if err != nil {
+		return err
+	}
+
+	err = c.couriers.Export(ctx.Request().Context(), filter, func(cr repositories.CourierExportDTO) error {
+		regions := make([]string, 0, len(cr.Regions))
+		for _, r := range cr.Regions {
+			regions = append(regions, strconv.Itoa(int(r)))
+		}
+
+		return w.Write([]string{
+			strconv.FormatUint(cr.ID, 10),
+			cr.CourierType,
+			strings.Join(regions, ";"),
+			cr.WorkingHours,
+		}, CourierDto{
+			CourierId:    cr.ID,
+			CourierType:  cr.CourierType,
+			Regions:      cr.Regions,
+			WorkingHours: splitHours(cr.WorkingHours),
+		})
+	})
+
+	return w.Finish(err)
+}
+
+// ==========================================
+
+// =============================================
+// ========== GET /export/assignments ==========
+// =============================================
+
+type AssignmentExportItem struct {
+	Date          string     `json:"date"`
+	CourierId     uint64     `json:"courier_id"`
+	GroupOrderId  uint64     `json:"group_order_id"`
+	StartTime     time.Time  `json:"start_time"`
+	EndTime       time.Time  `json:"end_time"`
+	OrderId       uint64     `json:"order_id"`
+	Regions       int32      `json:"regions"`
+	CompletedTime *time.Time `json:"completed_time"`
+}
+
+func (c *ExportController) Assignments(ctx echo.Context) error {
+
+	date, err := queryDate(ctx, "date")
+	if err != nil {
+		return err
+	}
+	from, err := queryDate(ctx, "from")
+	if err != nil {
+		return err
+	}
+	to, err := queryDate(ctx, "to")
+	if err != nil {
+		return err
+	}
+
+	filter := repositories.AssignmentExportFilter{}
+	switch {
+	case date != nil:
+		filter.From, filter.To = *date, *date
+	case from != nil && to != nil:
+		filter.From, filter.To = *from, *to
+	default:
+		return echo.NewHTTPError(http.StatusBadRequest, ":date or both :from and :to are required")
+	}
+
+	if filter.Region, err = queryInt32(ctx, "region"); err != nil {
+		return err
+	}
+	if filter.CourierID, err = queryUint64(ctx, "courier_id"); err != nil {
+		return err
+	}
+
+	w, err := newExportWriter(ctx, "assignments", []string{
+		"date", "courier_id", "group_order_id", "start_time", "end_time", "order_id", "regions", "completed_time",
+	})
+	if err != nil {
+		return err
+	}
+
+	err = c.couriers.ExportAssignments(ctx.Request().Context(), filter, func(a repositories.AssignmentExportDTO) error {
+		item := AssignmentExportItem{
+			Date:          a.AssignDate.Format("2006-01-02"),
+			CourierId:     a.CourierID,
+			GroupOrderId:  a.DeliveryGroupID,
+			StartTime:     a.StartDateTime.UTC(),
+			EndTime:       a.EndDateTime.UTC(),
+			OrderId:       a.OrderID,
+			Regions:       a.Regions,
+			CompletedTime: a.CompletedTime,
+		}
+
+		return w.Write([]string{
+			item.Date,
+			strconv.FormatUint(a.CourierID, 10),
+			strconv.FormatUint(a.DeliveryGroupID, 10),
+			formatTime(&item.StartTime),
+			formatTime(&item.EndTime),
+			strconv.FormatUint(a.OrderID, 10),
+			strconv.Itoa(int(a.Regions)),
+			formatTime(a.CompletedTime),
+		}, item)
+	})
+
+	return w.Finish(err)
+}
+
+// =============================================
+
+// splitHours turns `10:00-12:00;14:00-16:00` into list of intervals.
+func splitHours(s string) []string {
+	if s == "" {
+		return []string{}
+	}
+	return strings.Split(s, ";")
+}
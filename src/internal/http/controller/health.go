@@ -0,0 +1,47 @@
+package controller
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"yandex-team.ru/bstask/internal/health"
+)
+
+type HealthController struct {
+	readiness *health.Checker
+}
+
+func NewHealthController(readiness *health.Checker) HealthController {
+	return HealthController{
+		readiness: readiness,
+	}
+}
+
+// ===================================
+// ========== GET /healthz ===========
+// ===================================
+
+type HealthResponse struct {
+	Status string `json:"status"`
+}
+
+// Liveness does not touch dependencies: process that serves http is alive.
+func (c *HealthController) Liveness(ctx echo.Context) error {
+	return ctx.JSON(http.StatusOK, HealthResponse{Status: health.STATUS_OK})
+}
+
+// ===================================
+
+// ===================================
+// ========== GET /readyz ============
+// ===================================
+
+func (c *HealthController) Readiness(ctx echo.Context) error {
+
+	report := c.readiness.Run(ctx.Request().Context())
+	if !report.Ok() {
+		return ctx.JSON(http.StatusServiceUnavailable, report)
+	}
+
+	return ctx.JSON(http.StatusOK, report)
+}
@@ -0,0 +1,65 @@
+package controller
+
+import (
+	"mime"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"yandex-team.ru/bstask/internal/importer"
+	"yandex-team.ru/bstask/internal/usecase/bulk"
+)
+
+type ImportResponse struct {
+	Mode     string           `json:"mode"`
+	Imported int              `json:"imported"`
+	Failed   int              `json:"failed"`
+	Errors   []ImportRowError `json:"errors"`
+}
+
+type ImportRowError struct {
+	Line    int    `json:"line"`
+	Message string `json:"message"`
+}
+
+// importParams reads format from Content-Type and mode from `mode` query param.
+func importParams(ctx echo.Context) (importer.Format, bulk.Mode, error) {
+	mediaType, _, err := mime.ParseMediaType(ctx.Request().Header.Get(echo.HeaderContentType))
+	if err != nil {
+		return "", "", echo.NewHTTPError(http.StatusUnsupportedMediaType, "Content-Type must be text/csv or application/x-ndjson")
+	}
+
+	format, err := importer.FormatFromMediaType(mediaType)
+	if err != nil {
+		return "", "", echo.NewHTTPError(http.StatusUnsupportedMediaType, "Content-Type must be text/csv or application/x-ndjson")
+	}
+
+	mode := bulk.MODE_ATOMIC
+	if m := ctx.QueryParam("mode"); m != "" {
+		if !bulk.ValidMode(m) {
+			return "", "", echo.NewHTTPError(http.StatusBadRequest, ":mode must be atomic or best_effort")
+		}
+		mode = bulk.Mode(m)
+	}
+
+	return format, mode, nil
+}
+
+// importResult responds 400 when atomic import was rolled back, 200 otherwise.
+func importResult(ctx echo.Context, res *bulk.Result) error {
+	resp := ImportResponse{
+		Mode:     string(res.Mode),
+		Imported: res.Imported,
+		Failed:   res.Failed,
+		Errors:   []ImportRowError{},
+	}
+	for _, e := range res.Errors {
+		resp.Errors = append(resp.Errors, ImportRowError{Line: e.Line, Message: e.Err.Error()})
+	}
+
+	status := http.StatusOK
+	if res.Rejected() {
+		status = http.StatusBadRequest
+	}
+
+	return ctx.JSON(status, resp)
+}
@@ -1,14 +1,18 @@
 package controller
 
 import (
-	"context"
 	"math"
 	"net/http"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/labstack/echo/v4"
+	"yandex-team.ru/bstask/internal/entity"
+	"yandex-team.ru/bstask/internal/importer"
+	"yandex-team.ru/bstask/internal/repository/repositories"
 	"yandex-team.ru/bstask/internal/usecase/order"
+	"yandex-team.ru/bstask/pkg/geo"
 )
 
 type OrderController struct {
@@ -22,6 +26,33 @@ type OrderDto struct {
 	DeliveryHours []string   `json:"delivery_hours"`
 	Cost          uint32     `json:"cost"`
 	CompletedTime *time.Time `json:"completed_time"`
+	Lat           *float64   `json:"lat,omitempty"`
+	Lon           *float64   `json:"lon,omitempty"`
+	// Stop number in the delivery group and planned arrival
+	DeliverySequence *int32     `json:"delivery_sequence,omitempty"`
+	ETA              *time.Time `json:"eta,omitempty"`
+	// One of delivery_hours the delivery is planned in
+	DeliveryWindow *string    `json:"delivery_window,omitempty"`
+	Priority       string     `json:"priority"`
+	Deadline       *time.Time `json:"deadline,omitempty"`
+	// Litres and centimetres
+	Volume     *float64            `json:"volume,omitempty"`
+	Dimensions *OrderDimensionsDto `json:"dimensions,omitempty"`
+	Items      []OrderItemDto      `json:"items,omitempty"`
+	Handling   []string            `json:"handling,omitempty"`
+	StoreID    *uint64             `json:"store_id,omitempty"`
+}
+
+type OrderItemDto struct {
+	SKU        string  `json:"sku" validate:"required"`
+	Quantity   uint32  `json:"quantity" validate:"required,min=1"`
+	UnitWeight float64 `json:"unit_weight" validate:"min=0"`
+}
+
+type OrderDimensionsDto struct {
+	Length float64 `json:"length" validate:"gt=0"`
+	Width  float64 `json:"width" validate:"gt=0"`
+	Height float64 `json:"height" validate:"gt=0"`
 }
 
 func NewOrderController(uc *order.OrderUseCase) OrderController {
@@ -33,6 +64,14 @@ func NewOrderController(uc *order.OrderUseCase) OrderController {
 // ===================================
 // ========== GET /orders ============
 // ===================================
+
+type OrderGetAllPageResponse struct {
+	Orders     []OrderDto `json:"orders"`
+	Limit      int32      `json:"limit"`
+	NextCursor *string    `json:"next_cursor,omitempty"`
+	Total      *int64     `json:"total,omitempty"`
+}
+
 func (c *OrderController) GetAll(ctx echo.Context) error {
 
 	var limit int = 1
@@ -55,31 +94,145 @@ func (c *OrderController) GetAll(ctx echo.Context) error {
 		}
 	}
 
-	orders, err := c.uc.PaginatedGetAll(context.Background(), int32(offset), int32(limit))
+	query, err := orderQueryParams(ctx)
 	if err != nil {
 		return err
 	}
 
+	// bare array is kept for clients of offset pagination
+	if !ctx.QueryParams().Has("cursor") {
+		orders, err := c.uc.PaginatedGetAll(ctx.Request().Context(), query, int32(offset), int32(limit))
+		if err != nil {
+			return err
+		}
+
+		return ctx.JSON(200, toOrderDtos(*orders))
+	}
+
+	if offsetParam != "" {
+		return echo.NewHTTPError(400, "'offset' and 'cursor' params are mutually exclusive")
+	}
+
+	page, err := c.uc.KeysetGetAll(ctx.Request().Context(), query, ctx.QueryParam("cursor"), int32(limit), ctx.QueryParam("total") == "true")
+	if err != nil {
+		return err
+	}
+
+	return ctx.JSON(200, OrderGetAllPageResponse{
+		Orders:     toOrderDtos(page.Orders),
+		Limit:      int32(limit),
+		NextCursor: page.NextCursor,
+		Total:      page.Total,
+	})
+}
+
+// orderQueryParams reads filters and `sort=weight,-cost` of order listing.
+func orderQueryParams(ctx echo.Context) (repositories.OrderQuery, error) {
+
+	var q repositories.OrderQuery
+	f := &q.Filter
+
+	if region, err := queryInt32(ctx, "region"); err != nil {
+		return q, err
+	} else if region != 0 {
+		f.Region = &region
+	}
+
+	var err error
+	if f.MinWeight, err = queryFloat(ctx, "min_weight"); err != nil {
+		return q, err
+	}
+	if f.MaxWeight, err = queryFloat(ctx, "max_weight"); err != nil {
+		return q, err
+	}
+	if f.MinCost, err = queryUint32(ctx, "min_cost"); err != nil {
+		return q, err
+	}
+	if f.MaxCost, err = queryUint32(ctx, "max_cost"); err != nil {
+		return q, err
+	}
+	if f.Assigned, err = queryBool(ctx, "assigned"); err != nil {
+		return q, err
+	}
+	if f.Completed, err = queryBool(ctx, "completed"); err != nil {
+		return q, err
+	}
+	if f.CompletedFrom, err = queryTime(ctx, "completed_from"); err != nil {
+		return q, err
+	}
+	if f.CompletedTo, err = queryTime(ctx, "completed_to"); err != nil {
+		return q, err
+	}
+
+	if f.DeliveryWindow, err = queryTimeWindow(ctx, "delivery_window_overlaps"); err != nil {
+		return q, err
+	}
+
+	if sort := ctx.QueryParam("sort"); sort != "" {
+		for _, field := range strings.Split(sort, ",") {
+			s := repositories.OrderSort{Field: strings.TrimSpace(field)}
+			if strings.HasPrefix(s.Field, "-") {
+				s.Field, s.Desc = s.Field[1:], true
+			}
+			q.Sort = append(q.Sort, s)
+		}
+	}
+
+	return q, nil
+}
+
+func toOrderDtos(orders []entity.Order) []OrderDto {
 	res := []OrderDto{}
 
-	for _, order := range *orders {
+	for _, order := range orders {
+		res = append(res, toOrderDto(order))
+	}
+
+	return res
+}
+
+func toOrderDto(order entity.Order) OrderDto {
+	dh := []string{}
+	var window *string
+	for _, t := range order.DeliveryHours {
+		interval := t.StartTime.Format("15:04") + "-" + t.EndTime.Format("15:04")
+		dh = append(dh, interval)
 
-		dh := []string{}
-		for _, t := range order.DeliveryHours {
-			dh = append(dh, t.StartTime.Format("15:04")+"-"+t.EndTime.Format("15:04"))
+		if order.DeliveryWindowID != nil && *order.DeliveryWindowID == t.ID {
+			window = &interval
 		}
+	}
 
-		res = append(res, OrderDto{
-			ID:            order.ID,
-			Weight:        order.Weight,
-			Regions:       order.Regions,
-			DeliveryHours: dh,
-			Cost:          order.Cost,
-			CompletedTime: order.CompletedTime,
-		})
+	dto := OrderDto{
+		ID:               order.ID,
+		Weight:           order.Weight,
+		Regions:          order.Regions,
+		DeliveryHours:    dh,
+		Cost:             order.Cost,
+		CompletedTime:    order.CompletedTime,
+		DeliverySequence: order.DeliverySequence,
+		ETA:              order.ETA,
+		DeliveryWindow:   window,
+		Priority:         string(order.Priority),
+		Deadline:         order.Deadline,
+		Volume:           order.Volume,
+		StoreID:          order.StoreID,
+	}
+	if d := order.Dimensions; d != nil {
+		dto.Dimensions = &OrderDimensionsDto{Length: d.Length, Width: d.Width, Height: d.Height}
+	}
+	for _, i := range order.Items {
+		dto.Items = append(dto.Items, OrderItemDto{SKU: i.SKU, Quantity: i.Quantity, UnitWeight: i.UnitWeight})
+	}
+	for _, f := range order.Handling {
+		dto.Handling = append(dto.Handling, string(f))
+	}
+	if order.Location != nil {
+		dto.Lat = &order.Location.Lat
+		dto.Lon = &order.Location.Lon
 	}
 
-	return ctx.JSON(200, res)
+	return dto
 }
 
 // ===================================
@@ -96,6 +249,20 @@ type OrderRequestCreateDto struct {
 	Regions       int32    `json:"regions" validate:"required,max=2147483647"`
 	DeliveryHours []string `json:"delivery_hours" validate:"required"`
 	Cost          uint32   `json:"cost" validate:"required,min=0,max=2147483647"`
+	// Optional delivery address, both or none.
+	Lat *float64 `json:"lat" validate:"required_with=Lon"`
+	Lon *float64 `json:"lon" validate:"required_with=Lat"`
+	// EXPRESS, STANDARD or ECONOMY, STANDARD by default.
+	Priority string     `json:"priority"`
+	Deadline *time.Time `json:"deadline"`
+	// Optional size, volume defaults to the box of dimensions.
+	Volume     *float64            `json:"volume" validate:"omitempty,gt=0"`
+	Dimensions *OrderDimensionsDto `json:"dimensions"`
+	Items      []OrderItemDto      `json:"items" validate:"max=1000,dive"`
+	// COLD_CHAIN, FRAGILE, ID_CHECK or OVERSIZED
+	Handling []string `json:"handling" validate:"max=4"`
+	// Pickup store, region depot if not set.
+	StoreID *uint64 `json:"store_id"`
 }
 
 type OrderCreateResponse struct {
@@ -116,15 +283,31 @@ func (c *OrderController) Create(ctx echo.Context) error {
 	newOrders := []order.OrderToCreateDTO{}
 	for _, newCourier := range req.Orders {
 
-		newOrders = append(newOrders, order.OrderToCreateDTO{
+		dto := order.OrderToCreateDTO{
 			Weight:        newCourier.Weight,
 			Regions:       newCourier.Regions,
 			DeliveryHours: newCourier.DeliveryHours,
 			Cost:          newCourier.Cost,
-		})
+			Priority:      newCourier.Priority,
+			Deadline:      newCourier.Deadline,
+			Volume:        newCourier.Volume,
+			StoreID:       newCourier.StoreID,
+		}
+		if d := newCourier.Dimensions; d != nil {
+			dto.Dimensions = &entity.Dimensions{Length: d.Length, Width: d.Width, Height: d.Height}
+		}
+		for _, i := range newCourier.Items {
+			dto.Items = append(dto.Items, order.OrderItemToCreateDTO{SKU: i.SKU, Quantity: i.Quantity, UnitWeight: i.UnitWeight})
+		}
+		dto.Handling = newCourier.Handling
+		if newCourier.Lat != nil && newCourier.Lon != nil {
+			dto.Location = &geo.Point{Lat: *newCourier.Lat, Lon: *newCourier.Lon}
+		}
+
+		newOrders = append(newOrders, dto)
 	}
 
-	savedOrders, err := c.uc.CreateOrders(context.Background(), newOrders)
+	savedOrders, err := c.uc.CreateOrders(ctx.Request().Context(), newOrders)
 	if err != nil {
 		return err
 	}
@@ -133,19 +316,7 @@ func (c *OrderController) Create(ctx echo.Context) error {
 	res.Orders = []OrderDto{}
 
 	for _, newOrder := range *savedOrders {
-
-		dh := []string{}
-		for _, t := range newOrder.DeliveryHours {
-			dh = append(dh, t.StartTime.Format("15:04")+"-"+t.EndTime.Format("15:04"))
-		}
-
-		res.Orders = append(res.Orders, OrderDto{
-			ID:            newOrder.ID,
-			Weight:        newOrder.Weight,
-			Regions:       newOrder.Regions,
-			DeliveryHours: dh,
-			Cost:          newOrder.Cost,
-		})
+		res.Orders = append(res.Orders, toOrderDto(newOrder))
 	}
 
 	return ctx.JSON(200, res)
@@ -153,6 +324,32 @@ func (c *OrderController) Create(ctx echo.Context) error {
 
 // ====================================
 
+// =========================================
+// ========== POST /orders/import ==========
+// =========================================
+
+func (c *OrderController) Import(ctx echo.Context) error {
+
+	format, mode, err := importParams(ctx)
+	if err != nil {
+		return err
+	}
+
+	rows, err := importer.NewOrderReader(format, ctx.Request().Body)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	res, err := c.uc.Import(ctx.Request().Context(), rows, mode)
+	if err != nil {
+		return err
+	}
+
+	return importResult(ctx, res)
+}
+
+// =========================================
+
 // ==============================================
 // ========== POST /orders/:order_id ============
 // ==============================================
@@ -166,24 +363,12 @@ func (c *OrderController) GetById(ctx echo.Context) error {
 		return echo.NewHTTPError(http.StatusBadRequest, ":order_id must be valid int64")
 	}
 
-	order, err := c.uc.GetById(context.Background(), uint64(orderId))
+	order, err := c.uc.GetById(ctx.Request().Context(), uint64(orderId))
 	if err != nil {
 		return err
 	}
 
-	dh := []string{}
-	for _, t := range order.DeliveryHours {
-		dh = append(dh, t.StartTime.Format("15:04")+"-"+t.EndTime.Format("15:04"))
-	}
-
-	return ctx.JSON(200, OrderDto{
-		ID:            order.ID,
-		Weight:        order.Weight,
-		Regions:       order.Regions,
-		DeliveryHours: dh,
-		Cost:          order.Cost,
-		CompletedTime: order.CompletedTime,
-	})
+	return ctx.JSON(200, toOrderDto(*order))
 }
 
 // =============================================
@@ -222,7 +407,7 @@ func (c *OrderController) Complete(ctx echo.Context) error {
 		})
 	}
 
-	orders, err := c.uc.Complete(context.Background(), toComplete)
+	orders, err := c.uc.Complete(ctx.Request().Context(), toComplete)
 	if err != nil {
 		return err
 	}
@@ -230,20 +415,7 @@ func (c *OrderController) Complete(ctx echo.Context) error {
 	res := []OrderDto{}
 	if orders != nil {
 		for _, o := range *orders {
-
-			dh := []string{}
-			for _, t := range o.DeliveryHours {
-				dh = append(dh, t.StartTime.Format("15:04")+"-"+t.EndTime.Format("15:04"))
-			}
-
-			res = append(res, OrderDto{
-				ID:            o.ID,
-				Weight:        o.Weight,
-				Regions:       o.Regions,
-				DeliveryHours: dh,
-				Cost:          o.Cost,
-				CompletedTime: o.CompletedTime,
-			})
+			res = append(res, toOrderDto(o))
 		}
 	}
 
@@ -259,6 +431,8 @@ func (c *OrderController) Complete(ctx echo.Context) error {
 type OrderAssignByDateResponseItem struct {
 	Date     string                    `json:"date"`
 	Couriers []AssignResponseGroupItem `json:"couriers"`
+	// unassigned orders due by the end of the date
+	SLABreaching []OrderDto `json:"sla_breaching"`
 }
 
 type AssignResponseGroupItem struct {
@@ -285,13 +459,14 @@ func (c *OrderController) Assign(ctx echo.Context) error {
 		}
 	}
 
-	assigns, err := c.uc.AssignByDate(context.Background(), assignDate)
+	assigns, err := c.uc.AssignByDate(ctx.Request().Context(), assignDate)
 	if err != nil {
 		return err
 	}
 
 	res := OrderAssignByDateResponseItem{
-		Date: assignDate.Format("2006-01-02"),
+		Date:         assignDate.Format("2006-01-02"),
+		SLABreaching: toOrderDtos(assigns.SLABreaching),
 	}
 
 	for _, courier := range assigns.Couriers {
@@ -305,20 +480,7 @@ func (c *OrderController) Assign(ctx echo.Context) error {
 				GroupOrderId: orderGroup.GroupOrderId,
 			}
 			for _, order := range orderGroup.Orders {
-
-				dh := []string{}
-				for _, t := range order.DeliveryHours {
-					dh = append(dh, t.StartTime.Format("15:04")+"-"+t.EndTime.Format("15:04"))
-				}
-
-				assignOrdersGroup.Orders = append(assignOrdersGroup.Orders, OrderDto{
-					ID:            order.ID,
-					Weight:        order.Weight,
-					Regions:       order.Regions,
-					DeliveryHours: dh,
-					Cost:          order.Cost,
-					CompletedTime: order.CompletedTime,
-				})
+				assignOrdersGroup.Orders = append(assignOrdersGroup.Orders, toOrderDto(order))
 			}
 
 			assignResponseGroupItem.Orders = append(assignResponseGroupItem.Orders, assignOrdersGroup)
@@ -0,0 +1,158 @@
+package controller
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"yandex-team.ru/bstask/internal/repository/repositories"
+)
+
+// Helpers for optional query params, nil or zero means the param is absent.
+
+func queryDate(ctx echo.Context, name string) (*time.Time, error) {
+	v := ctx.QueryParam(name)
+	if v == "" {
+		return nil, nil
+	}
+
+	t, err := time.Parse("2006-01-02", v)
+	if err != nil {
+		return nil, echo.NewHTTPError(http.StatusBadRequest, ":"+name+" must be YYYY-MM-DD date")
+	}
+
+	return &t, nil
+}
+
+func queryInt32(ctx echo.Context, name string) (int32, error) {
+	v := ctx.QueryParam(name)
+	if v == "" {
+		return 0, nil
+	}
+
+	i, err := strconv.ParseInt(v, 10, 32)
+	if err != nil || i <= 0 {
+		return 0, echo.NewHTTPError(http.StatusBadRequest, ":"+name+" must be valid positive int32")
+	}
+
+	return int32(i), nil
+}
+
+func queryUint64(ctx echo.Context, name string) (uint64, error) {
+	v := ctx.QueryParam(name)
+	if v == "" {
+		return 0, nil
+	}
+
+	i, err := strconv.ParseUint(v, 10, 64)
+	if err != nil || i == 0 || i > math.MaxInt64 {
+		return 0, echo.NewHTTPError(http.StatusBadRequest, ":"+name+" must be valid positive int64")
+	}
+
+	return i, nil
+}
+
+func queryFloat(ctx echo.Context, name string) (*float64, error) {
+	v := ctx.QueryParam(name)
+	if v == "" {
+		return nil, nil
+	}
+
+	f, err := strconv.ParseFloat(v, 64)
+	if err != nil || f < 0 {
+		return nil, echo.NewHTTPError(http.StatusBadRequest, ":"+name+" must be valid non-negative number")
+	}
+
+	return &f, nil
+}
+
+func queryUint32(ctx echo.Context, name string) (*uint32, error) {
+	v := ctx.QueryParam(name)
+	if v == "" {
+		return nil, nil
+	}
+
+	i, err := strconv.ParseUint(v, 10, 31)
+	if err != nil {
+		return nil, echo.NewHTTPError(http.StatusBadRequest, ":"+name+" must be valid non-negative int32")
+	}
+	u := uint32(i)
+
+	return &u, nil
+}
+
+func queryBool(ctx echo.Context, name string) (*bool, error) {
+	v := ctx.QueryParam(name)
+	if v == "" {
+		return nil, nil
+	}
+
+	b, err := strconv.ParseBool(v)
+	if err != nil {
+		return nil, echo.NewHTTPError(http.StatusBadRequest, ":"+name+" must be true or false")
+	}
+
+	return &b, nil
+}
+
+// queryTime accepts RFC 3339 timestamp or YYYY-MM-DD date.
+func queryTime(ctx echo.Context, name string) (*time.Time, error) {
+	v := ctx.QueryParam(name)
+	if v == "" {
+		return nil, nil
+	}
+
+	t, err := time.Parse(time.RFC3339, v)
+	if err != nil {
+		t, err = time.Parse("2006-01-02", v)
+	}
+	if err != nil {
+		return nil, echo.NewHTTPError(http.StatusBadRequest, ":"+name+" must be RFC 3339 time or YYYY-MM-DD date")
+	}
+
+	return &t, nil
+}
+
+// queryClock accepts HH:MM time of day.
+func queryClock(ctx echo.Context, name string) (*time.Time, error) {
+	v := ctx.QueryParam(name)
+	if v == "" {
+		return nil, nil
+	}
+
+	t, err := time.Parse("15:04", v)
+	if err != nil {
+		return nil, echo.NewHTTPError(http.StatusBadRequest, ":"+name+" must be HH:MM")
+	}
+
+	return &t, nil
+}
+
+// queryTimeWindow accepts HH:MM-HH:MM window of day, it may cross midnight.
+func queryTimeWindow(ctx echo.Context, name string) (*repositories.TimeWindow, error) {
+	v := ctx.QueryParam(name)
+	if v == "" {
+		return nil, nil
+	}
+
+	spl := strings.Split(v, "-")
+	if len(spl) != 2 {
+		return nil, echo.NewHTTPError(http.StatusBadRequest, ":"+name+" must be HH:MM-HH:MM")
+	}
+
+	start, err1 := time.Parse("15:04", spl[0])
+	end, err2 := time.Parse("15:04", spl[1])
+	if err1 != nil || err2 != nil {
+		return nil, echo.NewHTTPError(http.StatusBadRequest, ":"+name+" must be HH:MM-HH:MM")
+	}
+	// same as for stored intervals, equal ends would be a whole day in Go and
+	// an empty window in SQL
+	if start.Equal(end) {
+		return nil, echo.NewHTTPError(http.StatusBadRequest, ":"+name+" must not start and end at the same time")
+	}
+
+	return &repositories.TimeWindow{Start: start, End: end}, nil
+}
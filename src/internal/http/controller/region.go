@@ -0,0 +1,243 @@
+package controller
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+	"yandex-team.ru/bstask/internal/entity"
+	"yandex-team.ru/bstask/internal/usecase/region"
+	"yandex-team.ru/bstask/pkg/geo"
+)
+
+type RegionController struct {
+	uc *region.RegionUseCase
+}
+
+type RegionDto struct {
+	RegionId   int32     `json:"region_id"`
+	Name       string    `json:"name"`
+	Active     bool      `json:"active"`
+	Neighbours []int32   `json:"neighbours"`
+	Depot      *PointDto `json:"depot,omitempty"`
+	TimeZone   string    `json:"time_zone"`
+}
+
+type PointDto struct {
+	Lat float64 `json:"lat"`
+	Lon float64 `json:"lon"`
+}
+
+func NewRegionController(uc *region.RegionUseCase) RegionController {
+	return RegionController{
+		uc: uc,
+	}
+}
+
+// ===================================
+// ========== GET /regions ===========
+// ===================================
+
+type RegionsResponse struct {
+	Regions []RegionDto `json:"regions"`
+}
+
+func (c *RegionController) GetAll(ctx echo.Context) error {
+
+	regions, err := c.uc.GetAll(ctx.Request().Context())
+	if err != nil {
+		return err
+	}
+
+	return ctx.JSON(200, RegionsResponse{Regions: toRegionDtos(*regions)})
+}
+
+// ===================================
+
+// ===================================
+// ========== POST /regions ==========
+// ===================================
+
+type RegionCreateRequest struct {
+	Regions []RegionRequestCreateDto `json:"regions" validate:"required,min=1,dive"`
+}
+
+type RegionRequestCreateDto struct {
+	RegionId int32  `json:"region_id" validate:"required"`
+	Name     string `json:"name" validate:"required"`
+	// Active unless stated otherwise.
+	Active     *bool     `json:"active"`
+	Neighbours []int32   `json:"neighbours"`
+	Depot      *PointDto `json:"depot"`
+	// IANA name, UTC unless stated otherwise.
+	TimeZone string `json:"time_zone"`
+}
+
+func (c *RegionController) Create(ctx echo.Context) error {
+
+	var req RegionCreateRequest
+	if err := ctx.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err)
+	}
+
+	if err := ctx.Validate(req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err)
+	}
+
+	newRegions := []region.RegionDTO{}
+	for _, r := range req.Regions {
+		newRegions = append(newRegions, region.RegionDTO{
+			ID:         r.RegionId,
+			Name:       r.Name,
+			Active:     r.Active == nil || *r.Active,
+			Neighbours: r.Neighbours,
+			Depot:      toPoint(r.Depot),
+			TimeZone:   timeZoneOrDefault(r.TimeZone),
+		})
+	}
+
+	saved, err := c.uc.CreateRegions(ctx.Request().Context(), newRegions)
+	if err != nil {
+		return err
+	}
+
+	return ctx.JSON(200, RegionsResponse{Regions: toRegionDtos(*saved)})
+}
+
+// ===================================
+
+// ==============================================
+// ========== GET /regions/{region_id} ==========
+// ==============================================
+
+func (c *RegionController) GetById(ctx echo.Context) error {
+
+	id, err := regionIdParam(ctx)
+	if err != nil {
+		return err
+	}
+
+	r, err := c.uc.GetById(ctx.Request().Context(), id)
+	if err != nil {
+		return err
+	}
+
+	return ctx.JSON(200, toRegionDto(*r))
+}
+
+// ==============================================
+
+// ==============================================
+// ========== PUT /regions/{region_id} ==========
+// ==============================================
+
+type RegionUpdateRequest struct {
+	Name       string    `json:"name" validate:"required"`
+	Active     *bool     `json:"active" validate:"required"`
+	Neighbours []int32   `json:"neighbours"`
+	Depot      *PointDto `json:"depot"`
+	TimeZone   string    `json:"time_zone"`
+}
+
+func (c *RegionController) Update(ctx echo.Context) error {
+
+	id, err := regionIdParam(ctx)
+	if err != nil {
+		return err
+	}
+
+	var req RegionUpdateRequest
+	if err := ctx.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err)
+	}
+
+	if err := ctx.Validate(req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err)
+	}
+
+	r, err := c.uc.Update(ctx.Request().Context(), region.RegionDTO{
+		ID:         id,
+		Name:       req.Name,
+		Active:     *req.Active,
+		Neighbours: req.Neighbours,
+		Depot:      toPoint(req.Depot),
+		TimeZone:   timeZoneOrDefault(req.TimeZone),
+	})
+	if err != nil {
+		return err
+	}
+
+	return ctx.JSON(200, toRegionDto(*r))
+}
+
+// ==============================================
+
+// =================================================
+// ========== DELETE /regions/{region_id} ==========
+// =================================================
+
+func (c *RegionController) Delete(ctx echo.Context) error {
+
+	id, err := regionIdParam(ctx)
+	if err != nil {
+		return err
+	}
+
+	if err := c.uc.Delete(ctx.Request().Context(), id); err != nil {
+		return err
+	}
+
+	return ctx.NoContent(http.StatusNoContent)
+}
+
+// =================================================
+
+func regionIdParam(ctx echo.Context) (int32, error) {
+	id, err := strconv.ParseInt(ctx.Param("region_id"), 10, 32)
+	if err != nil || id <= 0 || id > math.MaxInt32 {
+		return 0, echo.NewHTTPError(http.StatusBadRequest, ":region_id must be valid positive int32")
+	}
+
+	return int32(id), nil
+}
+
+func timeZoneOrDefault(tz string) string {
+	if tz == "" {
+		return entity.DEFAULT_TIME_ZONE
+	}
+
+	return tz
+}
+
+func toRegionDto(r entity.Region) RegionDto {
+	dto := RegionDto{
+		RegionId:   r.ID,
+		Name:       r.Name,
+		Active:     r.Active,
+		Neighbours: r.Neighbours,
+		TimeZone:   r.TimeZone,
+	}
+	if r.Depot != nil {
+		dto.Depot = &PointDto{Lat: r.Depot.Lat, Lon: r.Depot.Lon}
+	}
+
+	return dto
+}
+
+func toPoint(p *PointDto) *geo.Point {
+	if p == nil {
+		return nil
+	}
+
+	return &geo.Point{Lat: p.Lat, Lon: p.Lon}
+}
+
+func toRegionDtos(regions []entity.Region) []RegionDto {
+	res := []RegionDto{}
+	for _, r := range regions {
+		res = append(res, toRegionDto(r))
+	}
+
+	return res
+}
@@ -0,0 +1,205 @@
+package controller
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+	"yandex-team.ru/bstask/internal/entity"
+	"yandex-team.ru/bstask/internal/usecase/store"
+)
+
+type StoreController struct {
+	uc *store.StoreUseCase
+}
+
+type StoreDto struct {
+	StoreId       uint64   `json:"store_id"`
+	Name          string   `json:"name"`
+	Region        int32    `json:"region"`
+	Location      PointDto `json:"location"`
+	PickupMinutes uint32   `json:"pickup_minutes"`
+}
+
+func NewStoreController(uc *store.StoreUseCase) StoreController {
+	return StoreController{
+		uc: uc,
+	}
+}
+
+// ==================================
+// ========== GET /stores ===========
+// ==================================
+
+type StoresResponse struct {
+	Stores []StoreDto `json:"stores"`
+}
+
+func (c *StoreController) GetAll(ctx echo.Context) error {
+
+	stores, err := c.uc.GetAll(ctx.Request().Context())
+	if err != nil {
+		return err
+	}
+
+	return ctx.JSON(200, StoresResponse{Stores: toStoreDtos(*stores)})
+}
+
+// ==================================
+
+// ==================================
+// ========== POST /stores ==========
+// ==================================
+
+type StoreCreateRequest struct {
+	Stores []StoreRequestDto `json:"stores" validate:"required,min=1,dive"`
+}
+
+type StoreRequestDto struct {
+	Name     string    `json:"name" validate:"required"`
+	Region   int32     `json:"region" validate:"required"`
+	Location *PointDto `json:"location" validate:"required"`
+	// DEFAULT_PICKUP_MINUTES unless stated otherwise.
+	PickupMinutes *uint32 `json:"pickup_minutes"`
+}
+
+func (c *StoreController) Create(ctx echo.Context) error {
+
+	var req StoreCreateRequest
+	if err := ctx.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err)
+	}
+
+	if err := ctx.Validate(req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err)
+	}
+
+	newStores := []store.StoreDTO{}
+	for _, s := range req.Stores {
+		newStores = append(newStores, toStoreUseCaseDto(0, s))
+	}
+
+	saved, err := c.uc.CreateStores(ctx.Request().Context(), newStores)
+	if err != nil {
+		return err
+	}
+
+	return ctx.JSON(200, StoresResponse{Stores: toStoreDtos(*saved)})
+}
+
+// ==================================
+
+// ============================================
+// ========== GET /stores/{store_id} ==========
+// ============================================
+
+func (c *StoreController) GetById(ctx echo.Context) error {
+
+	id, err := storeIdParam(ctx)
+	if err != nil {
+		return err
+	}
+
+	s, err := c.uc.GetById(ctx.Request().Context(), id)
+	if err != nil {
+		return err
+	}
+
+	return ctx.JSON(200, toStoreDto(*s))
+}
+
+// ============================================
+
+// ============================================
+// ========== PUT /stores/{store_id} ==========
+// ============================================
+
+func (c *StoreController) Update(ctx echo.Context) error {
+
+	id, err := storeIdParam(ctx)
+	if err != nil {
+		return err
+	}
+
+	var req StoreRequestDto
+	if err := ctx.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err)
+	}
+
+	if err := ctx.Validate(req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err)
+	}
+
+	s, err := c.uc.Update(ctx.Request().Context(), toStoreUseCaseDto(id, req))
+	if err != nil {
+		return err
+	}
+
+	return ctx.JSON(200, toStoreDto(*s))
+}
+
+// ============================================
+
+// ===============================================
+// ========== DELETE /stores/{store_id} ==========
+// ===============================================
+
+func (c *StoreController) Delete(ctx echo.Context) error {
+
+	id, err := storeIdParam(ctx)
+	if err != nil {
+		return err
+	}
+
+	if err := c.uc.Delete(ctx.Request().Context(), id); err != nil {
+		return err
+	}
+
+	return ctx.NoContent(http.StatusNoContent)
+}
+
+// ===============================================
+
+func storeIdParam(ctx echo.Context) (uint64, error) {
+	id, err := strconv.ParseInt(ctx.Param("store_id"), 10, 64)
+	if err != nil || id <= 0 || id > math.MaxInt64 {
+		return 0, echo.NewHTTPError(http.StatusBadRequest, ":store_id must be valid positive int64")
+	}
+
+	return uint64(id), nil
+}
+
+func toStoreUseCaseDto(id uint64, req StoreRequestDto) store.StoreDTO {
+	pickup := uint32(entity.DEFAULT_PICKUP_MINUTES)
+	if req.PickupMinutes != nil {
+		pickup = *req.PickupMinutes
+	}
+
+	return store.StoreDTO{
+		ID:            id,
+		Name:          req.Name,
+		Region:        req.Region,
+		Location:      *toPoint(req.Location),
+		PickupMinutes: pickup,
+	}
+}
+
+func toStoreDto(s entity.Store) StoreDto {
+	return StoreDto{
+		StoreId:       s.ID,
+		Name:          s.Name,
+		Region:        s.Region,
+		Location:      PointDto{Lat: s.Location.Lat, Lon: s.Location.Lon},
+		PickupMinutes: uint32(s.PickupTime.Minutes()),
+	}
+}
+
+func toStoreDtos(stores []entity.Store) []StoreDto {
+	res := []StoreDto{}
+	for _, s := range stores {
+		res = append(res, toStoreDto(s))
+	}
+
+	return res
+}
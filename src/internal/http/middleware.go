@@ -1,16 +1,35 @@
 package http
 
 import (
+	"context"
 	"net/http"
+	"strconv"
 	"time"
 
 	"github.com/labstack/echo/v4"
 	"github.com/labstack/echo/v4/middleware"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.21.0"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+	"yandex-team.ru/bstask/internal/metrics"
+	"yandex-team.ru/bstask/pkg/logger"
+	"yandex-team.ru/bstask/pkg/tracing"
 )
 
 func RatelimiterConfig() middleware.RateLimiterConfig {
 	return middleware.RateLimiterConfig{
-		Skipper: middleware.DefaultSkipper,
+		Skipper: func(c echo.Context) bool {
+			// probes and scrapes must not be throttled
+			switch c.Path() {
+			case "/healthz", "/readyz", "/metrics":
+				return true
+			}
+			return false
+		},
 		Store: middleware.NewRateLimiterMemoryStoreWithConfig(
 			middleware.RateLimiterMemoryStoreConfig{Rate: 10, Burst: 0, ExpiresIn: time.Second},
 		),
@@ -22,7 +41,144 @@ func RatelimiterConfig() middleware.RateLimiterConfig {
 			return context.JSON(http.StatusForbidden, nil)
 		},
 		DenyHandler: func(context echo.Context, identifier string, err error) error {
+			metrics.RateLimiterRejectionsTotal.WithLabelValues(routeLabel(context)).Inc()
 			return context.JSON(http.StatusTooManyRequests, nil)
 		},
 	}
 }
+
+// RequestTimeout puts a deadline on the request context, so every
+// usecase and query started by the handler is cancelled after `timeout`.
+// Client disconnects cancel the same context.
+func RequestTimeout(timeout time.Duration) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if timeout <= 0 {
+				return next(c)
+			}
+
+			ctx, cancel := context.WithTimeout(c.Request().Context(), timeout)
+			defer cancel()
+
+			c.SetRequest(c.Request().WithContext(ctx))
+
+			return next(c)
+		}
+	}
+}
+
+// RequestIDConfig takes X-Request-ID from the request or generates a new one,
+// echoes it back and binds it to the logger of the request context.
+func RequestIDConfig() middleware.RequestIDConfig {
+	return middleware.RequestIDConfig{
+		Skipper:      middleware.DefaultSkipper,
+		TargetHeader: echo.HeaderXRequestID,
+		RequestIDHandler: func(c echo.Context, id string) {
+			ctx := logger.WithRequestID(c.Request().Context(), id)
+			c.SetRequest(c.Request().WithContext(ctx))
+		},
+	}
+}
+
+func RequestLoggerConfig() middleware.RequestLoggerConfig {
+	return middleware.RequestLoggerConfig{
+		Skipper:      middleware.DefaultSkipper,
+		LogMethod:    true,
+		LogURI:       true,
+		LogRoutePath: true,
+		LogStatus:    true,
+		LogLatency:   true,
+		LogRemoteIP:  true,
+		LogValuesFunc: func(c echo.Context, v middleware.RequestLoggerValues) error {
+			logger.FromContext(c.Request().Context()).Info(
+				"request",
+				zap.String("method", v.Method),
+				zap.String("uri", v.URI),
+				zap.String("route", v.RoutePath),
+				zap.Int("status", v.Status),
+				zap.Duration("latency", v.Latency),
+				zap.String("remote_ip", v.RemoteIP),
+			)
+			return nil
+		},
+	}
+}
+
+// Metrics observes latency and status code of every request by route.
+func Metrics() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			start := time.Now()
+
+			// commit response now, to know the status code; the error is
+			// handled here, so it is not passed up to be handled again
+			if err := next(c); err != nil {
+				c.Error(err)
+			}
+
+			route := routeLabel(c)
+			method := c.Request().Method
+
+			metrics.HttpRequestDuration.WithLabelValues(method, route).Observe(time.Since(start).Seconds())
+			metrics.HttpRequestsTotal.WithLabelValues(method, route, strconv.Itoa(c.Response().Status)).Inc()
+
+			return nil
+		}
+	}
+}
+
+// routeLabel returns route template instead of raw path to keep labels cardinality low.
+func routeLabel(c echo.Context) string {
+	if c.Path() == "" {
+		return "unmatched"
+	}
+
+	return c.Path()
+}
+
+// Tracing continues trace from W3C `traceparent` header or starts a new one
+// and wraps the request into a server span.
+func Tracing() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			req := c.Request()
+			route := routeLabel(c)
+
+			ctx := otel.GetTextMapPropagator().Extract(req.Context(), propagation.HeaderCarrier(req.Header))
+			ctx, span := tracing.Tracer().Start(
+				ctx,
+				req.Method+" "+route,
+				trace.WithSpanKind(trace.SpanKindServer),
+				trace.WithAttributes(
+					semconv.HTTPMethod(req.Method),
+					semconv.HTTPRoute(route),
+					semconv.HTTPTarget(req.URL.RequestURI()),
+				),
+			)
+			defer span.End()
+
+			if id := logger.RequestIDFromContext(ctx); id != "" {
+				span.SetAttributes(attribute.String("request_id", id))
+			}
+			if sc := span.SpanContext(); sc.IsValid() {
+				ctx = logger.WithContext(ctx, logger.FromContext(ctx).With(zap.String("trace_id", sc.TraceID().String())))
+			}
+
+			c.SetRequest(req.WithContext(ctx))
+
+			// as in Metrics, the error is handled once, right here
+			if err := next(c); err != nil {
+				c.Error(err)
+				span.RecordError(err)
+			}
+
+			status := c.Response().Status
+			span.SetAttributes(semconv.HTTPStatusCode(status))
+			if status >= 500 {
+				span.SetStatus(codes.Error, http.StatusText(status))
+			}
+
+			return nil
+		}
+	}
+}
@@ -4,41 +4,83 @@ import (
 	"net/http"
 
 	"github.com/labstack/echo/v4"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"yandex-team.ru/bstask/config"
 	"yandex-team.ru/bstask/internal/http/controller"
+	"yandex-team.ru/bstask/internal/metrics"
 )
 
 type Router struct {
 	Controllers Controllers
+	conf        config.HttpConfig
 }
 
 type Controllers struct {
 	CourierController controller.CourierController
 	OrderController   controller.OrderController
+	HealthController  controller.HealthController
+	ExportController  controller.ExportController
+	RegionController  controller.RegionController
+	StoreController   controller.StoreController
 }
 
-func NewRouter(cs Controllers) *Router {
+func NewRouter(cs Controllers, conf config.HttpConfig) *Router {
 	return &Router{
 		Controllers: cs,
+		conf:        conf,
 	}
 }
 
 func (r Router) SetupRoutes(e *echo.Echo) {
 
+	timeout := RequestTimeout(r.conf.RequestTimeout)
+	longTimeout := RequestTimeout(r.conf.LongRequestTimeout)
+	exportTimeout := RequestTimeout(r.conf.ExportTimeout)
+
 	e.GET("/ping", func(ctx echo.Context) error {
 		return ctx.String(http.StatusOK, "pong")
 	})
 
+	e.GET("/healthz", r.Controllers.HealthController.Liveness)
+	e.GET("/readyz", r.Controllers.HealthController.Readiness, timeout)
+
+	e.GET("/metrics", echo.WrapHandler(promhttp.HandlerFor(metrics.Registry, promhttp.HandlerOpts{ErrorHandling: promhttp.ContinueOnError})))
+
 	// courier methods
-	e.GET("/couriers/assignments", r.Controllers.CourierController.Assignments)
-	e.GET("/couriers", r.Controllers.CourierController.GetAll)
-	e.POST("/couriers", r.Controllers.CourierController.Create)
-	e.GET("/couriers/:courier_id", r.Controllers.CourierController.GetById)
-	e.GET("/couriers/meta-info/:courier_id", r.Controllers.CourierController.MetaByCourierId)
+	e.GET("/couriers/assignments", r.Controllers.CourierController.Assignments, timeout)
+	e.GET("/couriers", r.Controllers.CourierController.GetAll, timeout)
+	e.POST("/couriers", r.Controllers.CourierController.Create, timeout)
+	e.POST("/couriers/import", r.Controllers.CourierController.Import, longTimeout)
+	e.GET("/couriers/:courier_id", r.Controllers.CourierController.GetById, timeout)
+	e.GET("/couriers/meta-info/:courier_id", r.Controllers.CourierController.MetaByCourierId, timeout)
+	e.POST("/couriers/:courier_id/status", r.Controllers.CourierController.ReportStatus, timeout)
+	e.POST("/couriers/:courier_id/location", r.Controllers.CourierController.ReportLocation, timeout)
+	e.GET("/couriers/:courier_id/live", r.Controllers.CourierController.Live, timeout)
 
 	// order methods
-	e.GET("/orders", r.Controllers.OrderController.GetAll)
-	e.POST("/orders", r.Controllers.OrderController.Create)
-	e.POST("/orders/complete", r.Controllers.OrderController.Complete)
-	e.POST("/orders/assign", r.Controllers.OrderController.Assign)
-	e.GET("/orders/:order_id", r.Controllers.OrderController.GetById)
+	e.GET("/orders", r.Controllers.OrderController.GetAll, timeout)
+	e.POST("/orders", r.Controllers.OrderController.Create, timeout)
+	e.POST("/orders/import", r.Controllers.OrderController.Import, longTimeout)
+	e.POST("/orders/complete", r.Controllers.OrderController.Complete, timeout)
+	e.POST("/orders/assign", r.Controllers.OrderController.Assign, longTimeout)
+	e.GET("/orders/:order_id", r.Controllers.OrderController.GetById, timeout)
+
+	// region methods
+	e.GET("/regions", r.Controllers.RegionController.GetAll, timeout)
+	e.POST("/regions", r.Controllers.RegionController.Create, timeout)
+	e.GET("/regions/:region_id", r.Controllers.RegionController.GetById, timeout)
+	e.PUT("/regions/:region_id", r.Controllers.RegionController.Update, timeout)
+	e.DELETE("/regions/:region_id", r.Controllers.RegionController.Delete, timeout)
+
+	// store methods
+	e.GET("/stores", r.Controllers.StoreController.GetAll, timeout)
+	e.POST("/stores", r.Controllers.StoreController.Create, timeout)
+	e.GET("/stores/:store_id", r.Controllers.StoreController.GetById, timeout)
+	e.PUT("/stores/:store_id", r.Controllers.StoreController.Update, timeout)
+	e.DELETE("/stores/:store_id", r.Controllers.StoreController.Delete, timeout)
+
+	// export methods
+	e.GET("/export/orders", r.Controllers.ExportController.Orders, exportTimeout)
+	e.GET("/export/couriers", r.Controllers.ExportController.Couriers, exportTimeout)
+	e.GET("/export/assignments", r.Controllers.ExportController.Assignments, exportTimeout)
 }
@@ -1,6 +1,7 @@
 package http
 
 import (
+	"context"
 	"errors"
 	"net/http"
 
@@ -9,15 +10,23 @@ import (
 	"gopkg.in/go-playground/validator.v9"
 	"yandex-team.ru/bstask"
 	"yandex-team.ru/bstask/config"
+	"yandex-team.ru/bstask/pkg/logger"
 )
 
 func NewHttpServer(conf config.AppConfig) *echo.Echo {
 	e := echo.New()
+	e.HideBanner = true
+	e.HidePort = true
 
 	e.Validator = &CustomValidator{Validator: validator.New()}
 	e.HTTPErrorHandler = HttpErrorHandler
 
 	// setup middlewares
+	e.Use(middleware.RequestIDWithConfig(RequestIDConfig()))
+	e.Use(Tracing())
+	e.Use(Metrics())
+	e.Use(middleware.RequestLoggerWithConfig(RequestLoggerConfig()))
+
 	if conf.Env != "test" {
 		e.Use(middleware.RateLimiterWithConfig(RatelimiterConfig()))
 	}
@@ -31,7 +40,23 @@ func HttpErrorHandler(err error, c echo.Context) {
 		return
 	}
 
-	c.Logger().Error(err)
+	log := logger.FromContext(c.Request().Context())
+	if errorHttpStatus(err) >= 500 {
+		log.Error("request failed", logger.ErrorFields(err)...)
+	} else {
+		log.Info("request rejected", logger.ErrorFields(err)...)
+	}
+
+	if errors.Is(err, context.DeadlineExceeded) {
+		c.JSON(http.StatusServiceUnavailable, "request timed out")
+		return
+	}
+
+	if errors.Is(err, context.Canceled) {
+		// client has gone away, nobody will read the response
+		c.NoContent(499)
+		return
+	}
 
 	var appErr *bstask.Error
 	if errors.As(err, &appErr) {
@@ -57,3 +82,12 @@ func HttpErrorHandler(err error, c echo.Context) {
 		http.StatusText(http.StatusInternalServerError),
 	)
 }
+
+func errorHttpStatus(err error) int {
+	var echoError *echo.HTTPError
+	if errors.As(err, &echoError) {
+		return echoError.Code
+	}
+
+	return bstask.ErrCodeToHTTPStatus(err)
+}
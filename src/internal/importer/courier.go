@@ -0,0 +1,126 @@
+package importer
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"yandex-team.ru/bstask/internal/usecase/bulk"
+	"yandex-team.ru/bstask/internal/usecase/courier"
+)
+
+// NewCourierReader returns reader of couriers encoded in format.
+func NewCourierReader(format Format, src io.Reader) (courier.CourierRowReader, error) {
+	switch format {
+	case FORMAT_CSV:
+		r, err := newCSVReader(src, "courier_type", "regions", "working_hours")
+		if err != nil {
+			return nil, err
+		}
+		return courierCSVReader{r}, nil
+	case FORMAT_NDJSON:
+		return courierNDJSONReader{newNDJSONReader(src)}, nil
+	}
+
+	return nil, ErrUnsupportedFormat
+}
+
+type courierCSVReader struct {
+	*csvReader
+}
+
+func (r courierCSVReader) Next() (int, courier.CourierToCreateDTO, error) {
+	line, record, err := r.next()
+	if err != nil {
+		return line, courier.CourierToCreateDTO{}, err
+	}
+
+	regions, err := parseInt32List(r.field(record, "regions"))
+	if err != nil {
+		return line, courier.CourierToCreateDTO{}, &bulk.RowError{Line: line, Err: fmt.Errorf("regions: %w", err)}
+	}
+
+	maxDeliveries, err := parseOptionalUint32(r.optionalField(record, "max_deliveries_per_shift"))
+	if err != nil {
+		return line, courier.CourierToCreateDTO{}, &bulk.RowError{Line: line, Err: fmt.Errorf("max_deliveries_per_shift: %w", err)}
+	}
+
+	maxDriving, err := parseOptionalUint32(r.optionalField(record, "max_continuous_driving_minutes"))
+	if err != nil {
+		return line, courier.CourierToCreateDTO{}, &bulk.RowError{Line: line, Err: fmt.Errorf("max_continuous_driving_minutes: %w", err)}
+	}
+
+	shiftStores, err := parseShiftStores(r.optionalField(record, "shift_stores"))
+	if err != nil {
+		return line, courier.CourierToCreateDTO{}, &bulk.RowError{Line: line, Err: fmt.Errorf("shift_stores: %w", err)}
+	}
+
+	return line, courier.CourierToCreateDTO{
+		CourierType:                 r.field(record, "courier_type"),
+		Regions:                     regions,
+		WorkingHours:                splitList(r.field(record, "working_hours")),
+		Breaks:                      splitList(r.optionalField(record, "breaks")),
+		MaxDeliveriesPerShift:       maxDeliveries,
+		MaxContinuousDrivingMinutes: maxDriving,
+		Capabilities:                splitList(r.optionalField(record, "capabilities")),
+		ShiftStores:                 shiftStores,
+	}, nil
+}
+
+// parseShiftStores parses list of HH:MM-HH:MM=store_id pairs, nil for an empty string.
+func parseShiftStores(s string) (map[string]uint64, error) {
+	var res map[string]uint64
+	for _, v := range splitList(s) {
+		interval, store, ok := strings.Cut(v, "=")
+		if !ok {
+			return nil, errors.New("expected interval=store_id")
+		}
+
+		id, err := strconv.ParseUint(strings.TrimSpace(store), 10, 64)
+		if err != nil {
+			return nil, err
+		}
+
+		if res == nil {
+			res = make(map[string]uint64)
+		}
+		res[strings.TrimSpace(interval)] = id
+	}
+
+	return res, nil
+}
+
+type courierNDJSONReader struct {
+	*ndjsonReader
+}
+
+func (r courierNDJSONReader) Next() (int, courier.CourierToCreateDTO, error) {
+	var row struct {
+		CourierType                 string            `json:"courier_type"`
+		Regions                     []int32           `json:"regions"`
+		WorkingHours                []string          `json:"working_hours"`
+		Breaks                      []string          `json:"breaks"`
+		MaxDeliveriesPerShift       *uint32           `json:"max_deliveries_per_shift"`
+		MaxContinuousDrivingMinutes *uint32           `json:"max_continuous_driving_minutes"`
+		Capabilities                []string          `json:"capabilities"`
+		ShiftStores                 map[string]uint64 `json:"shift_stores"`
+	}
+
+	line, err := r.next(&row)
+	if err != nil {
+		return line, courier.CourierToCreateDTO{}, err
+	}
+
+	return line, courier.CourierToCreateDTO{
+		CourierType:                 row.CourierType,
+		Regions:                     row.Regions,
+		WorkingHours:                row.WorkingHours,
+		Breaks:                      row.Breaks,
+		MaxDeliveriesPerShift:       row.MaxDeliveriesPerShift,
+		MaxContinuousDrivingMinutes: row.MaxContinuousDrivingMinutes,
+		Capabilities:                row.Capabilities,
+		ShiftStores:                 row.ShiftStores,
+	}, nil
+}
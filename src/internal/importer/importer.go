@@ -0,0 +1,230 @@
+// Package importer decodes CSV and NDJSON streams into rows for bulk imports.
+//
+// CSV must start with a header, list values are separated by `;`:
+//
+//	courier_type,regions,working_hours
+//	FOOT,1;2,10:00-12:00;14:00-18:00
+//
+// NDJSON holds one object per line, in the shape of POST /couriers and POST /orders items.
+package importer
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"yandex-team.ru/bstask"
+	"yandex-team.ru/bstask/internal/usecase/bulk"
+)
+
+type Format string
+
+const (
+	FORMAT_CSV    Format = "text/csv"
+	FORMAT_NDJSON Format = "application/x-ndjson"
+)
+
+// Longest accepted NDJSON line.
+const MAX_LINE_SIZE = 1 << 20
+
+const LIST_SEPARATOR = ";"
+
+var ErrUnsupportedFormat = errors.New("unsupported import format")
+
+// FormatFromMediaType maps Content-Type to format.
+func FormatFromMediaType(mediaType string) (Format, error) {
+	switch mediaType {
+	case string(FORMAT_CSV):
+		return FORMAT_CSV, nil
+	case string(FORMAT_NDJSON), "application/jsonl", "application/jsonlines":
+		return FORMAT_NDJSON, nil
+	}
+
+	return "", ErrUnsupportedFormat
+}
+
+type csvReader struct {
+	r       *csv.Reader
+	columns map[string]int
+}
+
+// newCSVReader reads header and checks that all required columns are present.
+func newCSVReader(src io.Reader, required ...string) (*csvReader, error) {
+	r := csv.NewReader(src)
+	r.ReuseRecord = true
+	r.TrimLeadingSpace = true
+
+	header, err := r.Read()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, errors.New("csv header is missing")
+		}
+		return nil, err
+	}
+
+	columns := map[string]int{}
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	for _, name := range required {
+		if _, ok := columns[name]; !ok {
+			return nil, fmt.Errorf("csv column %q is missing", name)
+		}
+	}
+
+	return &csvReader{r: r, columns: columns}, nil
+}
+
+func (r *csvReader) next() (int, []string, error) {
+	record, err := r.r.Read()
+	if err != nil {
+		var parseErr *csv.ParseError
+		if errors.As(err, &parseErr) {
+			return parseErr.StartLine, nil, &bulk.RowError{Line: parseErr.StartLine, Err: parseErr.Err}
+		}
+		return 0, nil, err
+	}
+
+	line, _ := r.r.FieldPos(0)
+
+	return line, record, nil
+}
+
+func (r *csvReader) field(record []string, name string) string {
+	return strings.TrimSpace(record[r.columns[name]])
+}
+
+// optionalField returns empty string if the column is absent.
+func (r *csvReader) optionalField(record []string, name string) string {
+	if _, ok := r.columns[name]; !ok {
+		return ""
+	}
+
+	return r.field(record, name)
+}
+
+type ndjsonReader struct {
+	s    *bufio.Scanner
+	line int
+}
+
+func newNDJSONReader(src io.Reader) *ndjsonReader {
+	s := bufio.NewScanner(src)
+	s.Buffer(make([]byte, 64*1024), MAX_LINE_SIZE)
+
+	return &ndjsonReader{s: s}
+}
+
+// next decodes the next non-empty line into v.
+func (r *ndjsonReader) next(v interface{}) (int, error) {
+	for r.s.Scan() {
+		r.line++
+
+		b := r.s.Bytes()
+		if len(strings.TrimSpace(string(b))) == 0 {
+			continue
+		}
+
+		if err := json.Unmarshal(b, v); err != nil {
+			return r.line, &bulk.RowError{Line: r.line, Err: err}
+		}
+
+		return r.line, nil
+	}
+
+	if err := r.s.Err(); err != nil {
+		if errors.Is(err, bufio.ErrTooLong) {
+			return r.line, &bstask.Error{
+				Code:    bstask.EINVALID,
+				Err:     err,
+				Message: "line is too long",
+				Fields:  map[string]interface{}{"line": r.line + 1},
+			}
+		}
+		return r.line, err
+	}
+
+	return r.line, io.EOF
+}
+
+func splitList(s string) []string {
+	res := []string{}
+	for _, v := range strings.Split(s, LIST_SEPARATOR) {
+		v = strings.TrimSpace(v)
+		if v != "" {
+			res = append(res, v)
+		}
+	}
+
+	return res
+}
+
+func parseInt32(s string) (int32, error) {
+	v, err := strconv.ParseInt(s, 10, 32)
+	if err != nil {
+		return 0, err
+	}
+
+	return int32(v), nil
+}
+
+func parseInt32List(s string) ([]int32, error) {
+	res := []int32{}
+	for _, v := range splitList(s) {
+		i, err := parseInt32(v)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, i)
+	}
+
+	return res, nil
+}
+
+// parseOptionalUint32 returns nil for an empty string.
+func parseOptionalUint32(s string) (*uint32, error) {
+	if s == "" {
+		return nil, nil
+	}
+
+	v, err := strconv.ParseUint(s, 10, 32)
+	if err != nil {
+		return nil, err
+	}
+
+	res := uint32(v)
+	return &res, nil
+}
+
+// parseOptionalUint64 returns nil for an empty string.
+func parseOptionalUint64(s string) (*uint64, error) {
+	if s == "" {
+		return nil, nil
+	}
+
+	v, err := strconv.ParseUint(s, 10, 64)
+	if err != nil {
+		return nil, err
+	}
+
+	return &v, nil
+}
+
+// parseOptionalFloat returns nil for an empty string.
+func parseOptionalFloat(s string) (*float64, error) {
+	if s == "" {
+		return nil, nil
+	}
+
+	v, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return nil, err
+	}
+
+	return &v, nil
+}
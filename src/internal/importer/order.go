@@ -0,0 +1,233 @@
+package importer
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"yandex-team.ru/bstask/internal/entity"
+	"yandex-team.ru/bstask/internal/usecase/bulk"
+	"yandex-team.ru/bstask/internal/usecase/order"
+	"yandex-team.ru/bstask/pkg/geo"
+)
+
+// NewOrderReader returns reader of orders encoded in format. Item lines
+// are read from NDJSON only.
+func NewOrderReader(format Format, src io.Reader) (order.OrderRowReader, error) {
+	switch format {
+	case FORMAT_CSV:
+		r, err := newCSVReader(src, "weight", "regions", "delivery_hours", "cost")
+		if err != nil {
+			return nil, err
+		}
+		return orderCSVReader{r}, nil
+	case FORMAT_NDJSON:
+		return orderNDJSONReader{newNDJSONReader(src)}, nil
+	}
+
+	return nil, ErrUnsupportedFormat
+}
+
+type orderCSVReader struct {
+	*csvReader
+}
+
+func (r orderCSVReader) Next() (int, order.OrderToCreateDTO, error) {
+	line, record, err := r.next()
+	if err != nil {
+		return line, order.OrderToCreateDTO{}, err
+	}
+
+	weight, err := strconv.ParseFloat(r.field(record, "weight"), 64)
+	if err != nil {
+		return line, order.OrderToCreateDTO{}, &bulk.RowError{Line: line, Err: fmt.Errorf("weight: %w", err)}
+	}
+
+	regions, err := parseInt32(r.field(record, "regions"))
+	if err != nil {
+		return line, order.OrderToCreateDTO{}, &bulk.RowError{Line: line, Err: fmt.Errorf("regions: %w", err)}
+	}
+
+	cost, err := strconv.ParseUint(r.field(record, "cost"), 10, 31)
+	if err != nil {
+		return line, order.OrderToCreateDTO{}, &bulk.RowError{Line: line, Err: fmt.Errorf("cost: %w", err)}
+	}
+
+	location, err := parseLocation(r.optionalField(record, "lat"), r.optionalField(record, "lon"))
+	if err != nil {
+		return line, order.OrderToCreateDTO{}, &bulk.RowError{Line: line, Err: err}
+	}
+
+	deadline, err := parseDeadline(r.optionalField(record, "deadline"))
+	if err != nil {
+		return line, order.OrderToCreateDTO{}, &bulk.RowError{Line: line, Err: err}
+	}
+
+	volume, err := parseOptionalFloat(r.optionalField(record, "volume"))
+	if err != nil {
+		return line, order.OrderToCreateDTO{}, &bulk.RowError{Line: line, Err: fmt.Errorf("volume: %w", err)}
+	}
+
+	dimensions, err := parseDimensions(
+		r.optionalField(record, "length"),
+		r.optionalField(record, "width"),
+		r.optionalField(record, "height"),
+	)
+	if err != nil {
+		return line, order.OrderToCreateDTO{}, &bulk.RowError{Line: line, Err: err}
+	}
+
+	storeID, err := parseOptionalUint64(r.optionalField(record, "store_id"))
+	if err != nil {
+		return line, order.OrderToCreateDTO{}, &bulk.RowError{Line: line, Err: fmt.Errorf("store_id: %w", err)}
+	}
+
+	return line, order.OrderToCreateDTO{
+		Weight:        weight,
+		Regions:       regions,
+		DeliveryHours: splitList(r.field(record, "delivery_hours")),
+		Cost:          uint32(cost),
+		Location:      location,
+		Priority:      r.optionalField(record, "priority"),
+		Deadline:      deadline,
+		Volume:        volume,
+		Dimensions:    dimensions,
+		Handling:      splitList(r.optionalField(record, "handling")),
+		StoreID:       storeID,
+	}, nil
+}
+
+type orderNDJSONReader struct {
+	*ndjsonReader
+}
+
+func (r orderNDJSONReader) Next() (int, order.OrderToCreateDTO, error) {
+	var row struct {
+		Weight        float64    `json:"weight"`
+		Regions       int32      `json:"regions"`
+		DeliveryHours []string   `json:"delivery_hours"`
+		Cost          uint32     `json:"cost"`
+		Lat           *float64   `json:"lat"`
+		Lon           *float64   `json:"lon"`
+		Priority      string     `json:"priority"`
+		Deadline      *time.Time `json:"deadline"`
+		Volume        *float64   `json:"volume"`
+		Dimensions    *struct {
+			Length float64 `json:"length"`
+			Width  float64 `json:"width"`
+			Height float64 `json:"height"`
+		} `json:"dimensions"`
+		Items []struct {
+			SKU        string  `json:"sku"`
+			Quantity   uint32  `json:"quantity"`
+			UnitWeight float64 `json:"unit_weight"`
+		} `json:"items"`
+		Handling []string `json:"handling"`
+		StoreID  *uint64  `json:"store_id"`
+	}
+
+	line, err := r.next(&row)
+	if err != nil {
+		return line, order.OrderToCreateDTO{}, err
+	}
+
+	if (row.Lat == nil) != (row.Lon == nil) {
+		return line, order.OrderToCreateDTO{}, &bulk.RowError{Line: line, Err: errLocationPair}
+	}
+
+	var location *geo.Point
+	if row.Lat != nil {
+		location = &geo.Point{Lat: *row.Lat, Lon: *row.Lon}
+	}
+
+	var dimensions *entity.Dimensions
+	if d := row.Dimensions; d != nil {
+		dimensions = &entity.Dimensions{Length: d.Length, Width: d.Width, Height: d.Height}
+	}
+
+	items := []order.OrderItemToCreateDTO{}
+	for _, i := range row.Items {
+		items = append(items, order.OrderItemToCreateDTO{SKU: i.SKU, Quantity: i.Quantity, UnitWeight: i.UnitWeight})
+	}
+
+	return line, order.OrderToCreateDTO{
+		Weight:        row.Weight,
+		Regions:       row.Regions,
+		DeliveryHours: row.DeliveryHours,
+		Cost:          row.Cost,
+		Location:      location,
+		Priority:      row.Priority,
+		Deadline:      row.Deadline,
+		Volume:        row.Volume,
+		Dimensions:    dimensions,
+		Items:         items,
+		Handling:      row.Handling,
+		StoreID:       row.StoreID,
+	}, nil
+}
+
+var errLocationPair = errors.New("lat and lon must be set together")
+
+// parseLocation parses optional coordinates, both or none.
+func parseLocation(lat, lon string) (*geo.Point, error) {
+	if lat == "" && lon == "" {
+		return nil, nil
+	}
+	if lat == "" || lon == "" {
+		return nil, errLocationPair
+	}
+
+	la, err := strconv.ParseFloat(lat, 64)
+	if err != nil {
+		return nil, fmt.Errorf("lat: %w", err)
+	}
+	lo, err := strconv.ParseFloat(lon, 64)
+	if err != nil {
+		return nil, fmt.Errorf("lon: %w", err)
+	}
+
+	return &geo.Point{Lat: la, Lon: lo}, nil
+}
+
+// parseDeadline parses optional RFC 3339 deadline.
+func parseDeadline(s string) (*time.Time, error) {
+	if s == "" {
+		return nil, nil
+	}
+
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return nil, fmt.Errorf("deadline: %w", err)
+	}
+
+	return &t, nil
+}
+
+var errDimensionsTriple = errors.New("length, width and height must be set together")
+
+// parseDimensions parses optional dimensions, all or none.
+func parseDimensions(length, width, height string) (*entity.Dimensions, error) {
+	if length == "" && width == "" && height == "" {
+		return nil, nil
+	}
+	if length == "" || width == "" || height == "" {
+		return nil, errDimensionsTriple
+	}
+
+	l, err := strconv.ParseFloat(length, 64)
+	if err != nil {
+		return nil, fmt.Errorf("length: %w", err)
+	}
+	w, err := strconv.ParseFloat(width, 64)
+	if err != nil {
+		return nil, fmt.Errorf("width: %w", err)
+	}
+	h, err := strconv.ParseFloat(height, 64)
+	if err != nil {
+		return nil, fmt.Errorf("height: %w", err)
+	}
+
+	return &entity.Dimensions{Length: l, Width: w, Height: h}, nil
+}
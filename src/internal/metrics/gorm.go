@@ -0,0 +1,68 @@
+package metrics
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"gorm.io/gorm"
+	"yandex-team.ru/bstask/pkg/gorm/hooks"
+)
+
+const gormStartTimeKey = "metrics:start_time"
+
+// GormPlugin observes duration of every query made through gorm.
+type GormPlugin struct{}
+
+func (GormPlugin) Name() string {
+	return "metrics"
+}
+
+func (p GormPlugin) Initialize(db *gorm.DB) error {
+	return hooks.Around(db, p.Name(), p.before, p.after)
+}
+
+func (GormPlugin) before(string) func(*gorm.DB) {
+	return func(db *gorm.DB) {
+		db.InstanceSet(gormStartTimeKey, time.Now())
+	}
+}
+
+func (GormPlugin) after(operation string) func(*gorm.DB) {
+	return func(db *gorm.DB) {
+		v, ok := db.InstanceGet(gormStartTimeKey)
+		if !ok {
+			return
+		}
+
+		start, ok := v.(time.Time)
+		if !ok {
+			return
+		}
+
+		status := "ok"
+		if db.Error != nil && db.Error != gorm.ErrRecordNotFound {
+			status = "error"
+		}
+
+		table := db.Statement.Table
+		if table == "" {
+			table = "raw"
+		}
+
+		DBQueryDuration.WithLabelValues(operation, table, status).Observe(time.Since(start).Seconds())
+	}
+}
+
+// RegisterDB installs query duration plugin and exports connection pool stats.
+func RegisterDB(db *gorm.DB) error {
+	if err := db.Use(GormPlugin{}); err != nil {
+		return err
+	}
+
+	sqlDB, err := db.DB()
+	if err != nil {
+		return err
+	}
+
+	return Registry.Register(collectors.NewDBStatsCollector(sqlDB, "postgres"))
+}
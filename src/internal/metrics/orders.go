@@ -0,0 +1,44 @@
+package metrics
+
+import (
+	"context"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const scrapeTimeout = 2 * time.Second
+
+// unassignedOrdersCollector counts orders without delivery group on every scrape.
+type unassignedOrdersCollector struct {
+	desc  *prometheus.Desc
+	count func(ctx context.Context) (uint64, error)
+}
+
+func (c *unassignedOrdersCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.desc
+}
+
+func (c *unassignedOrdersCollector) Collect(ch chan<- prometheus.Metric) {
+	ctx, cancel := context.WithTimeout(context.Background(), scrapeTimeout)
+	defer cancel()
+
+	cnt, err := c.count(ctx)
+	if err != nil {
+		ch <- prometheus.NewInvalidMetric(c.desc, err)
+		return
+	}
+
+	ch <- prometheus.MustNewConstMetric(c.desc, prometheus.GaugeValue, float64(cnt))
+}
+
+func RegisterUnassignedOrders(count func(ctx context.Context) (uint64, error)) error {
+	return Registry.Register(&unassignedOrdersCollector{
+		desc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "orders", "unassigned"),
+			"Orders not assigned to any delivery group.",
+			nil, nil,
+		),
+		count: count,
+	})
+}
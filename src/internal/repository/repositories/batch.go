@@ -0,0 +1,8 @@
+package repositories
+
+// Rows per multi-row INSERT. Postgres accepts at most 65535 bind
+// parameters per statement, so it must stay below 65535 / columns.
+const INSERT_BATCH_SIZE = 1000
+
+// Rows fetched from server-side cursor per round trip.
+const EXPORT_FETCH_SIZE = 1000
@@ -0,0 +1,38 @@
+package repositories
+
+import (
+	"fmt"
+	"time"
+)
+
+// Working and delivery hours are stored as `time` columns; an interval whose
+// end is not after its start crosses midnight, e.g. 22:00-02:00. Comparisons
+// are done on the clock face in seconds since the interval start.
+
+// clockSince is SQL for seconds on the clock from `from` forward to `to`,
+// wrapping over midnight: 22:00 to 02:00 is 4 hours.
+func clockSince(from, to string) string {
+	return fmt.Sprintf(
+		`mod((EXTRACT(EPOCH FROM %s) - EXTRACT(EPOCH FROM %s))::numeric + 86400, 86400)`,
+		to,
+		from,
+	)
+}
+
+// clockContains is SQL true if clock `t` is within [start, end], or
+// [start, end) if endExclusive.
+func clockContains(start, end, t string, endExclusive bool) string {
+	op := "<="
+	if endExclusive {
+		op = "<"
+	}
+
+	return fmt.Sprintf("%s %s %s", clockSince(start, t), op, clockSince(start, end))
+}
+
+// clockParam is SQL placeholder for clock time of t.
+const clockParam = "?::time"
+
+func clock(t time.Time) string {
+	return t.Format("15:04:05")
+}
@@ -0,0 +1,101 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	trmgorm "github.com/avito-tech/go-transaction-manager/gorm"
+	"gorm.io/gorm"
+	"yandex-team.ru/bstask/internal/entity"
+	"yandex-team.ru/bstask/pkg/geo"
+)
+
+// @migration
+type CourierStatus struct {
+	ID         uint64 `gorm:"primaryKey"`
+	CourierID  uint64
+	Courier    *Courier `gorm:"foreignKey:CourierID"`
+	Status     string
+	ReportedAt time.Time
+}
+
+// @migration
+type CourierLocation struct {
+	ID         uint64 `gorm:"primaryKey"`
+	CourierID  uint64
+	Courier    *Courier `gorm:"foreignKey:CourierID"`
+	Lat        float64
+	Lon        float64
+	ReportedAt time.Time
+}
+
+// CourierLiveRepo keeps history of statuses and locations reported by couriers.
+type CourierLiveRepo struct {
+	gorm      *gorm.DB
+	ctxGetter *trmgorm.CtxGetter
+}
+
+func NewCourierLiveRepo(grm *gorm.DB, c *trmgorm.CtxGetter) *CourierLiveRepo {
+	return &CourierLiveRepo{
+		gorm:      grm,
+		ctxGetter: c,
+	}
+}
+
+func (s *CourierLiveRepo) AddStatus(ctx context.Context, courierID uint64, status entity.CourierStatus, at time.Time) error {
+
+	db := s.ctxGetter.DefaultTrOrDB(ctx, s.gorm).WithContext(ctx)
+
+	return db.Omit("Courier").Create(&CourierStatus{
+		CourierID:  courierID,
+		Status:     string(status),
+		ReportedAt: at,
+	}).Error
+}
+
+func (s *CourierLiveRepo) AddLocation(ctx context.Context, courierID uint64, location geo.Point, at time.Time) error {
+
+	db := s.ctxGetter.DefaultTrOrDB(ctx, s.gorm).WithContext(ctx)
+
+	return db.Omit("Courier").Create(&CourierLocation{
+		CourierID:  courierID,
+		Lat:        location.Lat,
+		Lon:        location.Lon,
+		ReportedAt: at,
+	}).Error
+}
+
+// Latest returns the state reported last by time of observation, updates
+// delivered out of order don't override newer ones.
+func (s *CourierLiveRepo) Latest(ctx context.Context, courierID uint64) (*entity.CourierLive, error) {
+
+	res := entity.CourierLive{
+		CourierID: courierID,
+		Status:    entity.DEFAULT_COURIER_STATUS,
+	}
+
+	db := s.ctxGetter.DefaultTrOrDB(ctx, s.gorm).WithContext(ctx)
+
+	var status CourierStatus
+	err := db.Where("courier_id = ?", courierID).Order("reported_at DESC, id DESC").First(&status).Error
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+	if err == nil {
+		res.Status = entity.CourierStatus(status.Status)
+		res.StatusTime = &status.ReportedAt
+	}
+
+	var location CourierLocation
+	err = db.Where("courier_id = ?", courierID).Order("reported_at DESC, id DESC").First(&location).Error
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+	if err == nil {
+		res.Location = &geo.Point{Lat: location.Lat, Lon: location.Lon}
+		res.LocationTime = &location.ReportedAt
+	}
+
+	return &res, nil
+}
@@ -4,13 +4,16 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"strings"
 	"time"
 
 	trmgorm "github.com/avito-tech/go-transaction-manager/gorm"
 	"github.com/lib/pq"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 	"yandex-team.ru/bstask"
 	"yandex-team.ru/bstask/internal/entity"
+	"yandex-team.ru/bstask/pkg/db/postgresql"
 	"yandex-team.ru/bstask/pkg/gorm/types"
 )
 
@@ -20,6 +23,11 @@ type Courier struct {
 	CourierType  string
 	Regions      pq.Int32Array         `gorm:"type:integer[]"`
 	WorkingHours []CourierWorkingHours `gorm:"foreignKey:CourierID;references:ID"`
+	Breaks       []CourierBreak        `gorm:"foreignKey:CourierID;references:ID"`
+	// shift limits, nil for no limit
+	MaxDeliveriesPerShift       *uint32
+	MaxContinuousDrivingMinutes *uint32
+	Capabilities                pq.StringArray `gorm:"type:text[]"`
 }
 
 // @migration
@@ -29,6 +37,17 @@ type CourierWorkingHours struct {
 	Courier   *Courier `gorm:"foreignKey:CourierID"`
 	StartTime types.Time
 	EndTime   types.Time
+	// store the shift starts at, nil for the region depot
+	StoreID *uint64
+}
+
+// @migration
+type CourierBreak struct {
+	ID        uint64 `gorm:"primaryKey"`
+	CourierID uint64
+	Courier   *Courier `gorm:"foreignKey:CourierID"`
+	StartTime types.Time
+	EndTime   types.Time
 }
 
 type CourierRepo struct {
@@ -44,74 +63,160 @@ func NewCourierRepo(grm *gorm.DB, c *trmgorm.CtxGetter) *CourierRepo {
 }
 
 type CourierToCreateDTO struct {
-	CourierType  string
-	Regions      []int32
-	WorkingHours []CourierWorkingHoursIntervalDTO
+	CourierType                 string
+	Regions                     []int32
+	WorkingHours                []CourierWorkingHoursIntervalDTO
+	Breaks                      []CourierWorkingHoursIntervalDTO
+	MaxDeliveriesPerShift       *uint32
+	MaxContinuousDrivingMinutes *uint32
+	Capabilities                []entity.Capability
 }
 
 type CourierWorkingHoursIntervalDTO struct {
 	StartTime time.Time
 	EndTime   time.Time
+	// Only for working hours, store the shift starts at.
+	StoreID *uint64
 }
 
 func toCourierEntity(c Courier) entity.Courier {
 
 	wh := []string{}
+	var stores map[string]uint64
 	for _, t := range c.WorkingHours {
 		st := time.Time(t.StartTime).Format("15:04")
 		et := time.Time(t.EndTime).Format("15:04")
 
 		wh = append(wh, st+"-"+et)
+
+		if t.StoreID != nil {
+			if stores == nil {
+				stores = make(map[string]uint64)
+			}
+			stores[st+"-"+et] = *t.StoreID
+		}
+	}
+
+	breaks := []string{}
+	for _, b := range c.Breaks {
+		breaks = append(breaks, time.Time(b.StartTime).Format("15:04")+"-"+time.Time(b.EndTime).Format("15:04"))
 	}
 
 	return entity.Courier{
-		ID:           c.ID,
-		CourierType:  entity.CourierType(c.CourierType),
-		Regions:      c.Regions,
-		WorkingHours: wh,
+		ID:                    c.ID,
+		CourierType:           entity.CourierType(c.CourierType),
+		Regions:               c.Regions,
+		WorkingHours:          wh,
+		Breaks:                breaks,
+		MaxDeliveriesPerShift: c.MaxDeliveriesPerShift,
+		MaxContinuousDriving:  minutes(c.MaxContinuousDrivingMinutes),
+		Capabilities:          toCapabilities(c.Capabilities),
+		ShiftStores:           stores,
+	}
+}
+
+func toCapabilities(a pq.StringArray) []entity.Capability {
+	res := []entity.Capability{}
+	for _, c := range a {
+		res = append(res, entity.Capability(c))
+	}
+
+	return res
+}
+
+func fromCapabilities(capabilities []entity.Capability) pq.StringArray {
+	res := pq.StringArray{}
+	for _, c := range capabilities {
+		res = append(res, string(c))
+	}
+
+	return res
+}
+
+func minutes(m *uint32) *time.Duration {
+	if m == nil {
+		return nil
 	}
+
+	d := time.Duration(*m) * time.Minute
+	return &d
 }
 
+// BatchCreate inserts couriers and their working hours with multi-row
+// INSERTs, INSERT_BATCH_SIZE rows per statement.
 func (s *CourierRepo) BatchCreate(ctx context.Context, newCouriers []CourierToCreateDTO) (*[]entity.Courier, error) {
 
-	couriers := []Courier{}
+	res := []entity.Courier{}
+	if len(newCouriers) == 0 {
+		return &res, nil
+	}
 
 	db := s.ctxGetter.DefaultTrOrDB(ctx, s.gorm).WithContext(ctx)
 
+	couriers := make([]Courier, 0, len(newCouriers))
 	for _, c := range newCouriers {
-
-		// BUG: if we assign `working hours` to courier here,
-		// we will deal with duplicate INSERT queries
-
 		couriers = append(couriers, Courier{
-			CourierType: c.CourierType,
-			Regions:     c.Regions,
+			CourierType:                 c.CourierType,
+			Regions:                     c.Regions,
+			MaxDeliveriesPerShift:       c.MaxDeliveriesPerShift,
+			MaxContinuousDrivingMinutes: c.MaxContinuousDrivingMinutes,
+			Capabilities:                fromCapabilities(c.Capabilities),
 		})
 	}
 
-	dbRes := db.CreateInBatches(couriers, 20)
-	if dbRes.Error != nil {
-		return nil, dbRes.Error
+	err := db.Omit(clause.Associations).CreateInBatches(&couriers, INSERT_BATCH_SIZE).Error
+	if err != nil {
+		return nil, err
 	}
 
+	hours := []CourierWorkingHours{}
 	for i, c := range newCouriers {
-		courier := &couriers[i]
-
 		for _, wh := range c.WorkingHours {
-			(*courier).WorkingHours = append(courier.WorkingHours, CourierWorkingHours{
-				Courier:   courier,
+			hours = append(hours, CourierWorkingHours{
+				CourierID: couriers[i].ID,
 				StartTime: types.NewTime(wh.StartTime.Hour(), wh.StartTime.Minute(), wh.StartTime.Second()),
 				EndTime:   types.NewTime(wh.EndTime.Hour(), wh.EndTime.Minute(), wh.EndTime.Second()),
+				StoreID:   wh.StoreID,
 			})
 		}
+	}
 
-		dbRes = db.Save(courier)
-		if dbRes.Error != nil {
-			return nil, dbRes.Error
+	if len(hours) > 0 {
+		err = db.Omit(clause.Associations).CreateInBatches(&hours, INSERT_BATCH_SIZE).Error
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	breaks := []CourierBreak{}
+	for i, c := range newCouriers {
+		for _, b := range c.Breaks {
+			breaks = append(breaks, CourierBreak{
+				CourierID: couriers[i].ID,
+				StartTime: types.NewTime(b.StartTime.Hour(), b.StartTime.Minute(), b.StartTime.Second()),
+				EndTime:   types.NewTime(b.EndTime.Hour(), b.EndTime.Minute(), b.EndTime.Second()),
+			})
+		}
+	}
+
+	if len(breaks) > 0 {
+		err = db.Omit(clause.Associations).CreateInBatches(&breaks, INSERT_BATCH_SIZE).Error
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// hours and breaks are in the same order as couriers
+	j, k := 0, 0
+	for i := range couriers {
+		for ; j < len(hours) && hours[j].CourierID == couriers[i].ID; j++ {
+			couriers[i].WorkingHours = append(couriers[i].WorkingHours, hours[j])
+		}
+		for ; k < len(breaks) && breaks[k].CourierID == couriers[i].ID; k++ {
+			couriers[i].Breaks = append(couriers[i].Breaks, breaks[k])
 		}
 	}
 
-	res := []entity.Courier{}
 	for _, c := range couriers {
 		res = append(res, toCourierEntity(c))
 	}
@@ -124,7 +229,7 @@ func (s *CourierRepo) FindById(ctx context.Context, id uint64) (*entity.Courier,
 	var courier Courier
 
 	db := s.ctxGetter.DefaultTrOrDB(ctx, s.gorm).WithContext(ctx)
-	err := db.Model(&Courier{}).Preload("WorkingHours").Where("id = ?", id).First(&courier).Error
+	err := db.Model(&Courier{}).Preload("WorkingHours").Preload("Breaks").Where("id = ?", id).First(&courier).Error
 
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
@@ -147,12 +252,68 @@ func (s *CourierRepo) FindById(ctx context.Context, id uint64) (*entity.Courier,
 	return &entity, nil
 }
 
-func (s *CourierRepo) PaginatedFetchAll(ctx context.Context, offset, limit int32) (*[]entity.Courier, error) {
+// FindByIds returns couriers with given IDs ordered by ID, unknown IDs are skipped.
+func (s *CourierRepo) FindByIds(ctx context.Context, ids []uint64) (*[]entity.Courier, error) {
+
+	res := []entity.Courier{}
+	if len(ids) == 0 {
+		return &res, nil
+	}
 
 	couriers := []Courier{}
 
 	db := s.ctxGetter.DefaultTrOrDB(ctx, s.gorm).WithContext(ctx)
-	err := db.Model(&Courier{}).Preload("WorkingHours").Limit(int(limit)).Offset(int(offset)).Find(&couriers).Error
+	err := db.Model(&Courier{}).Preload("WorkingHours").Preload("Breaks").Where("id IN ?", ids).Order("id").Find(&couriers).Error
+	if err != nil {
+		return nil, err
+	}
+
+	for _, c := range couriers {
+		res = append(res, toCourierEntity(c))
+	}
+
+	return &res, nil
+}
+
+func (s *CourierRepo) PaginatedFetchAll(ctx context.Context, filter CourierFilter, offset, limit int32) (*[]entity.Courier, error) {
+
+	couriers := []Courier{}
+
+	db := s.ctxGetter.DefaultTrOrDB(ctx, s.gorm).WithContext(ctx)
+	err := db.Model(&Courier{}).
+		Preload("WorkingHours").
+		Preload("Breaks").
+		Scopes(filter.Scopes()...).
+		Order("id").
+		Limit(int(limit)).
+		Offset(int(offset)).
+		Find(&couriers).Error
+	if err != nil {
+		return nil, err
+	}
+
+	res := []entity.Courier{}
+	for _, c := range couriers {
+		res = append(res, toCourierEntity(c))
+	}
+
+	return &res, nil
+}
+
+// FetchAfter returns up to limit couriers with ID greater than afterID ordered by ID.
+func (s *CourierRepo) FetchAfter(ctx context.Context, filter CourierFilter, afterID uint64, limit int) (*[]entity.Courier, error) {
+
+	couriers := []Courier{}
+
+	db := s.ctxGetter.DefaultTrOrDB(ctx, s.gorm).WithContext(ctx)
+	err := db.Model(&Courier{}).
+		Preload("WorkingHours").
+		Preload("Breaks").
+		Scopes(filter.Scopes()...).
+		Where(`"couriers"."id" > ?`, afterID).
+		Order("id").
+		Limit(limit).
+		Find(&couriers).Error
 	if err != nil {
 		return nil, err
 	}
@@ -165,14 +326,52 @@ func (s *CourierRepo) PaginatedFetchAll(ctx context.Context, offset, limit int32
 	return &res, nil
 }
 
+func (s *CourierRepo) Count(ctx context.Context, filter CourierFilter) (int64, error) {
+
+	var count int64
+
+	db := s.ctxGetter.DefaultTrOrDB(ctx, s.gorm).WithContext(ctx)
+	err := db.Model(&Courier{}).Scopes(filter.Scopes()...).Count(&count).Error
+	if err != nil {
+		return 0, err
+	}
+
+	return count, nil
+}
+
+// DistinctRegionSets returns distinct `regions` of couriers matching filter.
+func (s *CourierRepo) DistinctRegionSets(ctx context.Context, filter CourierFilter) ([][]int32, error) {
+
+	var sets []pq.Int32Array
+
+	db := s.ctxGetter.DefaultTrOrDB(ctx, s.gorm).WithContext(ctx)
+	err := db.Model(&Courier{}).
+		Scopes(filter.Scopes()...).
+		Distinct(`"couriers"."regions"`).
+		Pluck(`"couriers"."regions"`, &sets).Error
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([][]int32, 0, len(sets))
+	for _, set := range sets {
+		res = append(res, set)
+	}
+
+	return res, nil
+}
+
 func (s *CourierRepo) WorkingIntervalForDelivery(ctx context.Context, courierID uint64, start, end time.Time) (*CourierWorkingHours, error) {
 	var wh *CourierWorkingHours
 
+	// start..end fits into the interval, which may cross midnight
 	db := s.ctxGetter.DefaultTrOrDB(ctx, s.gorm).WithContext(ctx)
 	err := db.Where(
-		"start_time <= ? AND end_time >= ? AND courier_id = ?",
-		start.Format("15:04:05"),
-		end.Format("15:04:05"),
+		clockSince("start_time", clockParam)+" + "+clockSince(clockParam, clockParam)+
+			" <= "+clockSince("start_time", "end_time")+" AND courier_id = ?",
+		clock(start),
+		clock(end),
+		clock(start),
 		courierID,
 	).First(&wh).Error
 
@@ -190,17 +389,27 @@ type AllWorkingHoursRes struct {
 	WorkingHoursID uint64
 	StartTime      time.Time
 	EndTime        time.Time
+	// breaks of the courier in any of its working intervals
+	Breaks                []CourierWorkingHoursIntervalDTO
+	MaxDeliveriesPerShift *uint32
+	MaxContinuousDriving  *time.Duration
+	Capabilities          []entity.Capability
+	StoreID               *uint64
 }
 
 func (s *CourierRepo) AllWorkingHoursByCourierType(ctx context.Context, courierType entity.CourierType) (*[]AllWorkingHoursRes, error) {
 
 	tmp := []struct {
-		CourierID      uint64        `gorm:"column:courier_id"`
-		CourierType    string        `gorm:"column:courier_type"`
-		Regions        pq.Int32Array `gorm:"column:regions"`
-		WorkingHoursID uint64        `gorm:"column:working_hours_id"`
-		StartTime      types.Time    `gorm:"column:start_time"`
-		EndTime        types.Time    `gorm:"column:end_time"`
+		CourierID      uint64         `gorm:"column:courier_id"`
+		CourierType    string         `gorm:"column:courier_type"`
+		Regions        pq.Int32Array  `gorm:"column:regions"`
+		WorkingHoursID uint64         `gorm:"column:working_hours_id"`
+		StartTime      types.Time     `gorm:"column:start_time"`
+		EndTime        types.Time     `gorm:"column:end_time"`
+		MaxDeliveries  *uint32        `gorm:"column:max_deliveries_per_shift"`
+		MaxDriving     *uint32        `gorm:"column:max_continuous_driving_minutes"`
+		Capabilities   pq.StringArray `gorm:"column:capabilities"`
+		StoreID        *uint64        `gorm:"column:store_id"`
 	}{}
 
 	db := s.ctxGetter.DefaultTrOrDB(ctx, s.gorm).WithContext(ctx)
@@ -211,7 +420,11 @@ func (s *CourierRepo) AllWorkingHoursByCourierType(ctx context.Context, courierT
 			"c"."regions" as "regions",
 			"cwh"."id" as "working_hours_id",
 			"cwh"."start_time" as "start_time",
-			"cwh"."end_time" as "end_time"
+			"cwh"."end_time" as "end_time",
+			"c"."max_deliveries_per_shift",
+			"c"."max_continuous_driving_minutes",
+			"c"."capabilities",
+			"cwh"."store_id"
 		FROM "couriers" as "c"
 		LEFT JOIN "courier_working_hours" as cwh 
 			ON "cwh"."courier_id" = "c"."id"
@@ -227,17 +440,99 @@ func (s *CourierRepo) AllWorkingHoursByCourierType(ctx context.Context, courierT
 		return nil, err
 	}
 
+	courierIDs := []uint64{}
+	for _, d := range tmp {
+		courierIDs = append(courierIDs, d.CourierID)
+	}
+
+	breaks := []CourierBreak{}
+	if len(courierIDs) > 0 {
+		err = db.Where("courier_id IN ?", courierIDs).Order("start_time").Find(&breaks).Error
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	courierBreaks := make(map[uint64][]CourierWorkingHoursIntervalDTO)
+	for _, b := range breaks {
+		courierBreaks[b.CourierID] = append(courierBreaks[b.CourierID], CourierWorkingHoursIntervalDTO{
+			StartTime: time.Time(b.StartTime),
+			EndTime:   time.Time(b.EndTime),
+		})
+	}
+
 	res := []AllWorkingHoursRes{}
 	for _, d := range tmp {
 		res = append(res, AllWorkingHoursRes{
-			CourierID:      d.CourierID,
-			CourierType:    d.CourierType,
-			Regions:        d.Regions,
-			WorkingHoursID: d.WorkingHoursID,
-			StartTime:      time.Time(d.StartTime),
-			EndTime:        time.Time(d.EndTime),
+			CourierID:             d.CourierID,
+			CourierType:           d.CourierType,
+			Regions:               d.Regions,
+			WorkingHoursID:        d.WorkingHoursID,
+			StartTime:             time.Time(d.StartTime),
+			EndTime:               time.Time(d.EndTime),
+			Breaks:                courierBreaks[d.CourierID],
+			MaxDeliveriesPerShift: d.MaxDeliveries,
+			MaxContinuousDriving:  minutes(d.MaxDriving),
+			Capabilities:          toCapabilities(d.Capabilities),
+			StoreID:               d.StoreID,
 		})
 	}
 
 	return &res, nil
 }
+
+type CourierExportFilter struct {
+	// Zero values mean any.
+	Region      int32
+	CourierType string
+	CourierID   uint64
+}
+
+type CourierExportDTO struct {
+	ID           uint64
+	CourierType  string
+	Regions      pq.Int32Array
+	WorkingHours string
+}
+
+// Export streams couriers matching filter ordered by ID.
+// Must be called inside a transaction.
+func (s *CourierRepo) Export(ctx context.Context, filter CourierExportFilter, fn func(CourierExportDTO) error) error {
+
+	conds := []string{"TRUE"}
+	args := []interface{}{}
+
+	if filter.Region != 0 {
+		conds = append(conds, `c."regions" @> ARRAY[?]::integer[]`)
+		args = append(args, filter.Region)
+	}
+	if filter.CourierType != "" {
+		conds = append(conds, `c."courier_type" = ?`)
+		args = append(args, filter.CourierType)
+	}
+	if filter.CourierID != 0 {
+		conds = append(conds, `c."id" = ?`)
+		args = append(args, filter.CourierID)
+	}
+
+	query := `
+		SELECT
+			c."id", c."courier_type", c."regions",
+			COALESCE((
+				SELECT string_agg(to_char(h."start_time", 'HH24:MI') || '-' || to_char(h."end_time", 'HH24:MI'), ';' ORDER BY h."id")
+				FROM "courier_working_hours" as h WHERE h."courier_id" = c."id"
+			), '') as "working_hours"
+		FROM "couriers" as c
+		WHERE ` + strings.Join(conds, " AND ") + `
+		ORDER BY c."id"`
+
+	db := s.ctxGetter.DefaultTrOrDB(ctx, s.gorm).WithContext(ctx)
+
+	return postgresql.Cursor(db, "export_couriers", EXPORT_FETCH_SIZE, query, args, func(db *gorm.DB, rows *sql.Rows) error {
+		var row CourierExportDTO
+		if err := db.ScanRows(rows, &row); err != nil {
+			return err
+		}
+		return fn(row)
+	})
+}
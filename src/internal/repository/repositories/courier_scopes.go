@@ -0,0 +1,80 @@
+package repositories
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// CourierFilter narrows courier listings. Nil fields mean any.
+type CourierFilter struct {
+	CourierType *string
+	// Matched against `regions` array, served by GIN index.
+	Region *int32
+	// Some working interval contains the moment.
+	AvailableAt *time.Time
+	// Some working interval covers the whole window.
+	AvailableBetween *TimeWindow
+}
+
+// Scopes returns filter conditions as gorm scopes.
+func (f CourierFilter) Scopes() []func(*gorm.DB) *gorm.DB {
+	scopes := []func(*gorm.DB) *gorm.DB{}
+
+	if f.CourierType != nil {
+		scopes = append(scopes, CourierOfType(*f.CourierType))
+	}
+	if f.Region != nil {
+		scopes = append(scopes, CourierInRegion(*f.Region))
+	}
+	if f.AvailableAt != nil {
+		scopes = append(scopes, CourierAvailableAt(*f.AvailableAt))
+	}
+	if f.AvailableBetween != nil {
+		scopes = append(scopes, CourierAvailableBetween(*f.AvailableBetween))
+	}
+
+	return scopes
+}
+
+func CourierOfType(courierType string) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Where(`"couriers"."courier_type" = ?`, courierType)
+	}
+}
+
+func CourierInRegion(region int32) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		// `@>` instead of `= ANY()`, so the GIN index is used
+		return db.Where(`"couriers"."regions" @> ARRAY[?]::integer[]`, region)
+	}
+}
+
+func CourierAvailableAt(t time.Time) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Where(
+			`EXISTS (
+				SELECT 1 FROM "courier_working_hours" as wh
+				WHERE wh."courier_id" = "couriers"."id" AND `+
+				clockContains(`wh."start_time"`, `wh."end_time"`, clockParam, true)+`
+			)`,
+			clock(t),
+		)
+	}
+}
+
+func CourierAvailableBetween(w TimeWindow) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Where(
+			`EXISTS (
+				SELECT 1 FROM "courier_working_hours" as wh
+				WHERE wh."courier_id" = "couriers"."id" AND `+
+				clockSince(`wh."start_time"`, clockParam)+" + "+clockSince(clockParam, clockParam)+
+				" <= "+clockSince(`wh."start_time"`, `wh."end_time"`)+`
+			)`,
+			clock(w.Start),
+			clock(w.End),
+			clock(w.Start),
+		)
+	}
+}
@@ -2,11 +2,14 @@ package repositories
 
 import (
 	"context"
+	"database/sql"
+	"strings"
 	"time"
 
 	trmgorm "github.com/avito-tech/go-transaction-manager/gorm"
 	"gorm.io/gorm"
 	"yandex-team.ru/bstask/internal/entity"
+	"yandex-team.ru/bstask/pkg/db/postgresql"
 	"yandex-team.ru/bstask/pkg/gorm/types"
 )
 
@@ -133,3 +136,60 @@ func (s *DeliveryGroupRepo) AllByDateAndIds(ctx context.Context, courierIDs []ui
 // func (s *DeliveryGroupRepo) ByCourierIdInInterval(ctx context.Context courier) (*entity.DeliveryGroup, error) {
 
 // }
+
+type AssignmentExportFilter struct {
+	// Inclusive range of assign date.
+	From time.Time
+	To   time.Time
+	// Zero values mean any.
+	Region    int32
+	CourierID uint64
+}
+
+type AssignmentExportDTO struct {
+	AssignDate      time.Time
+	CourierID       uint64
+	DeliveryGroupID uint64
+	StartDateTime   time.Time
+	EndDateTime     time.Time
+	OrderID         uint64
+	Regions         int32
+	CompletedTime   *time.Time
+}
+
+// ExportAssignments streams assigned orders, one row per order,
+// ordered by date, courier and group. Must be called inside a transaction.
+func (s *DeliveryGroupRepo) ExportAssignments(ctx context.Context, filter AssignmentExportFilter, fn func(AssignmentExportDTO) error) error {
+
+	conds := []string{`dg."assign_date" >= ?`, `dg."assign_date" <= ?`}
+	args := []interface{}{filter.From.Format("2006-01-02"), filter.To.Format("2006-01-02")}
+
+	if filter.Region != 0 {
+		conds = append(conds, `o."regions" = ?`)
+		args = append(args, filter.Region)
+	}
+	if filter.CourierID != 0 {
+		conds = append(conds, `dg."courier_id" = ?`)
+		args = append(args, filter.CourierID)
+	}
+
+	query := `
+		SELECT
+			dg."assign_date", dg."courier_id", dg."id" as "delivery_group_id",
+			dg."start_date_time", dg."end_date_time",
+			o."id" as "order_id", o."regions", o."completed_time"
+		FROM "delivery_groups" as dg
+		INNER JOIN "orders" as o ON o."delivery_group_id" = dg."id"
+		WHERE ` + strings.Join(conds, " AND ") + `
+		ORDER BY dg."assign_date", dg."courier_id", dg."id", o."id"`
+
+	db := s.ctxGetter.DefaultTrOrDB(ctx, s.gorm).WithContext(ctx)
+
+	return postgresql.Cursor(db, "export_assignments", EXPORT_FETCH_SIZE, query, args, func(db *gorm.DB, rows *sql.Rows) error {
+		var row AssignmentExportDTO
+		if err := db.ScanRows(rows, &row); err != nil {
+			return err
+		}
+		return fn(row)
+	})
+}
@@ -0,0 +1,21 @@
+package repositories
+
+import "yandex-team.ru/bstask/internal/entity"
+
+// Dimensions are stored in triples of nullable columns.
+
+func toDimensions(length, width, height *float64) *entity.Dimensions {
+	if length == nil || width == nil || height == nil {
+		return nil
+	}
+
+	return &entity.Dimensions{Length: *length, Width: *width, Height: *height}
+}
+
+func fromDimensions(d *entity.Dimensions) (length, width, height *float64) {
+	if d == nil {
+		return nil, nil, nil
+	}
+
+	return &d.Length, &d.Width, &d.Height
+}
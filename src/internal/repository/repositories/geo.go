@@ -0,0 +1,21 @@
+package repositories
+
+import "yandex-team.ru/bstask/pkg/geo"
+
+// Coordinates are stored in pairs of nullable columns.
+
+func toPoint(lat, lon *float64) *geo.Point {
+	if lat == nil || lon == nil {
+		return nil
+	}
+
+	return &geo.Point{Lat: *lat, Lon: *lon}
+}
+
+func fromPoint(p *geo.Point) (lat, lon *float64) {
+	if p == nil {
+		return nil, nil
+	}
+
+	return &p.Lat, &p.Lon
+}
@@ -2,15 +2,20 @@ package repositories
 
 import (
 	"context"
+	"database/sql"
 	"errors"
 	"fmt"
+	"strings"
 	"time"
 
 	trmgorm "github.com/avito-tech/go-transaction-manager/gorm"
 	"github.com/lib/pq"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 	"yandex-team.ru/bstask"
 	"yandex-team.ru/bstask/internal/entity"
+	"yandex-team.ru/bstask/pkg/db/postgresql"
+	"yandex-team.ru/bstask/pkg/geo"
 	"yandex-team.ru/bstask/pkg/gorm/types"
 )
 
@@ -24,6 +29,36 @@ type Order struct {
 	CompletedTime   *time.Time
 	DeliveryGroupID *uint64
 	DeliveryGroup   *DeliveryGroup `gorm:"foreignKey:DeliveryGroupID"`
+	Lat             *float64
+	Lon             *float64
+	// confirmed by the courier, completed_time of assigned orders is planned
+	DeliveredTime *time.Time
+	// 1-based stop number within the delivery group
+	DeliverySequence *int32
+	ETA              *time.Time `gorm:"column:eta"`
+	// chosen row of DeliveryHours
+	DeliveryWindowID *uint64 `gorm:"column:delivery_hours_id"`
+	Priority         string
+	Deadline         *time.Time
+	// litres and centimetres
+	Volume   *float64
+	Length   *float64
+	Width    *float64
+	Height   *float64
+	Items    []OrderItem    `gorm:"foreignKey:OrderID;references:ID"`
+	Handling pq.StringArray `gorm:"type:text[]"`
+	// pickup store, nil for the region depot
+	StoreID *uint64
+}
+
+// @migration
+type OrderItem struct {
+	ID         uint64 `gorm:"primaryKey"`
+	OrderID    uint64
+	Order      *Order `gorm:"foreignKey:OrderID"`
+	SKU        string `gorm:"column:sku"`
+	Quantity   uint32
+	UnitWeight float64
 }
 
 // @migration
@@ -58,15 +93,51 @@ func toOrderEntity(o Order) entity.Order {
 		})
 	}
 
+	items := []entity.OrderItem{}
+	for _, i := range o.Items {
+		items = append(items, entity.OrderItem{
+			ID:         i.ID,
+			SKU:        i.SKU,
+			Quantity:   i.Quantity,
+			UnitWeight: i.UnitWeight,
+		})
+	}
+
+	handling := []entity.HandlingFlag{}
+	for _, f := range o.Handling {
+		handling = append(handling, entity.HandlingFlag(f))
+	}
+
 	return entity.Order{
-		ID:              o.ID,
-		Weight:          o.Weight,
-		Regions:         o.Regions,
-		DeliveryHours:   dh,
-		Cost:            o.Cost,
-		CompletedTime:   o.CompletedTime,
-		DeliveryGroupID: o.DeliveryGroupID,
+		ID:               o.ID,
+		Weight:           o.Weight,
+		Regions:          o.Regions,
+		DeliveryHours:    dh,
+		Cost:             o.Cost,
+		CompletedTime:    o.CompletedTime,
+		DeliveredTime:    o.DeliveredTime,
+		DeliveryGroupID:  o.DeliveryGroupID,
+		Location:         toPoint(o.Lat, o.Lon),
+		DeliverySequence: o.DeliverySequence,
+		ETA:              o.ETA,
+		DeliveryWindowID: o.DeliveryWindowID,
+		Priority:         entity.OrderPriority(o.Priority),
+		Deadline:         o.Deadline,
+		Volume:           o.Volume,
+		Dimensions:       toDimensions(o.Length, o.Width, o.Height),
+		Items:            items,
+		Handling:         handling,
+		StoreID:          o.StoreID,
+	}
+}
+
+func fromHandlingFlags(flags []entity.HandlingFlag) pq.StringArray {
+	res := pq.StringArray{}
+	for _, f := range flags {
+		res = append(res, string(f))
 	}
+
+	return res
 }
 
 type OrderToCreateDTO struct {
@@ -74,6 +145,20 @@ type OrderToCreateDTO struct {
 	Regions       int32
 	DeliveryHours []OrderDeliveryHoursIntervalDTO
 	Cost          uint32
+	Location      *geo.Point
+	Priority      entity.OrderPriority
+	Deadline      *time.Time
+	Volume        *float64
+	Dimensions    *entity.Dimensions
+	Items         []OrderItemDTO
+	Handling      []entity.HandlingFlag
+	StoreID       *uint64
+}
+
+type OrderItemDTO struct {
+	SKU        string
+	Quantity   uint32
+	UnitWeight float64
 }
 
 type OrderDeliveryHoursIntervalDTO struct {
@@ -81,46 +166,91 @@ type OrderDeliveryHoursIntervalDTO struct {
 	EndTime   time.Time
 }
 
+// BatchCreate inserts orders and their delivery hours with multi-row
+// INSERTs, INSERT_BATCH_SIZE rows per statement.
 func (s *OrderRepo) BatchCreate(ctx context.Context, newOrders []OrderToCreateDTO) (*[]entity.Order, error) {
 
-	orders := []Order{}
+	res := []entity.Order{}
+	if len(newOrders) == 0 {
+		return &res, nil
+	}
 
 	db := s.ctxGetter.DefaultTrOrDB(ctx, s.gorm).WithContext(ctx)
-	for _, o := range newOrders {
-
-		// BUG: if we assign `delivery hours` to courier here,
-		// we will deal with duplicate INSERT queries
 
+	orders := make([]Order, 0, len(newOrders))
+	for _, o := range newOrders {
+		lat, lon := fromPoint(o.Location)
+		length, width, height := fromDimensions(o.Dimensions)
 		orders = append(orders, Order{
-			Weight:  o.Weight,
-			Regions: o.Regions,
-			Cost:    o.Cost,
+			Weight:   o.Weight,
+			Regions:  o.Regions,
+			Cost:     o.Cost,
+			Lat:      lat,
+			Lon:      lon,
+			Priority: string(o.Priority),
+			Deadline: o.Deadline,
+			Volume:   o.Volume,
+			Length:   length,
+			Width:    width,
+			Height:   height,
+			Handling: fromHandlingFlags(o.Handling),
+			StoreID:  o.StoreID,
 		})
 	}
 
-	dbRes := db.CreateInBatches(orders, 20)
-	if dbRes.Error != nil {
-		return nil, dbRes.Error
+	err := db.Omit(clause.Associations).CreateInBatches(&orders, INSERT_BATCH_SIZE).Error
+	if err != nil {
+		return nil, err
 	}
 
-	for i, c := range newOrders {
-		order := &orders[i]
-
-		for _, dh := range c.DeliveryHours {
-			(*order).DeliveryHours = append(order.DeliveryHours, OrderDeliveryHours{
-				Order:     order,
+	hours := []OrderDeliveryHours{}
+	for i, o := range newOrders {
+		for _, dh := range o.DeliveryHours {
+			hours = append(hours, OrderDeliveryHours{
+				OrderID:   orders[i].ID,
 				StartTime: types.NewTime(dh.StartTime.Hour(), dh.StartTime.Minute(), dh.StartTime.Second()),
 				EndTime:   types.NewTime(dh.EndTime.Hour(), dh.EndTime.Minute(), dh.EndTime.Second()),
 			})
 		}
+	}
 
-		dbRes = db.Save(order)
-		if dbRes.Error != nil {
-			return nil, dbRes.Error
+	if len(hours) > 0 {
+		err = db.Omit(clause.Associations).CreateInBatches(&hours, INSERT_BATCH_SIZE).Error
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	items := []OrderItem{}
+	for i, o := range newOrders {
+		for _, item := range o.Items {
+			items = append(items, OrderItem{
+				OrderID:    orders[i].ID,
+				SKU:        item.SKU,
+				Quantity:   item.Quantity,
+				UnitWeight: item.UnitWeight,
+			})
+		}
+	}
+
+	if len(items) > 0 {
+		err = db.Omit(clause.Associations).CreateInBatches(&items, INSERT_BATCH_SIZE).Error
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// hours and items are in the same order as orders
+	j, k := 0, 0
+	for i := range orders {
+		for ; j < len(hours) && hours[j].OrderID == orders[i].ID; j++ {
+			orders[i].DeliveryHours = append(orders[i].DeliveryHours, hours[j])
+		}
+		for ; k < len(items) && items[k].OrderID == orders[i].ID; k++ {
+			orders[i].Items = append(orders[i].Items, items[k])
 		}
 	}
 
-	res := []entity.Order{}
 	for _, o := range orders {
 		res = append(res, toOrderEntity(o))
 	}
@@ -133,7 +263,7 @@ func (s *OrderRepo) FindById(ctx context.Context, id uint64) (*entity.Order, err
 	var order Order
 
 	db := s.ctxGetter.DefaultTrOrDB(ctx, s.gorm).WithContext(ctx)
-	err := db.Model(&Order{}).Preload("DeliveryHours").First(&order, id).Error
+	err := db.Model(&Order{}).Preload("DeliveryHours").Preload("Items").First(&order, id).Error
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return nil, &bstask.Error{
@@ -153,12 +283,19 @@ func (s *OrderRepo) FindById(ctx context.Context, id uint64) (*entity.Order, err
 	return &res, nil
 }
 
-func (s *OrderRepo) PaginatedFetchAll(ctx context.Context, offset, limit int32) (*[]entity.Order, error) {
+func (s *OrderRepo) PaginatedFetchAll(ctx context.Context, query OrderQuery, offset, limit int32) (*[]entity.Order, error) {
 
 	orders := []Order{}
 
 	db := s.ctxGetter.DefaultTrOrDB(ctx, s.gorm).WithContext(ctx)
-	err := db.Model(&Order{}).Preload("DeliveryHours").Limit(int(limit)).Offset(int(offset)).Find(&orders).Error
+	err := db.Model(&Order{}).
+		Preload("DeliveryHours").
+		Preload("Items").
+		Scopes(query.Filter.Scopes()...).
+		Scopes(OrderSorted(query.Sort)).
+		Limit(int(limit)).
+		Offset(int(offset)).
+		Find(&orders).Error
 	if err != nil {
 		return nil, err
 	}
@@ -171,11 +308,65 @@ func (s *OrderRepo) PaginatedFetchAll(ctx context.Context, offset, limit int32)
 	return &res, nil
 }
 
+// FetchAfter returns up to limit orders following the one with ID afterID
+// and sort values afterValues (see OrderSortValues). Zero afterID means the first page.
+func (s *OrderRepo) FetchAfter(ctx context.Context, query OrderQuery, afterValues []float64, afterID uint64, limit int) (*[]entity.Order, error) {
+
+	orders := []Order{}
+
+	db := s.ctxGetter.DefaultTrOrDB(ctx, s.gorm).WithContext(ctx)
+	db = db.Model(&Order{}).
+		Preload("DeliveryHours").
+		Preload("Items").
+		Scopes(query.Filter.Scopes()...).
+		Scopes(OrderSorted(query.Sort))
+
+	if afterID != 0 {
+		if len(afterValues) != len(OrderSortValues(query.Sort, entity.Order{})) {
+			return nil, &bstask.Error{
+				Op:      "repositories.OrderRepo.FetchAfter",
+				Code:    bstask.EINVALID,
+				Message: "cursor doesn't match sort",
+			}
+		}
+		db = db.Scopes(OrderAfter(query.Sort, afterValues, afterID))
+	}
+
+	err := db.Limit(limit).Find(&orders).Error
+	if err != nil {
+		return nil, err
+	}
+
+	res := []entity.Order{}
+	for _, o := range orders {
+		res = append(res, toOrderEntity(o))
+	}
+
+	return &res, nil
+}
+
+func (s *OrderRepo) Count(ctx context.Context, filter OrderFilter) (int64, error) {
+
+	var count int64
+
+	db := s.ctxGetter.DefaultTrOrDB(ctx, s.gorm).WithContext(ctx)
+	err := db.Model(&Order{}).Scopes(filter.Scopes()...).Count(&count).Error
+	if err != nil {
+		return 0, err
+	}
+
+	return count, nil
+}
+
 type OrderCompleteInfoDTO struct {
 	CourierID       uint64
 	DeliveryGroupID uint64
 	Cost            uint32
 	CompleteTime    time.Time
+	// planned delivery window, nil keeps the current one
+	DeliveryWindowID *uint64
+	// confirmed by the courier, otherwise CompleteTime is planned
+	Delivered bool
 }
 
 func (s *OrderRepo) SetCompletedInfo(ctx context.Context, order *entity.Order, info OrderCompleteInfoDTO) error {
@@ -183,28 +374,25 @@ func (s *OrderRepo) SetCompletedInfo(ctx context.Context, order *entity.Order, i
 	order.Cost = info.Cost
 	order.CompletedTime = &info.CompleteTime
 	order.DeliveryGroupID = &info.DeliveryGroupID
-
-	dh := []OrderDeliveryHours{}
-	for _, t := range order.DeliveryHours {
-		dh = append(dh, OrderDeliveryHours{
-			ID:        t.ID,
-			StartTime: types.Time(t.StartTime),
-			EndTime:   types.Time(t.EndTime),
-		})
+	if info.DeliveryWindowID != nil {
+		order.DeliveryWindowID = info.DeliveryWindowID
 	}
 
-	o := Order{
-		ID:              order.ID,
-		Weight:          order.Weight,
-		Regions:         order.Regions,
-		DeliveryHours:   dh,
-		Cost:            info.Cost,
-		CompletedTime:   &info.CompleteTime,
-		DeliveryGroupID: &info.DeliveryGroupID,
+	columns := map[string]interface{}{
+		"cost":              order.Cost,
+		"completed_time":    order.CompletedTime,
+		"delivery_group_id": order.DeliveryGroupID,
+		"delivery_hours_id": order.DeliveryWindowID,
+	}
+	if info.Delivered {
+		order.DeliveredTime = &info.CompleteTime
+		columns["delivered_time"] = order.DeliveredTime
 	}
 
 	db := s.ctxGetter.DefaultTrOrDB(ctx, s.gorm).WithContext(ctx)
-	err := db.Save(&o).Error
+	err := db.Model(&Order{}).
+		Where(`"id" = ?`, order.ID).
+		Updates(columns).Error
 	if err != nil {
 		return err
 	}
@@ -212,6 +400,35 @@ func (s *OrderRepo) SetCompletedInfo(ctx context.Context, order *entity.Order, i
 	return nil
 }
 
+type OrderStopDTO struct {
+	ID               uint64
+	Sequence         int32
+	ETA              time.Time
+	DeliveryWindowID uint64
+}
+
+// SetStops stores the delivery sequence of assigned orders, the completion
+// time follows the planned arrival.
+func (s *OrderRepo) SetStops(ctx context.Context, stops []OrderStopDTO) error {
+
+	db := s.ctxGetter.DefaultTrOrDB(ctx, s.gorm).WithContext(ctx)
+	for _, st := range stops {
+		err := db.Model(&Order{}).
+			Where(`"id" = ?`, st.ID).
+			Updates(map[string]interface{}{
+				"delivery_sequence": st.Sequence,
+				"eta":               st.ETA,
+				"completed_time":    st.ETA,
+				"delivery_hours_id": st.DeliveryWindowID,
+			}).Error
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
 func (s *OrderRepo) CostInIntervalByCourierId(
 	ctx context.Context,
 	courierID uint64,
@@ -228,8 +445,8 @@ func (s *OrderRepo) CostInIntervalByCourierId(
 		WHERE odg."courier_id" = ?
 			AND o."completed_time" between ? and ?`,
 		courierID,
-		startDate.Format("2006-01-02"),
-		endDate.Format("2006-01-02"),
+		startDate,
+		endDate,
 	).Scan(&cost).Error
 
 	if err != nil {
@@ -255,8 +472,8 @@ func (s *OrderRepo) CountInIntervalByCourierId(
 		WHERE odg."courier_id" = ?
 			AND o."completed_time" between ? and ?`,
 		courierID,
-		startDate.Format("2006-01-02"),
-		endDate.Format("2006-01-02"),
+		startDate,
+		endDate,
 	).Row().Scan(&count)
 
 	if err != nil {
@@ -266,15 +483,72 @@ func (s *OrderRepo) CountInIntervalByCourierId(
 	return *count, nil
 }
 
+type CourierTotalsDTO struct {
+	CourierID uint64
+	Cost      uint64
+	Count     uint64
+}
+
+// CourierTotalsInInterval sums cost and counts orders completed by each courier
+// between startDate and endDate, calendar dates in time zone of the courier
+// regions.
+func (s *OrderRepo) CourierTotalsInInterval(ctx context.Context, startDate, endDate time.Time) ([]CourierTotalsDTO, error) {
+
+	res := []CourierTotalsDTO{}
+
+	// regions of a courier share one time zone, see RegionCatalog.LocationOf
+	db := s.ctxGetter.DefaultTrOrDB(ctx, s.gorm).WithContext(ctx)
+	err := db.Raw(`
+		SELECT
+			odg."courier_id" as "courier_id",
+			SUM(o."cost") as "cost",
+			COUNT(o.*) as "count"
+		FROM "orders" as o
+		JOIN "delivery_groups" as odg ON odg."id" = o."delivery_group_id"
+		JOIN "couriers" as c ON c."id" = odg."courier_id"
+		LEFT JOIN "regions" as r ON r."id" = c."regions"[1]
+		WHERE o."completed_time" BETWEEN
+			(?::timestamp AT TIME ZONE COALESCE(r."time_zone", 'UTC')) AND
+			(?::timestamp AT TIME ZONE COALESCE(r."time_zone", 'UTC'))
+		GROUP BY odg."courier_id"
+		ORDER BY odg."courier_id"`,
+		startDate.Format("2006-01-02"),
+		endDate.Format("2006-01-02"),
+	).Scan(&res).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
 type FindInRegionsForCourierDTO struct {
 	MaxWeight          float64
+	MaxVolume          float64
 	Regions            []int32
 	DeliveryHoursStart time.Time
 	DeliveryHoursEnd   time.Time
 	OrderByWeightASC   bool
 	WithGap            bool
+	// Prefer orders closest to the point, orders without coordinates go last.
+	Near       *geo.Point
+	ExcludeIDs []uint64
+	// Handling flags the courier is capable of, orders with other flags are skipped.
+	Handling []entity.HandlingFlag
+	// Only orders picked up at Store, nil Store means orders without a store.
+	OnlyStore bool
+	Store     *uint64
+	// Prefer orders picked up at the store, after more urgent ones.
+	PreferStore *uint64
 }
 
+// urgentFirst orders higher priorities first, then the nearest deadlines.
+var urgentFirst = fmt.Sprintf(
+	`CASE "o"."priority" WHEN '%s' THEN 0 WHEN '%s' THEN 1 ELSE 2 END, "o"."deadline" ASC NULLS LAST,`,
+	entity.ORDER_PRIORITY_EXPRESS,
+	entity.ORDER_PRIORITY_STANDARD,
+)
+
 func (s *OrderRepo) FindInRegionForCourier(ctx context.Context, params FindInRegionsForCourierDTO) (*entity.Order, error) {
 
 	regionsArr, err := pq.Int32Array(params.Regions).Value()
@@ -282,6 +556,16 @@ func (s *OrderRepo) FindInRegionForCourier(ctx context.Context, params FindInReg
 		return nil, err
 	}
 
+	excludeArr, err := pq.Int64Array(excludeIDs(params.ExcludeIDs)).Value()
+	if err != nil {
+		return nil, err
+	}
+
+	handlingArr, err := fromHandlingFlags(params.Handling).Value()
+	if err != nil {
+		return nil, err
+	}
+
 	var order *Order = nil
 	db := s.ctxGetter.DefaultTrOrDB(ctx, s.gorm).WithContext(ctx)
 
@@ -291,32 +575,65 @@ func (s *OrderRepo) FindInRegionForCourier(ctx context.Context, params FindInReg
 			ON "odh"."order_id" = "o"."id"
 		WHERE "o"."delivery_group_id" IS NULL
 			AND "o"."weight" <= ?
+			AND COALESCE("o"."volume", 0) <= ?
 			AND "o"."regions" = ANY(?)
-			AND "odh"."start_time" %s ?
-			AND "odh"."end_time" >= ?
-		ORDER BY "o"."weight" %s
+			AND %s
+			AND ("o"."deadline" IS NULL OR "o"."deadline" > ?)
+			AND NOT ("o"."id" = ANY(?))
+			AND "o"."handling" <@ ?::text[]
+			AND %s
+		ORDER BY %s %s %s "o"."weight" %s
 		LIMIT 1
 		FOR UPDATE SKIP LOCKED`
 
+	// whether delivery fits the window is checked by the caller
+	// window is open now
+	window := clockContains(`"odh"."start_time"`, `"odh"."end_time"`, clockParam, true)
+	windowArgs := []interface{}{clock(params.DeliveryHoursStart)}
+	if params.WithGap {
+		// window opens later, before the end of shift
+		window = clockSince(clockParam, `"odh"."start_time"`) + " < ?"
+		windowArgs = []interface{}{
+			clock(params.DeliveryHoursStart),
+			int64(params.DeliveryHoursEnd.Sub(params.DeliveryHoursStart).Seconds()),
+		}
+	}
+
+	args := []interface{}{
+		params.MaxWeight,
+		params.MaxVolume,
+		regionsArr,
+	}
+	args = append(args, windowArgs...)
+	args = append(args, params.DeliveryHoursStart, excludeArr, handlingArr)
+
+	store := "TRUE"
+	if params.OnlyStore {
+		store = `"o"."store_id" IS NOT DISTINCT FROM ?`
+		args = append(args, params.Store)
+	}
+
+	preferred := ""
+	if params.PreferStore != nil {
+		preferred = `"o"."store_id" IS DISTINCT FROM ?,`
+		args = append(args, *params.PreferStore)
+	}
+
 	orderingType := "DESC"
 	if params.OrderByWeightASC {
 		orderingType = "ASC"
 	}
 
-	startTimeOperator := "<="
-	if params.WithGap {
-		startTimeOperator = ">="
+	nearest := ""
+	if params.Near != nil {
+		// planar distance is enough to compare stops within a city
+		nearest = `"o"."lat" IS NULL, ("o"."lat" - ?)^2 + (("o"."lon" - ?) * ?)^2,`
+		args = append(args, params.Near.Lat, params.Near.Lon, geo.LonScale(params.Near.Lat))
 	}
 
-	query = fmt.Sprintf(query, startTimeOperator, orderingType)
+	query = fmt.Sprintf(query, window, store, urgentFirst, preferred, nearest, orderingType)
 
-	err = db.Raw(
-		query,
-		params.MaxWeight,
-		regionsArr,
-		params.DeliveryHoursStart.Format("15:04:05"),
-		params.DeliveryHoursEnd.Format("15:04:05"),
-	).Scan(&order).Error
+	err = db.Raw(query, args...).Scan(&order).Error
 	if err != nil {
 		return nil, err
 	}
@@ -332,15 +649,33 @@ func (s *OrderRepo) FindInRegionForCourier(ctx context.Context, params FindInReg
 	}
 	order.DeliveryHours = deliveryHours
 
+	var items []OrderItem
+	err = db.Where("order_id = ?", order.ID).Find(&items).Error
+	if err != nil {
+		return nil, err
+	}
+	order.Items = items
+
 	res := toOrderEntity(*order)
 
 	return &res, nil
 }
 
-func (s *OrderRepo) OrdersInGroup(groupID uint64) (*[]entity.Order, error) {
+// excludeIDs converts IDs for pq, which has no uint64 arrays.
+func excludeIDs(ids []uint64) []int64 {
+	res := make([]int64, 0, len(ids))
+	for _, id := range ids {
+		res = append(res, int64(id))
+	}
+
+	return res
+}
+
+func (s *OrderRepo) OrdersInGroup(ctx context.Context, groupID uint64) (*[]entity.Order, error) {
 	orders := []Order{}
 
-	err := s.gorm.Where(&Order{DeliveryGroupID: &groupID}).Preload("DeliveryHours").Find(&orders).Error
+	db := s.ctxGetter.DefaultTrOrDB(ctx, s.gorm).WithContext(ctx)
+	err := db.Where(&Order{DeliveryGroupID: &groupID}).Preload("DeliveryHours").Preload("Items").Find(&orders).Error
 	if err != nil {
 		return nil, err
 	}
@@ -352,3 +687,125 @@ func (s *OrderRepo) OrdersInGroup(groupID uint64) (*[]entity.Order, error) {
 
 	return &res, nil
 }
+
+// UnassignedDueBefore returns unassigned orders with a deadline before t,
+// the most urgent first.
+func (s *OrderRepo) UnassignedDueBefore(ctx context.Context, t time.Time) (*[]entity.Order, error) {
+	orders := []Order{}
+
+	db := s.ctxGetter.DefaultTrOrDB(ctx, s.gorm).WithContext(ctx)
+	err := db.Where("delivery_group_id IS NULL AND deadline < ?", t).
+		Preload("DeliveryHours").
+		Preload("Items").
+		Order("deadline").
+		Order("id").
+		Find(&orders).Error
+	if err != nil {
+		return nil, err
+	}
+
+	res := []entity.Order{}
+	for _, o := range orders {
+		res = append(res, toOrderEntity(o))
+	}
+
+	return &res, nil
+}
+
+func (s *OrderRepo) CountUnassigned(ctx context.Context) (uint64, error) {
+
+	var count int64
+
+	db := s.ctxGetter.DefaultTrOrDB(ctx, s.gorm).WithContext(ctx)
+	err := db.Model(&Order{}).Where("delivery_group_id IS NULL").Count(&count).Error
+	if err != nil {
+		return 0, err
+	}
+
+	return uint64(count), nil
+}
+
+type OrderExportFilter struct {
+	// Inclusive range of completion date, or assign date for not completed orders.
+	From *time.Time
+	To   *time.Time
+	// Zero means any.
+	Region    int32
+	CourierID uint64
+	Status    entity.OrderStatus
+}
+
+type OrderExportDTO struct {
+	ID              uint64
+	Weight          float64
+	Regions         int32
+	Cost            uint32
+	DeliveryHours   string
+	CompletedTime   *time.Time
+	DeliveredTime   *time.Time
+	DeliveryGroupID *uint64
+	CourierID       *uint64
+	AssignDate      *time.Time
+}
+
+func (d OrderExportDTO) Status() entity.OrderStatus {
+	o := entity.Order{DeliveredTime: d.DeliveredTime, DeliveryGroupID: d.DeliveryGroupID}
+	return o.Status()
+}
+
+// Export streams orders matching filter ordered by ID.
+// Must be called inside a transaction.
+func (s *OrderRepo) Export(ctx context.Context, filter OrderExportFilter, fn func(OrderExportDTO) error) error {
+
+	conds := []string{"TRUE"}
+	args := []interface{}{}
+
+	if filter.From != nil {
+		conds = append(conds, `COALESCE(o."completed_time"::date, dg."assign_date") >= ?`)
+		args = append(args, filter.From.Format("2006-01-02"))
+	}
+	if filter.To != nil {
+		conds = append(conds, `COALESCE(o."completed_time"::date, dg."assign_date") <= ?`)
+		args = append(args, filter.To.Format("2006-01-02"))
+	}
+	if filter.Region != 0 {
+		conds = append(conds, `o."regions" = ?`)
+		args = append(args, filter.Region)
+	}
+	if filter.CourierID != 0 {
+		conds = append(conds, `dg."courier_id" = ?`)
+		args = append(args, filter.CourierID)
+	}
+	// same as entity.Order.Status, assignment sets only planned completed_time
+	switch filter.Status {
+	case entity.ORDER_STATUS_UNASSIGNED:
+		conds = append(conds, `o."delivery_group_id" IS NULL AND o."delivered_time" IS NULL`)
+	case entity.ORDER_STATUS_ASSIGNED:
+		conds = append(conds, `o."delivery_group_id" IS NOT NULL AND o."delivered_time" IS NULL`)
+	case entity.ORDER_STATUS_COMPLETED:
+		conds = append(conds, `o."delivered_time" IS NOT NULL`)
+	}
+
+	query := `
+		SELECT
+			o."id", o."weight", o."regions", o."cost", o."completed_time", o."delivered_time", o."delivery_group_id",
+			dg."courier_id", dg."assign_date",
+			COALESCE((
+				SELECT string_agg(to_char(h."start_time", 'HH24:MI') || '-' || to_char(h."end_time", 'HH24:MI'), ';' ORDER BY h."id")
+				FROM "order_delivery_hours" as h WHERE h."order_id" = o."id"
+			), '') as "delivery_hours"
+		FROM "orders" as o
+		LEFT JOIN "delivery_groups" as dg ON dg."id" = o."delivery_group_id"
+		WHERE ` + strings.Join(conds, " AND ") + `
+		ORDER BY o."id"`
+
+	db := s.ctxGetter.DefaultTrOrDB(ctx, s.gorm).WithContext(ctx)
+
+	return postgresql.Cursor(db, "export_orders", EXPORT_FETCH_SIZE, query, args, func(db *gorm.DB, rows *sql.Rows) error {
+		var row OrderExportDTO
+		if err := db.ScanRows(rows, &row); err != nil {
+			return err
+		}
+		return fn(row)
+	})
+}
@@ -0,0 +1,246 @@
+package repositories
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"yandex-team.ru/bstask/internal/entity"
+)
+
+// OrderFilter narrows order listings. Nil fields mean any.
+type OrderFilter struct {
+	Region        *int32
+	MinWeight     *float64
+	MaxWeight     *float64
+	MinCost       *uint32
+	MaxCost       *uint32
+	Assigned      *bool
+	Completed     *bool
+	CompletedFrom *time.Time
+	CompletedTo   *time.Time
+	// At least one delivery interval intersects the window.
+	DeliveryWindow *TimeWindow
+}
+
+type TimeWindow struct {
+	Start time.Time
+	End   time.Time
+}
+
+// OrderSort orders listing by Field, descending if Desc.
+type OrderSort struct {
+	Field string
+	Desc  bool
+}
+
+// Columns available for sorting. All of them are not null numbers,
+// so their values fit into keyset cursors.
+var orderSortColumns = map[string]string{
+	"id":      `"orders"."id"`,
+	"weight":  `"orders"."weight"`,
+	"cost":    `"orders"."cost"`,
+	"regions": `"orders"."regions"`,
+}
+
+func IsValidOrderSortField(field string) bool {
+	_, ok := orderSortColumns[field]
+	return ok
+}
+
+// OrderQuery combines filter and sort of listing.
+type OrderQuery struct {
+	Filter OrderFilter
+	Sort   []OrderSort
+}
+
+// Scopes returns filter conditions as gorm scopes.
+func (f OrderFilter) Scopes() []func(*gorm.DB) *gorm.DB {
+	scopes := []func(*gorm.DB) *gorm.DB{}
+
+	if f.Region != nil {
+		scopes = append(scopes, OrderInRegion(*f.Region))
+	}
+	if f.MinWeight != nil || f.MaxWeight != nil {
+		scopes = append(scopes, OrderWeightBetween(f.MinWeight, f.MaxWeight))
+	}
+	if f.MinCost != nil || f.MaxCost != nil {
+		scopes = append(scopes, OrderCostBetween(f.MinCost, f.MaxCost))
+	}
+	if f.Assigned != nil {
+		scopes = append(scopes, OrderAssigned(*f.Assigned))
+	}
+	if f.Completed != nil {
+		scopes = append(scopes, OrderCompleted(*f.Completed))
+	}
+	if f.CompletedFrom != nil || f.CompletedTo != nil {
+		scopes = append(scopes, OrderCompletedBetween(f.CompletedFrom, f.CompletedTo))
+	}
+	if f.DeliveryWindow != nil {
+		scopes = append(scopes, OrderDeliveryWindowOverlaps(*f.DeliveryWindow))
+	}
+
+	return scopes
+}
+
+func OrderInRegion(region int32) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Where(`"orders"."regions" = ?`, region)
+	}
+}
+
+func OrderWeightBetween(min, max *float64) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if min != nil {
+			db = db.Where(`"orders"."weight" >= ?`, *min)
+		}
+		if max != nil {
+			db = db.Where(`"orders"."weight" <= ?`, *max)
+		}
+		return db
+	}
+}
+
+func OrderCostBetween(min, max *uint32) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if min != nil {
+			db = db.Where(`"orders"."cost" >= ?`, *min)
+		}
+		if max != nil {
+			db = db.Where(`"orders"."cost" <= ?`, *max)
+		}
+		return db
+	}
+}
+
+func OrderAssigned(assigned bool) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if assigned {
+			return db.Where(`"orders"."delivery_group_id" IS NOT NULL`)
+		}
+		return db.Where(`"orders"."delivery_group_id" IS NULL`)
+	}
+}
+
+// OrderCompleted keeps orders with confirmed delivery, assignment alone sets
+// only planned completed_time.
+func OrderCompleted(completed bool) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if completed {
+			return db.Where(`"orders"."delivered_time" IS NOT NULL`)
+		}
+		return db.Where(`"orders"."delivered_time" IS NULL`)
+	}
+}
+
+func OrderCompletedBetween(from, to *time.Time) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if from != nil {
+			db = db.Where(`"orders"."completed_time" >= ?`, from.UTC())
+		}
+		if to != nil {
+			db = db.Where(`"orders"."completed_time" <= ?`, to.UTC())
+		}
+		return db
+	}
+}
+
+func OrderDeliveryWindowOverlaps(w TimeWindow) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Where(
+			`EXISTS (
+				SELECT 1 FROM "order_delivery_hours" as h
+				WHERE h."order_id" = "orders"."id" AND (`+
+				clockContains(`h."start_time"`, `h."end_time"`, clockParam, true)+" OR "+
+				clockSince(clockParam, `h."start_time"`)+" < "+clockSince(clockParam, clockParam)+`
+				)
+			)`,
+			clock(w.Start),
+			clock(w.Start),
+			clock(w.End),
+			clock(w.Start),
+		)
+	}
+}
+
+// OrderSorted orders by sort fields with ID as the last tie-breaker.
+func OrderSorted(sort []OrderSort) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		for _, s := range withIDSort(sort) {
+			dir := "ASC"
+			if s.Desc {
+				dir = "DESC"
+			}
+			db = db.Order(orderSortColumns[s.Field] + " " + dir)
+		}
+		return db
+	}
+}
+
+// OrderAfter skips rows up to the one with sort values `values` and ID `id`,
+// respecting direction of every sort field. `values` must match OrderSortValues.
+func OrderAfter(sort []OrderSort, values []float64, id uint64) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		sort := withIDSort(sort)
+
+		keys := make([]interface{}, 0, len(sort))
+		for _, v := range values {
+			keys = append(keys, v)
+		}
+		keys = append(keys, id)
+
+		// (a > x) OR (a = x AND b < y) OR (a = x AND b = y AND id > z)
+		ors := []string{}
+		args := []interface{}{}
+		for i, s := range sort {
+			ands := []string{}
+			for j := 0; j < i; j++ {
+				ands = append(ands, orderSortColumns[sort[j].Field]+" = ?")
+				args = append(args, keys[j])
+			}
+
+			op := ">"
+			if s.Desc {
+				op = "<"
+			}
+			ands = append(ands, fmt.Sprintf("%s %s ?", orderSortColumns[s.Field], op))
+			args = append(args, keys[i])
+
+			ors = append(ors, "("+strings.Join(ands, " AND ")+")")
+		}
+
+		return db.Where("("+strings.Join(ors, " OR ")+")", args...)
+	}
+}
+
+// withIDSort appends ID to sort, so the order is total.
+func withIDSort(sort []OrderSort) []OrderSort {
+	res := []OrderSort{}
+	for _, s := range sort {
+		res = append(res, s)
+		if s.Field == "id" {
+			// the rest can't change the order
+			return res
+		}
+	}
+
+	return append(res, OrderSort{Field: "id"})
+}
+
+// OrderSortValues returns values of non-ID sort fields of o, for keyset cursor.
+func OrderSortValues(sort []OrderSort, o entity.Order) []float64 {
+	values := []float64{}
+	for _, s := range withIDSort(sort) {
+		switch s.Field {
+		case "weight":
+			values = append(values, o.Weight)
+		case "cost":
+			values = append(values, float64(o.Cost))
+		case "regions":
+			values = append(values, float64(o.Regions))
+		}
+	}
+
+	return values
+}
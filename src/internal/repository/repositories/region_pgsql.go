@@ -0,0 +1,197 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+
+	trmgorm "github.com/avito-tech/go-transaction-manager/gorm"
+	"github.com/lib/pq"
+	"gorm.io/gorm"
+	"yandex-team.ru/bstask"
+	"yandex-team.ru/bstask/internal/entity"
+	"yandex-team.ru/bstask/pkg/geo"
+)
+
+// @migration
+type Region struct {
+	ID         int32 `gorm:"primaryKey;autoIncrement:false"`
+	Name       string
+	Active     bool
+	Neighbours pq.Int32Array `gorm:"type:integer[]"`
+	DepotLat   *float64
+	DepotLon   *float64
+	TimeZone   string
+}
+
+type RegionRepo struct {
+	gorm      *gorm.DB
+	ctxGetter *trmgorm.CtxGetter
+}
+
+func NewRegionRepo(grm *gorm.DB, c *trmgorm.CtxGetter) *RegionRepo {
+	return &RegionRepo{
+		gorm:      grm,
+		ctxGetter: c,
+	}
+}
+
+type RegionDTO struct {
+	ID         int32
+	Name       string
+	Active     bool
+	Neighbours []int32
+	Depot      *geo.Point
+	TimeZone   string
+}
+
+func toRegionEntity(r Region) entity.Region {
+	neighbours := []int32{}
+	neighbours = append(neighbours, r.Neighbours...)
+
+	return entity.Region{
+		ID:         r.ID,
+		Name:       r.Name,
+		Active:     r.Active,
+		Neighbours: neighbours,
+		Depot:      toPoint(r.DepotLat, r.DepotLon),
+		TimeZone:   r.TimeZone,
+	}
+}
+
+func toRegionModel(r RegionDTO) Region {
+	neighbours := pq.Int32Array{}
+	neighbours = append(neighbours, r.Neighbours...)
+
+	lat, lon := fromPoint(r.Depot)
+
+	return Region{
+		ID:         r.ID,
+		Name:       r.Name,
+		Active:     r.Active,
+		Neighbours: neighbours,
+		DepotLat:   lat,
+		DepotLon:   lon,
+		TimeZone:   r.TimeZone,
+	}
+}
+
+func (s *RegionRepo) BatchCreate(ctx context.Context, newRegions []RegionDTO) (*[]entity.Region, error) {
+
+	res := []entity.Region{}
+	if len(newRegions) == 0 {
+		return &res, nil
+	}
+
+	regions := make([]Region, 0, len(newRegions))
+	for _, r := range newRegions {
+		regions = append(regions, toRegionModel(r))
+	}
+
+	db := s.ctxGetter.DefaultTrOrDB(ctx, s.gorm).WithContext(ctx)
+	if err := db.CreateInBatches(&regions, INSERT_BATCH_SIZE).Error; err != nil {
+		return nil, err
+	}
+
+	for _, r := range regions {
+		res = append(res, toRegionEntity(r))
+	}
+
+	return &res, nil
+}
+
+func (s *RegionRepo) Update(ctx context.Context, r RegionDTO) (*entity.Region, error) {
+
+	region := toRegionModel(r)
+
+	db := s.ctxGetter.DefaultTrOrDB(ctx, s.gorm).WithContext(ctx)
+	res := db.Model(&Region{ID: r.ID}).Select("Name", "Active", "Neighbours", "DepotLat", "DepotLon", "TimeZone").Updates(&region)
+	if res.Error != nil {
+		return nil, res.Error
+	}
+	if res.RowsAffected == 0 {
+		return nil, regionNotFound("repositories.RegionRepo.Update", r.ID, nil)
+	}
+
+	e := toRegionEntity(region)
+
+	return &e, nil
+}
+
+// Delete removes region and drops it from neighbours of other regions.
+// Orders and couriers keep their region numbers.
+func (s *RegionRepo) Delete(ctx context.Context, id int32) error {
+
+	db := s.ctxGetter.DefaultTrOrDB(ctx, s.gorm).WithContext(ctx)
+
+	res := db.Delete(&Region{}, id)
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return regionNotFound("repositories.RegionRepo.Delete", id, nil)
+	}
+
+	return db.Model(&Region{}).
+		Where("neighbours @> ARRAY[?]::integer[]", id).
+		Update("neighbours", gorm.Expr("array_remove(neighbours, ?)", id)).Error
+}
+
+func (s *RegionRepo) FindById(ctx context.Context, id int32) (*entity.Region, error) {
+
+	var region Region
+
+	db := s.ctxGetter.DefaultTrOrDB(ctx, s.gorm).WithContext(ctx)
+	err := db.Where("id = ?", id).First(&region).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, regionNotFound("repositories.RegionRepo.FindById", id, err)
+		}
+
+		return nil, err
+	}
+
+	e := toRegionEntity(region)
+
+	return &e, nil
+}
+
+// FetchAll returns all regions ordered by ID. The catalog is small,
+// so it is never paginated.
+func (s *RegionRepo) FetchAll(ctx context.Context) (*[]entity.Region, error) {
+
+	regions := []Region{}
+
+	db := s.ctxGetter.DefaultTrOrDB(ctx, s.gorm).WithContext(ctx)
+	if err := db.Order("id").Find(&regions).Error; err != nil {
+		return nil, err
+	}
+
+	res := []entity.Region{}
+	for _, r := range regions {
+		res = append(res, toRegionEntity(r))
+	}
+
+	return &res, nil
+}
+
+func (s *RegionRepo) Catalog(ctx context.Context) (*entity.RegionCatalog, error) {
+
+	regions, err := s.FetchAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return entity.NewRegionCatalog(*regions), nil
+}
+
+func regionNotFound(op string, id int32, err error) error {
+	return &bstask.Error{
+		Op:      op,
+		Code:    bstask.ENOTFOUND,
+		Err:     err,
+		Message: "region not found",
+		Fields: map[string]interface{}{
+			"region_id": id,
+		},
+	}
+}
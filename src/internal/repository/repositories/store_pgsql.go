@@ -0,0 +1,182 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	trmgorm "github.com/avito-tech/go-transaction-manager/gorm"
+	"gorm.io/gorm"
+	"yandex-team.ru/bstask"
+	"yandex-team.ru/bstask/internal/entity"
+	"yandex-team.ru/bstask/pkg/geo"
+)
+
+// @migration
+type Store struct {
+	ID            uint64 `gorm:"primaryKey"`
+	Name          string
+	Region        int32
+	Lat           float64
+	Lon           float64
+	PickupMinutes uint32
+}
+
+type StoreRepo struct {
+	gorm      *gorm.DB
+	ctxGetter *trmgorm.CtxGetter
+}
+
+func NewStoreRepo(grm *gorm.DB, c *trmgorm.CtxGetter) *StoreRepo {
+	return &StoreRepo{
+		gorm:      grm,
+		ctxGetter: c,
+	}
+}
+
+type StoreDTO struct {
+	ID            uint64
+	Name          string
+	Region        int32
+	Location      geo.Point
+	PickupMinutes uint32
+}
+
+func toStoreEntity(s Store) entity.Store {
+	return entity.Store{
+		ID:         s.ID,
+		Name:       s.Name,
+		Region:     s.Region,
+		Location:   geo.Point{Lat: s.Lat, Lon: s.Lon},
+		PickupTime: time.Duration(s.PickupMinutes) * time.Minute,
+	}
+}
+
+func toStoreModel(s StoreDTO) Store {
+	return Store{
+		ID:            s.ID,
+		Name:          s.Name,
+		Region:        s.Region,
+		Lat:           s.Location.Lat,
+		Lon:           s.Location.Lon,
+		PickupMinutes: s.PickupMinutes,
+	}
+}
+
+func (s *StoreRepo) BatchCreate(ctx context.Context, newStores []StoreDTO) (*[]entity.Store, error) {
+
+	res := []entity.Store{}
+	if len(newStores) == 0 {
+		return &res, nil
+	}
+
+	stores := make([]Store, 0, len(newStores))
+	for _, st := range newStores {
+		stores = append(stores, toStoreModel(st))
+	}
+
+	db := s.ctxGetter.DefaultTrOrDB(ctx, s.gorm).WithContext(ctx)
+	if err := db.CreateInBatches(&stores, INSERT_BATCH_SIZE).Error; err != nil {
+		return nil, err
+	}
+
+	for _, st := range stores {
+		res = append(res, toStoreEntity(st))
+	}
+
+	return &res, nil
+}
+
+func (s *StoreRepo) Update(ctx context.Context, st StoreDTO) (*entity.Store, error) {
+
+	store := toStoreModel(st)
+
+	db := s.ctxGetter.DefaultTrOrDB(ctx, s.gorm).WithContext(ctx)
+	res := db.Model(&Store{ID: st.ID}).Select("Name", "Region", "Lat", "Lon", "PickupMinutes").Updates(&store)
+	if res.Error != nil {
+		return nil, res.Error
+	}
+	if res.RowsAffected == 0 {
+		return nil, storeNotFound("repositories.StoreRepo.Update", st.ID, nil)
+	}
+
+	e := toStoreEntity(store)
+
+	return &e, nil
+}
+
+// Delete removes store, its orders and courier shifts are left without a store.
+func (s *StoreRepo) Delete(ctx context.Context, id uint64) error {
+
+	db := s.ctxGetter.DefaultTrOrDB(ctx, s.gorm).WithContext(ctx)
+
+	res := db.Delete(&Store{}, id)
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return storeNotFound("repositories.StoreRepo.Delete", id, nil)
+	}
+
+	return nil
+}
+
+func (s *StoreRepo) FindById(ctx context.Context, id uint64) (*entity.Store, error) {
+
+	var store Store
+
+	db := s.ctxGetter.DefaultTrOrDB(ctx, s.gorm).WithContext(ctx)
+	err := db.Where("id = ?", id).First(&store).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, storeNotFound("repositories.StoreRepo.FindById", id, err)
+		}
+
+		return nil, err
+	}
+
+	e := toStoreEntity(store)
+
+	return &e, nil
+}
+
+// FetchAll returns all stores ordered by ID. There are a few stores per
+// city, so the list is never paginated.
+func (s *StoreRepo) FetchAll(ctx context.Context) (*[]entity.Store, error) {
+
+	stores := []Store{}
+
+	db := s.ctxGetter.DefaultTrOrDB(ctx, s.gorm).WithContext(ctx)
+	if err := db.Order("id").Find(&stores).Error; err != nil {
+		return nil, err
+	}
+
+	res := []entity.Store{}
+	for _, st := range stores {
+		res = append(res, toStoreEntity(st))
+	}
+
+	return &res, nil
+}
+
+func (s *StoreRepo) Catalog(ctx context.Context) (*entity.StoreCatalog, error) {
+
+	stores, err := s.FetchAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return entity.NewStoreCatalog(*stores), nil
+}
+
+func storeNotFound(op string, id uint64, err error) error {
+	return &bstask.Error{
+		Op:      op,
+		Code:    bstask.ENOTFOUND,
+		Err:     err,
+		Message: "store not found",
+		Fields: map[string]interface{}{
+			"store_id": id,
+		},
+	}
+}
@@ -0,0 +1,130 @@
+// Package bulk holds the shared part of streaming imports:
+// modes, per-row errors and chunked saving.
+package bulk
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/avito-tech/go-transaction-manager/trm"
+)
+
+type Mode string
+
+const (
+	// Whole import is rolled back if any row is rejected.
+	MODE_ATOMIC Mode = "atomic"
+	// Valid rows are saved, rejected ones are reported.
+	MODE_BEST_EFFORT Mode = "best_effort"
+)
+
+func ValidMode(m string) bool {
+	return m == string(MODE_ATOMIC) || m == string(MODE_BEST_EFFORT)
+}
+
+// ErrRejected rolls back transaction of atomic import with rejected rows.
+var ErrRejected = errors.New("import rejected")
+
+// Rows are saved by chunks of this size.
+const CHUNK_SIZE = 1000
+
+// Only first errors are kept in the result, the rest are just counted.
+const MAX_REPORTED_ERRORS = 1000
+
+// RowError rejects a single row of the input.
+type RowError struct {
+	Line int
+	Err  error
+}
+
+func (e *RowError) Error() string {
+	return fmt.Sprintf("line %d: %s", e.Line, e.Err)
+}
+
+func (e *RowError) Unwrap() error { return e.Err }
+
+type Result struct {
+	Mode     Mode
+	Imported int
+	Failed   int
+	Errors   []RowError
+}
+
+func (r *Result) AddError(line int, err error) {
+	r.Failed++
+	if len(r.Errors) < MAX_REPORTED_ERRORS {
+		r.Errors = append(r.Errors, RowError{Line: line, Err: err})
+	}
+}
+
+// Rejected tells that nothing was saved because of rejected rows.
+func (r *Result) Rejected() bool {
+	return r.Mode == MODE_ATOMIC && r.Failed > 0
+}
+
+// Importer saves chunks of rows according to mode.
+// In atomic mode caller must run the whole import in one transaction.
+type Importer struct {
+	trm    trm.Manager
+	Result Result
+}
+
+func New(trm trm.Manager, mode Mode) *Importer {
+	return &Importer{
+		trm:    trm,
+		Result: Result{Mode: mode, Errors: []RowError{}},
+	}
+}
+
+// Flush saves rows of chunk, `lines` holds their line numbers.
+// save(ctx, from, to) must store rows [from, to) of the chunk.
+//
+// In best effort mode every chunk gets its own transaction and a failed chunk
+// is retried row by row to find the broken ones.
+func (im *Importer) Flush(ctx context.Context, lines []int, save func(ctx context.Context, from, to int) error) error {
+	if len(lines) == 0 {
+		return nil
+	}
+
+	if im.Result.Mode == MODE_ATOMIC {
+		// the import is doomed, don't waste time on saving
+		if im.Result.Failed > 0 {
+			return nil
+		}
+
+		if err := save(ctx, 0, len(lines)); err != nil {
+			return err
+		}
+		im.Result.Imported += len(lines)
+
+		return nil
+	}
+
+	err := im.trm.Do(ctx, func(ctx context.Context) error {
+		return save(ctx, 0, len(lines))
+	})
+	if err == nil {
+		im.Result.Imported += len(lines)
+		return nil
+	}
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+
+	for i, line := range lines {
+		err := im.trm.Do(ctx, func(ctx context.Context) error {
+			return save(ctx, i, i+1)
+		})
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			im.Result.AddError(line, err)
+			continue
+		}
+		im.Result.Imported++
+	}
+
+	return nil
+}
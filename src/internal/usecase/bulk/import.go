@@ -0,0 +1,105 @@
+package bulk
+
+import (
+	"context"
+	"errors"
+	"io"
+
+	"github.com/avito-tech/go-transaction-manager/trm"
+)
+
+// Import streams rows of type T from next until io.EOF, converts them to D
+// and saves them by chunks of CHUNK_SIZE with create.
+//
+// setup runs once before reading, inside the import transaction in atomic mode,
+// and returns prepare which validates and converts a single row, e.g. against
+// catalogs loaded by setup. Rows rejected by next or prepare are reported in
+// the result, any other error aborts the import.
+func Import[T, D any](
+	ctx context.Context,
+	trm trm.Manager,
+	mode Mode,
+	next func() (int, T, error),
+	setup func(ctx context.Context) (prepare func(T) (D, error), err error),
+	create func(ctx context.Context, rows []D) error,
+) (*Result, error) {
+
+	im := New(trm, mode)
+
+	run := func(ctx context.Context) error {
+		prepare, err := setup(ctx)
+		if err != nil {
+			return err
+		}
+
+		chunk := []D{}
+		lines := []int{}
+
+		flush := func() error {
+			err := im.Flush(ctx, lines, func(ctx context.Context, from, to int) error {
+				return create(ctx, chunk[from:to])
+			})
+			chunk = chunk[:0]
+			lines = lines[:0]
+
+			return err
+		}
+
+		for {
+			line, row, err := next()
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			if err != nil {
+				var rowErr *RowError
+				if errors.As(err, &rowErr) {
+					im.Result.AddError(rowErr.Line, rowErr.Err)
+					continue
+				}
+				return err
+			}
+
+			dto, err := prepare(row)
+			if err != nil {
+				im.Result.AddError(line, err)
+				continue
+			}
+
+			chunk = append(chunk, dto)
+			lines = append(lines, line)
+
+			if len(chunk) >= CHUNK_SIZE {
+				if err := flush(); err != nil {
+					return err
+				}
+			}
+		}
+
+		if err := flush(); err != nil {
+			return err
+		}
+
+		if im.Result.Rejected() {
+			return ErrRejected
+		}
+
+		return nil
+	}
+
+	var err error
+	if mode == MODE_ATOMIC {
+		err = trm.Do(ctx, run)
+	} else {
+		err = run(ctx)
+	}
+
+	if errors.Is(err, ErrRejected) {
+		im.Result.Imported = 0
+		return &im.Result, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &im.Result, nil
+}
@@ -1,13 +1,52 @@
 package courier
 
 import (
+	"time"
+
 	"yandex-team.ru/bstask/internal/entity"
+	"yandex-team.ru/bstask/pkg/geo"
 )
 
 type CourierToCreateDTO struct {
 	CourierType  string   `validate:"required,courier_type"`
 	Regions      []int32  `validate:"required,unique"`
 	WorkingHours []string `validate:"required,unique,each_HH_MM_HH_MM_time_interval"`
+	// Optional rest periods inside working hours and shift limits.
+	Breaks                      []string `validate:"omitempty,unique,each_HH_MM_HH_MM_time_interval"`
+	MaxDeliveriesPerShift       *uint32  `validate:"omitempty,min=1"`
+	MaxContinuousDrivingMinutes *uint32  `validate:"omitempty,min=1"`
+	Capabilities                []string `validate:"omitempty,unique,dive,courier_capability"`
+	// Store a working interval starts at, intervals not listed start at the
+	// region depot.
+	ShiftStores map[string]uint64
+}
+
+// CourierStatusDTO is a status reported by the courier app. Zero ReportedAt
+// means now.
+type CourierStatusDTO struct {
+	Status     string `validate:"required,courier_status"`
+	ReportedAt time.Time
+}
+
+// CourierLocationDTO is a position reported by the courier app. Zero
+// ReportedAt means now.
+type CourierLocationDTO struct {
+	Location   geo.Point
+	ReportedAt time.Time
+}
+
+// CourierRowReader streams couriers to import. Next returns io.EOF after the last row,
+// *bulk.RowError rejects only the current row, any other error aborts the import.
+type CourierRowReader interface {
+	Next() (line int, c CourierToCreateDTO, err error)
+}
+
+type CourierPageDTO struct {
+	Couriers []entity.Courier
+	// Nil on the last page.
+	NextCursor *string
+	// Filled only on request.
+	Total *int64
 }
 
 type CourierMetaDTO struct {
@@ -15,6 +54,12 @@ type CourierMetaDTO struct {
 	Earnings *int32
 }
 
+type CourierPayrollDTO struct {
+	Courier  entity.Courier
+	Earnings int32
+	Rating   *int32
+}
+
 type AssignResponseGroupItem struct {
 	CourierId uint64
 	Orders    map[uint64]AssignOrdersGroup
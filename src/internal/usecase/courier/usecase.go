@@ -5,39 +5,56 @@ import (
 	"strings"
 	"time"
 
-	"github.com/avito-tech/go-transaction-manager/trm/manager"
+	"github.com/avito-tech/go-transaction-manager/trm"
 	"gopkg.in/go-playground/validator.v9"
 	"yandex-team.ru/bstask"
 	"yandex-team.ru/bstask/internal/entity"
 	"yandex-team.ru/bstask/internal/repository/repositories"
+	"yandex-team.ru/bstask/internal/usecase/bulk"
+	"yandex-team.ru/bstask/pkg/pagination"
+	"yandex-team.ru/bstask/pkg/tracing"
 	validatations "yandex-team.ru/bstask/pkg/validations"
 )
 
 type CourierUseCase struct {
-	trm               *manager.Manager
+	trm               trm.Manager
 	validator         *validator.Validate
 	CourierRepo       *repositories.CourierRepo
 	OrderRepo         *repositories.OrderRepo
 	DeliveryGroupRepo *repositories.DeliveryGroupRepo
+	RegionRepo        *repositories.RegionRepo
+	StoreRepo         *repositories.StoreRepo
+	LiveRepo          *repositories.CourierLiveRepo
 }
 
+// Courier app clocks may run a bit ahead of the server.
+const MAX_CLOCK_SKEW = 5 * time.Minute
+
 func New(
-	trm *manager.Manager,
+	trm trm.Manager,
 	curstrg *repositories.CourierRepo,
 	ordrepo *repositories.OrderRepo,
 	dgrepo *repositories.DeliveryGroupRepo,
+	regrepo *repositories.RegionRepo,
+	storerepo *repositories.StoreRepo,
+	liverepo *repositories.CourierLiveRepo,
 ) *CourierUseCase {
 
 	v := validator.New()
 	v.RegisterValidation("each_HH_MM_time", validatations.Each_HH_MM_time)
 	v.RegisterValidation("each_HH_MM_HH_MM_time_interval", validatations.Each_HH_MM_HH_MM_time_interval)
 	v.RegisterValidation("courier_type", courier_type)
+	v.RegisterValidation("courier_capability", courier_capability)
+	v.RegisterValidation("courier_status", courier_status)
 
 	return &CourierUseCase{
 		trm:               trm,
 		CourierRepo:       curstrg,
 		OrderRepo:         ordrepo,
 		DeliveryGroupRepo: dgrepo,
+		RegionRepo:        regrepo,
+		StoreRepo:         storerepo,
+		LiveRepo:          liverepo,
 		validator:         v,
 	}
 }
@@ -45,56 +62,222 @@ func New(
 func (uc *CourierUseCase) CreateCouriers(ctx context.Context, couriers []CourierToCreateDTO) (*[]entity.Courier, error) {
 	op := "usecase.courier.CreateCouriers"
 
-	toCreate := []repositories.CourierToCreateDTO{}
-	for _, c := range couriers {
-		if err := uc.validator.Struct(c); err != nil {
-			return nil, bstask.ErrorWithCode(bstask.OpError(op, err), bstask.EINVALID)
+	ctx, span := tracing.Start(ctx, op)
+	defer span.End()
+
+	var savedCouriers *[]entity.Courier
+
+	err := uc.trm.Do(ctx, func(ctx context.Context) error {
+		catalog, err := uc.RegionRepo.Catalog(ctx)
+		if err != nil {
+			return err
 		}
 
-		intervals := []repositories.CourierWorkingHoursIntervalDTO{}
-		for _, i := range c.WorkingHours {
-			spl := strings.Split(i, "-")
+		stores, err := uc.StoreRepo.Catalog(ctx)
+		if err != nil {
+			return err
+		}
 
-			startTime, err := time.Parse("15:04", spl[0])
+		toCreate := []repositories.CourierToCreateDTO{}
+		for _, c := range couriers {
+			dto, err := uc.prepareToCreate(catalog, stores, c)
 			if err != nil {
-				return nil, bstask.ErrorWithCode(bstask.OpError(op, err), bstask.EINVALID)
+				return bstask.ErrorWithCode(err, bstask.EINVALID)
 			}
 
-			endTime, err := time.Parse("15:04", spl[1])
-			if err != nil {
-				return nil, bstask.ErrorWithCode(bstask.OpError(op, err), bstask.EINVALID)
+			toCreate = append(toCreate, dto)
+		}
+
+		savedCouriers, err = uc.CourierRepo.BatchCreate(ctx, toCreate)
+		return err
+	})
+	if err != nil {
+		return nil, bstask.OpError(op, err)
+	}
+
+	return savedCouriers, nil
+}
+
+// prepareToCreate validates courier against regions and stores catalogs and converts it for repository.
+func (uc *CourierUseCase) prepareToCreate(catalog *entity.RegionCatalog, stores *entity.StoreCatalog, c CourierToCreateDTO) (repositories.CourierToCreateDTO, error) {
+	op := "usecase.courier.prepareToCreate"
+
+	if err := uc.validator.Struct(c); err != nil {
+		return repositories.CourierToCreateDTO{}, err
+	}
+	if err := catalog.CheckActive(c.Regions...); err != nil {
+		return repositories.CourierToCreateDTO{}, err
+	}
+	// working hours are local clock of the courier regions
+	if err := catalog.CheckSameTimeZone(c.Regions...); err != nil {
+		return repositories.CourierToCreateDTO{}, err
+	}
+
+	intervals, err := parseIntervals(c.WorkingHours)
+	if err != nil {
+		return repositories.CourierToCreateDTO{}, err
+	}
+
+	for interval := range c.ShiftStores {
+		if !containsInterval(c.WorkingHours, interval) {
+			return repositories.CourierToCreateDTO{}, &bstask.Error{
+				Op:      op,
+				Code:    bstask.EINVALID,
+				Message: "shift store must be set for one of working hours",
+				Fields:  map[string]interface{}{"interval": interval},
+			}
+		}
+	}
+	for i, wh := range c.WorkingHours {
+		var storeID *uint64
+		if id, ok := c.ShiftStores[wh]; ok {
+			storeID = &id
+		}
+
+		if err := stores.CheckExists(storeID); err != nil {
+			return repositories.CourierToCreateDTO{}, err
+		}
+		if store, ok := stores.Get(storeID); ok && !containsRegion(c.Regions, store.Region) {
+			return repositories.CourierToCreateDTO{}, &bstask.Error{
+				Op:      op,
+				Code:    bstask.EINVALID,
+				Message: "store must be in one of courier regions",
+				Fields:  map[string]interface{}{"store_id": *storeID, "interval": wh},
 			}
+		}
 
-			intervals = append(intervals, repositories.CourierWorkingHoursIntervalDTO{
-				StartTime: startTime,
-				EndTime:   endTime,
-			})
+		intervals[i].StoreID = storeID
+	}
+
+	breaks, err := parseIntervals(c.Breaks)
+	if err != nil {
+		return repositories.CourierToCreateDTO{}, err
+	}
+	for i, b := range breaks {
+		if !withinAny(intervals, b) {
+			return repositories.CourierToCreateDTO{}, &bstask.Error{
+				Op:      op,
+				Code:    bstask.EINVALID,
+				Message: "break must be inside working hours",
+				Fields:  map[string]interface{}{"break": c.Breaks[i]},
+			}
 		}
+	}
+
+	capabilities := []entity.Capability{}
+	for _, cp := range c.Capabilities {
+		capabilities = append(capabilities, entity.Capability(cp))
+	}
+
+	return repositories.CourierToCreateDTO{
+		CourierType:                 c.CourierType,
+		Regions:                     c.Regions,
+		WorkingHours:                intervals,
+		Breaks:                      breaks,
+		MaxDeliveriesPerShift:       c.MaxDeliveriesPerShift,
+		MaxContinuousDrivingMinutes: c.MaxContinuousDrivingMinutes,
+		Capabilities:                capabilities,
+	}, nil
+}
+
+func containsRegion(regions []int32, region int32) bool {
+	for _, r := range regions {
+		if r == region {
+			return true
+		}
+	}
+
+	return false
+}
 
-		toCreate = append(toCreate, repositories.CourierToCreateDTO{
-			CourierType:  c.CourierType,
-			Regions:      c.Regions,
-			WorkingHours: intervals,
+func containsInterval(intervals []string, interval string) bool {
+	for _, i := range intervals {
+		if i == interval {
+			return true
+		}
+	}
+
+	return false
+}
+
+// parseIntervals converts validated HH:MM-HH:MM intervals.
+func parseIntervals(in []string) ([]repositories.CourierWorkingHoursIntervalDTO, error) {
+	intervals := []repositories.CourierWorkingHoursIntervalDTO{}
+	for _, i := range in {
+		spl := strings.Split(i, "-")
+
+		startTime, err := time.Parse("15:04", spl[0])
+		if err != nil {
+			return nil, err
+		}
+
+		endTime, err := time.Parse("15:04", spl[1])
+		if err != nil {
+			return nil, err
+		}
+
+		intervals = append(intervals, repositories.CourierWorkingHoursIntervalDTO{
+			StartTime: startTime,
+			EndTime:   endTime,
 		})
 	}
 
-	var savedCouriers *[]entity.Courier
-	var err error
+	return intervals, nil
+}
 
-	err = uc.trm.Do(ctx, func(ctx context.Context) error {
-		savedCouriers, err = uc.CourierRepo.BatchCreate(ctx, toCreate)
-		return err
-	})
+func withinAny(intervals []repositories.CourierWorkingHoursIntervalDTO, i repositories.CourierWorkingHoursIntervalDTO) bool {
+	for _, wh := range intervals {
+		if entity.ClockWithin(wh.StartTime, wh.EndTime, i.StartTime, i.EndTime) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Import streams couriers from rows and saves them by chunks.
+// Rejected rows are reported in result with their line numbers.
+func (uc *CourierUseCase) Import(ctx context.Context, rows CourierRowReader, mode bulk.Mode) (*bulk.Result, error) {
+	op := "usecase.courier.Import"
+
+	ctx, span := tracing.Start(ctx, op)
+	defer span.End()
+
+	res, err := bulk.Import(ctx, uc.trm, mode, rows.Next,
+		func(ctx context.Context) (func(CourierToCreateDTO) (repositories.CourierToCreateDTO, error), error) {
+			catalog, err := uc.RegionRepo.Catalog(ctx)
+			if err != nil {
+				return nil, err
+			}
+
+			stores, err := uc.StoreRepo.Catalog(ctx)
+			if err != nil {
+				return nil, err
+			}
+
+			return func(c CourierToCreateDTO) (repositories.CourierToCreateDTO, error) {
+				return uc.prepareToCreate(catalog, stores, c)
+			}, nil
+		},
+		func(ctx context.Context, rows []repositories.CourierToCreateDTO) error {
+			_, err := uc.CourierRepo.BatchCreate(ctx, rows)
+			return err
+		},
+	)
 	if err != nil {
+		tracing.RecordError(span, err)
 		return nil, bstask.OpError(op, err)
 	}
 
-	return savedCouriers, nil
+	return res, nil
 }
 
 func (uc *CourierUseCase) GetById(ctx context.Context, id uint64) (*entity.Courier, error) {
 	op := "usecase.courier.GetById"
 
+	ctx, span := tracing.Start(ctx, op)
+	defer span.End()
+
 	courier, err := uc.CourierRepo.FindById(ctx, id)
 	if err != nil {
 		return nil, bstask.OpError(op, err)
@@ -103,10 +286,118 @@ func (uc *CourierUseCase) GetById(ctx context.Context, id uint64) (*entity.Couri
 	return courier, nil
 }
 
-func (uc *CourierUseCase) PaginatedGetAll(ctx context.Context, offset, limit int32) (*[]entity.Courier, error) {
+// ReportStatus appends status of an existing courier to its history.
+func (uc *CourierUseCase) ReportStatus(ctx context.Context, courierID uint64, s CourierStatusDTO) error {
+	op := "usecase.courier.ReportStatus"
+
+	ctx, span := tracing.Start(ctx, op)
+	defer span.End()
+
+	if err := uc.validator.Struct(s); err != nil {
+		return bstask.ErrorWithCode(bstask.OpError(op, err), bstask.EINVALID)
+	}
+
+	at, err := reportedAt(op, s.ReportedAt)
+	if err != nil {
+		return err
+	}
+
+	err = uc.trm.Do(ctx, func(ctx context.Context) error {
+		if _, err := uc.CourierRepo.FindById(ctx, courierID); err != nil {
+			return err
+		}
+
+		return uc.LiveRepo.AddStatus(ctx, courierID, entity.CourierStatus(s.Status), at)
+	})
+	if err != nil {
+		return bstask.OpError(op, err)
+	}
+
+	return nil
+}
+
+// ReportLocation appends position of an existing courier to its history.
+func (uc *CourierUseCase) ReportLocation(ctx context.Context, courierID uint64, l CourierLocationDTO) error {
+	op := "usecase.courier.ReportLocation"
+
+	ctx, span := tracing.Start(ctx, op)
+	defer span.End()
+
+	if !l.Location.Valid() {
+		return &bstask.Error{
+			Op:      op,
+			Code:    bstask.EINVALID,
+			Message: "invalid courier coordinates",
+		}
+	}
+
+	at, err := reportedAt(op, l.ReportedAt)
+	if err != nil {
+		return err
+	}
+
+	err = uc.trm.Do(ctx, func(ctx context.Context) error {
+		if _, err := uc.CourierRepo.FindById(ctx, courierID); err != nil {
+			return err
+		}
+
+		return uc.LiveRepo.AddLocation(ctx, courierID, l.Location, at)
+	})
+	if err != nil {
+		return bstask.OpError(op, err)
+	}
+
+	return nil
+}
+
+// reportedAt defaults time of a report to now and rejects times in the future.
+func reportedAt(op string, t time.Time) (time.Time, error) {
+	now := time.Now().UTC()
+	if t.IsZero() {
+		return now, nil
+	}
+	if t.After(now.Add(MAX_CLOCK_SKEW)) {
+		return time.Time{}, &bstask.Error{
+			Op:      op,
+			Code:    bstask.EINVALID,
+			Message: "timestamp is in the future",
+			Fields:  map[string]interface{}{"timestamp": t},
+		}
+	}
+
+	return t.UTC(), nil
+}
+
+// Live returns the latest status and location of an existing courier.
+func (uc *CourierUseCase) Live(ctx context.Context, courierID uint64) (*entity.CourierLive, error) {
+	op := "usecase.courier.Live"
+
+	ctx, span := tracing.Start(ctx, op)
+	defer span.End()
+
+	if _, err := uc.CourierRepo.FindById(ctx, courierID); err != nil {
+		return nil, bstask.OpError(op, err)
+	}
+
+	live, err := uc.LiveRepo.Latest(ctx, courierID)
+	if err != nil {
+		return nil, bstask.OpError(op, err)
+	}
+
+	return live, nil
+}
+
+func (uc *CourierUseCase) PaginatedGetAll(ctx context.Context, filter repositories.CourierFilter, offset, limit int32) (*[]entity.Courier, error) {
 	op := "usecase.courier.PaginatedGetAll"
 
-	couriers, err := uc.CourierRepo.PaginatedFetchAll(ctx, offset, limit)
+	ctx, span := tracing.Start(ctx, op)
+	defer span.End()
+
+	if err := validateCourierFilter(op, filter); err != nil {
+		return nil, err
+	}
+
+	couriers, err := uc.CourierRepo.PaginatedFetchAll(ctx, filter, offset, limit)
 	if err != nil {
 		return nil, bstask.OpError(op, err)
 	}
@@ -114,11 +405,83 @@ func (uc *CourierUseCase) PaginatedGetAll(ctx context.Context, offset, limit int
 	return couriers, nil
 }
 
+// KeysetGetAll returns page of couriers following cursor, ordered by ID.
+func (uc *CourierUseCase) KeysetGetAll(ctx context.Context, filter repositories.CourierFilter, cursor string, limit int32, withTotal bool) (*CourierPageDTO, error) {
+	op := "usecase.courier.KeysetGetAll"
+
+	ctx, span := tracing.Start(ctx, op)
+	defer span.End()
+
+	if err := validateCourierFilter(op, filter); err != nil {
+		return nil, err
+	}
+
+	after, err := pagination.Decode(cursor)
+	if err != nil {
+		return nil, &bstask.Error{Op: op, Code: bstask.EINVALID, Err: err, Message: "invalid cursor"}
+	}
+
+	// one extra row tells if there is a next page
+	couriers, err := uc.CourierRepo.FetchAfter(ctx, filter, after.ID, int(limit)+1)
+	if err != nil {
+		return nil, bstask.OpError(op, err)
+	}
+
+	res := CourierPageDTO{Couriers: *couriers}
+	if len(res.Couriers) > int(limit) {
+		res.Couriers = res.Couriers[:limit]
+		if limit > 0 {
+			next := pagination.Cursor{ID: res.Couriers[limit-1].ID}.Encode()
+			res.NextCursor = &next
+		}
+	}
+
+	if withTotal {
+		total, err := uc.CourierRepo.Count(ctx, filter)
+		if err != nil {
+			return nil, bstask.OpError(op, err)
+		}
+		res.Total = &total
+	}
+
+	return &res, nil
+}
+
+func validateCourierFilter(op string, f repositories.CourierFilter) error {
+	if f.CourierType != nil && !entity.IsValidCourierType(*f.CourierType) {
+		return &bstask.Error{
+			Op:      op,
+			Code:    bstask.EINVALID,
+			Message: "invalid courier type",
+			Fields:  map[string]interface{}{"courier_type": *f.CourierType},
+		}
+	}
+	// a window ending before its start crosses midnight
+	if f.AvailableBetween != nil && f.AvailableBetween.Start.Equal(f.AvailableBetween.End) {
+		return &bstask.Error{
+			Op:      op,
+			Code:    bstask.EINVALID,
+			Message: "availability window must not be empty",
+		}
+	}
+
+	return nil
+}
+
 func (uc *CourierUseCase) MetaInInterval(ctx context.Context, courier *entity.Courier, startDate, endDate time.Time) (*CourierMetaDTO, error) {
 	op := "usecase.courier.MetaInInterval"
 
-	startDate = startDate.UTC()
-	endDate = endDate.UTC()
+	ctx, span := tracing.Start(ctx, op)
+	defer span.End()
+
+	// dates are local to the courier regions
+	catalog, err := uc.RegionRepo.Catalog(ctx)
+	if err != nil {
+		return nil, bstask.OpError(op, err)
+	}
+	loc := catalog.LocationOf(courier.Regions)
+	startDate = time.Date(startDate.Year(), startDate.Month(), startDate.Day(), 0, 0, 0, 0, loc)
+	endDate = time.Date(endDate.Year(), endDate.Month(), endDate.Day(), 0, 0, 0, 0, loc)
 
 	if startDate.After(endDate) {
 		return nil, &bstask.Error{
@@ -128,8 +491,6 @@ func (uc *CourierUseCase) MetaInInterval(ctx context.Context, courier *entity.Co
 		}
 	}
 
-	res := CourierMetaDTO{}
-
 	ordersCount, err := uc.OrderRepo.CountInIntervalByCourierId(ctx, courier.ID, startDate, endDate)
 	if err != nil {
 		return nil, bstask.OpError(op, err)
@@ -138,10 +499,30 @@ func (uc *CourierUseCase) MetaInInterval(ctx context.Context, courier *entity.Co
 		return &CourierMetaDTO{}, nil
 	}
 
-	ratingRatio, err := courier.RatingRatio()
+	ordersCost, err := uc.OrderRepo.CostInIntervalByCourierId(ctx, courier.ID, startDate, endDate)
+	if err != nil {
+		return nil, bstask.OpError(op, err)
+	}
+
+	res, err := courierMeta(courier, ordersCount, ordersCost, endDate.Sub(startDate))
 	if err != nil {
 		return nil, bstask.OpError(op, err)
 	}
+
+	return res, nil
+}
+
+// courierMeta computes rating and earnings of courier, who completed count
+// orders of total cost within interval of diff length.
+func courierMeta(courier *entity.Courier, count, cost uint64, diff time.Duration) (*CourierMetaDTO, error) {
+	const op = "usecase.courier.courierMeta"
+
+	res := CourierMetaDTO{}
+
+	ratingRatio, err := courier.RatingRatio()
+	if err != nil {
+		return nil, err
+	}
 	if ratingRatio == 0 {
 		return nil, &bstask.Error{
 			Op:      op,
@@ -154,35 +535,152 @@ func (uc *CourierUseCase) MetaInInterval(ctx context.Context, courier *entity.Co
 		}
 	}
 
-	diff := endDate.Sub(startDate)
 	if diff != 0 {
-		rating := int32(ordersCount) / int32(diff.Hours()) * int32(ratingRatio)
+		rating := int32(count) / int32(diff.Hours()) * int32(ratingRatio)
 		res.Rating = &rating
 	}
 
-	ordersCost, err := uc.OrderRepo.CostInIntervalByCourierId(ctx, courier.ID, startDate, endDate)
+	salaryRatio, err := courier.SalaryRatio()
+	if err != nil {
+		return nil, err
+	}
+
+	earnings := int32(cost) * int32(salaryRatio)
+	res.Earnings = &earnings
+
+	return &res, nil
+}
+
+// Payroll collects earnings of every courier, who completed orders in interval.
+func (uc *CourierUseCase) Payroll(ctx context.Context, startDate, endDate time.Time) ([]CourierPayrollDTO, error) {
+	op := "usecase.courier.Payroll"
+
+	ctx, span := tracing.Start(ctx, op)
+	defer span.End()
+
+	if startDate.After(endDate) {
+		return nil, &bstask.Error{
+			Op:      op,
+			Code:    bstask.EINVALID,
+			Message: "start date is after end date",
+		}
+	}
+
+	totals, err := uc.OrderRepo.CourierTotalsInInterval(ctx, startDate, endDate)
 	if err != nil {
 		return nil, bstask.OpError(op, err)
 	}
 
-	salaryRatio, err := courier.SalaryRatio()
+	ids := make([]uint64, 0, len(totals))
+	byCourier := make(map[uint64]repositories.CourierTotalsDTO, len(totals))
+	for _, t := range totals {
+		ids = append(ids, t.CourierID)
+		byCourier[t.CourierID] = t
+	}
+
+	couriers, err := uc.CourierRepo.FindByIds(ctx, ids)
 	if err != nil {
 		return nil, bstask.OpError(op, err)
 	}
 
-	earnings := int32(ordersCost) * int32(salaryRatio)
-	res.Earnings = &earnings
+	// dates are local to the courier regions
+	catalog, err := uc.RegionRepo.Catalog(ctx)
+	if err != nil {
+		return nil, bstask.OpError(op, err)
+	}
 
-	return &res, nil
+	res := []CourierPayrollDTO{}
+	for _, c := range *couriers {
+		t := byCourier[c.ID]
+
+		loc := catalog.LocationOf(c.Regions)
+		start := time.Date(startDate.Year(), startDate.Month(), startDate.Day(), 0, 0, 0, 0, loc)
+		end := time.Date(endDate.Year(), endDate.Month(), endDate.Day(), 0, 0, 0, 0, loc)
+
+		meta, err := courierMeta(&c, t.Count, t.Cost, end.Sub(start))
+		if err != nil {
+			return nil, bstask.OpError(op, err)
+		}
+
+		res = append(res, CourierPayrollDTO{
+			Courier:  c,
+			Earnings: *meta.Earnings,
+			Rating:   meta.Rating,
+		})
+	}
+
+	return res, nil
+}
+
+// Export streams couriers matching filter to fn inside one transaction.
+func (uc *CourierUseCase) Export(ctx context.Context, filter repositories.CourierExportFilter, fn func(repositories.CourierExportDTO) error) error {
+	op := "usecase.courier.Export"
+
+	ctx, span := tracing.Start(ctx, op)
+	defer span.End()
+
+	if filter.CourierType != "" && !entity.IsValidCourierType(filter.CourierType) {
+		return &bstask.Error{
+			Op:      op,
+			Code:    bstask.EINVALID,
+			Message: "unknown courier type",
+			Fields:  map[string]interface{}{"courier_type": filter.CourierType},
+		}
+	}
+
+	err := uc.trm.Do(ctx, func(ctx context.Context) error {
+		return uc.CourierRepo.Export(ctx, filter, fn)
+	})
+	if err != nil {
+		tracing.RecordError(span, err)
+		return bstask.OpError(op, err)
+	}
+
+	return nil
+}
+
+// ExportAssignments streams assigned orders of the interval to fn inside one transaction.
+func (uc *CourierUseCase) ExportAssignments(ctx context.Context, filter repositories.AssignmentExportFilter, fn func(repositories.AssignmentExportDTO) error) error {
+	op := "usecase.courier.ExportAssignments"
+
+	ctx, span := tracing.Start(ctx, op)
+	defer span.End()
+
+	if filter.From.After(filter.To) {
+		return &bstask.Error{
+			Op:      op,
+			Code:    bstask.EINVALID,
+			Message: ":from is after :to param",
+		}
+	}
+
+	err := uc.trm.Do(ctx, func(ctx context.Context) error {
+		return uc.DeliveryGroupRepo.ExportAssignments(ctx, filter, fn)
+	})
+	if err != nil {
+		tracing.RecordError(span, err)
+		return bstask.OpError(op, err)
+	}
+
+	return nil
 }
 
 func (uc *CourierUseCase) Assignments(ctx context.Context, courierIDs []uint64, date time.Time) ([]AssignResponseGroupItem, error) {
 	op := "usecase.courier.Assignments"
 
+	ctx, span := tracing.Start(ctx, op)
+	defer span.End()
+
 	couriersOrders := make(map[uint64]AssignResponseGroupItem)
 	var groups *[]entity.DeliveryGroup
 	var err error
 
+	// order times are shown in local time of their regions
+	catalog, err := uc.RegionRepo.Catalog(ctx)
+	if err != nil {
+		return []AssignResponseGroupItem{}, bstask.OpError(op, err)
+	}
+
 	if len(courierIDs) == 0 {
 		groups, err = uc.DeliveryGroupRepo.AllByDate(ctx, date)
 		if err != nil {
@@ -206,10 +704,11 @@ func (uc *CourierUseCase) Assignments(ctx context.Context, courierIDs []uint64,
 			}
 		}
 
-		orders, err := uc.OrderRepo.OrdersInGroup(g.ID)
+		orders, err := uc.OrderRepo.OrdersInGroup(ctx, g.ID)
 		if err != nil {
 			return []AssignResponseGroupItem{}, bstask.OpError(op, err)
 		}
+		catalog.LocalizeOrders(*orders)
 
 		for _, order := range *orders {
 			assignOrdersGroup, ok := assignResponseGroupItem.Orders[g.ID]
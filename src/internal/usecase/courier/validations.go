@@ -19,3 +19,29 @@ func courier_type(fl validator.FieldLevel) bool {
 
 	return entity.IsValidCourierType(s)
 }
+
+func courier_status(fl validator.FieldLevel) bool {
+	if fl.Field().Type().Kind() != reflect.String {
+		return false
+	}
+
+	s, ok := fl.Field().Interface().(string)
+	if !ok {
+		return false
+	}
+
+	return entity.IsValidCourierStatus(s)
+}
+
+func courier_capability(fl validator.FieldLevel) bool {
+	if fl.Field().Type().Kind() != reflect.String {
+		return false
+	}
+
+	s, ok := fl.Field().Interface().(string)
+	if !ok {
+		return false
+	}
+
+	return entity.IsValidCapability(s)
+}
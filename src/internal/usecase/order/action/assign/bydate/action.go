@@ -4,27 +4,46 @@ import (
 	"context"
 	"time"
 
+	"go.opentelemetry.io/otel/attribute"
 	"yandex-team.ru/bstask/internal/entity"
 	"yandex-team.ru/bstask/internal/repository/repositories"
+	"yandex-team.ru/bstask/pkg/tracing"
 )
 
-var couriersOrders map[uint64]AssignResponseGroupItem = make(map[uint64]AssignResponseGroupItem)
+// Bound of UTC offsets of time zones.
+const MAX_ZONE_OFFSET = 14 * time.Hour
 
 type ActionAssignByDate struct {
 	CourierRepo       *repositories.CourierRepo
 	OrderRepo         *repositories.OrderRepo
 	DeliveryGroupRepo *repositories.DeliveryGroupRepo
+	RegionRepo        *repositories.RegionRepo
+	StoreRepo         *repositories.StoreRepo
+	travel            entity.TravelTimeModel
+	// adjacency of regions, loaded once per run
+	regions *entity.RegionCatalog
+	// pickup stores, loaded once per run
+	stores *entity.StoreCatalog
+	// assigned orders of the current run, by courier id
+	couriersOrders map[uint64]AssignResponseGroupItem
 }
 
 func New(
 	CourierRepo *repositories.CourierRepo,
 	OrderRepo *repositories.OrderRepo,
 	DeliveryGroupRepo *repositories.DeliveryGroupRepo,
+	RegionRepo *repositories.RegionRepo,
+	StoreRepo *repositories.StoreRepo,
+	travel entity.TravelTimeModel,
 ) *ActionAssignByDate {
 	return &ActionAssignByDate{
 		CourierRepo:       CourierRepo,
 		OrderRepo:         OrderRepo,
 		DeliveryGroupRepo: DeliveryGroupRepo,
+		RegionRepo:        RegionRepo,
+		StoreRepo:         StoreRepo,
+		travel:            travel,
+		couriersOrders:    make(map[uint64]AssignResponseGroupItem),
 	}
 }
 
@@ -36,12 +55,28 @@ func (a *ActionAssignByDate) Assign(ctx context.Context, assignDate time.Time) (
 		Date: assignDate,
 	}
 
+	regions, err := a.RegionRepo.Catalog(ctx)
+	if err != nil {
+		return AssignResponseGroup{}, err
+	}
+	a.regions = regions
+
+	stores, err := a.StoreRepo.Catalog(ctx)
+	if err != nil {
+		return AssignResponseGroup{}, err
+	}
+	a.stores = stores
+
 	footCouriersWorkingHours, err := a.CourierRepo.AllWorkingHoursByCourierType(ctx, entity.FOOT)
 	if err != nil {
 		return AssignResponseGroup{}, err
 	}
 
 	for _, wh := range *footCouriersWorkingHours {
+		if err := ctx.Err(); err != nil {
+			return AssignResponseGroup{}, err
+		}
+
 		err := a.assignToWorkingInterval(ctx, assignDate, wh, *a.OrderRepo)
 		if err != nil {
 			return AssignResponseGroup{}, err
@@ -54,6 +89,10 @@ func (a *ActionAssignByDate) Assign(ctx context.Context, assignDate time.Time) (
 	}
 
 	for _, wh := range *bikeCouriersWorkingHours {
+		if err := ctx.Err(); err != nil {
+			return AssignResponseGroup{}, err
+		}
+
 		err := a.assignToWorkingInterval(ctx, assignDate, wh, *a.OrderRepo)
 		if err != nil {
 			return AssignResponseGroup{}, err
@@ -66,16 +105,47 @@ func (a *ActionAssignByDate) Assign(ctx context.Context, assignDate time.Time) (
 	}
 
 	for _, wh := range *autoCouriersWorkingHours {
+		if err := ctx.Err(); err != nil {
+			return AssignResponseGroup{}, err
+		}
+
 		err := a.assignToWorkingInterval(ctx, assignDate, wh, *a.OrderRepo)
 		if err != nil {
 			return AssignResponseGroup{}, err
 		}
 	}
 
-	for _, gr := range couriersOrders {
+	for _, gr := range a.couriersOrders {
 		res.Couriers = append(res.Couriers, gr)
 	}
 
+	res.SLABreaching, err = a.slaBreaching(ctx, assignDate)
+	if err != nil {
+		return AssignResponseGroup{}, err
+	}
+
+	return res, nil
+}
+
+// slaBreaching returns orders left unassigned with deadline before the end
+// of the assign date, local date of the order region.
+func (a *ActionAssignByDate) slaBreaching(ctx context.Context, assignDate time.Time) ([]entity.Order, error) {
+
+	// the latest end of the date across time zones
+	due, err := a.OrderRepo.UnassignedDueBefore(ctx, assignDate.AddDate(0, 0, 1).Add(MAX_ZONE_OFFSET))
+	if err != nil {
+		return nil, err
+	}
+
+	res := []entity.Order{}
+	for _, o := range *due {
+		loc := a.regions.Location(o.Regions)
+		dayEnd := time.Date(assignDate.Year(), assignDate.Month(), assignDate.Day()+1, 0, 0, 0, 0, loc)
+		if o.Deadline.Before(dayEnd) {
+			res = append(res, o)
+		}
+	}
+
 	return res, nil
 }
 
@@ -86,8 +156,19 @@ func (a *ActionAssignByDate) assignToWorkingInterval(
 	orderRepo repositories.OrderRepo,
 ) error {
 
-	startDateTime := time.Date(assignDate.Year(), assignDate.Month(), assignDate.Day(), wh.StartTime.Hour(), wh.StartTime.Minute(), wh.StartTime.Second(), 0, assignDate.Location())
-	endDateTime := time.Date(assignDate.Year(), assignDate.Month(), assignDate.Day(), wh.EndTime.Hour(), wh.EndTime.Minute(), wh.EndTime.Second(), 0, assignDate.Location())
+	ctx, span := tracing.Start(
+		ctx,
+		"bydate.assignToWorkingInterval",
+		attribute.Int64("courier_id", int64(wh.CourierID)),
+		attribute.Int64("working_hours_id", int64(wh.WorkingHoursID)),
+	)
+	defer span.End()
+
+	// working hours are local clock of the courier regions, an overnight
+	// shift starts on the assign date and ends next day
+	loc := a.regions.LocationOf(wh.Regions)
+	day := time.Date(assignDate.Year(), assignDate.Month(), assignDate.Day(), 0, 0, 0, 0, loc)
+	startDateTime, endDateTime := entity.IntervalOnDate(day, wh.StartTime, wh.EndTime)
 
 	potential, err := entity.DeliveryPotentialForType(entity.CourierType(wh.CourierType))
 	if err != nil {
@@ -96,6 +177,7 @@ func (a *ActionAssignByDate) assignToWorkingInterval(
 
 	courierState, err := initCourierState(
 		a.DeliveryGroupRepo,
+		a.OrderRepo,
 		wh.CourierID,
 		wh.WorkingHoursID,
 		potential,
@@ -103,31 +185,51 @@ func (a *ActionAssignByDate) assignToWorkingInterval(
 		wh.Regions,
 		startDateTime,
 		endDateTime,
+		a.travel,
+		a.regions,
+		wh.Breaks,
+		wh.MaxDeliveriesPerShift,
+		wh.MaxContinuousDriving,
+		wh.Capabilities,
+		a.stores,
+		wh.StoreID,
 	)
 	if err != nil {
-		return nil
+		return err
 	}
 
+	// orders this courier can't deliver in time from where they are
+	unreachable := []uint64{}
+
 	for {
 		var order *entity.Order
 
+		// stop issuing queries as soon as the request is aborted
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
 		if courierState.isTimeToFlush() {
-			courierState.flush(ctx)
+			if err := courierState.flush(ctx); err != nil {
+				return err
+			}
 		}
 
 		if courierState.isTimeToStop() {
 			break
 		}
 
-		order, err := a.orderForCurrentState(ctx, orderRepo, *courierState)
+		order, err := a.orderForCurrentState(ctx, orderRepo, *courierState, unreachable)
 		if err != nil {
 			return err
 		}
 
 		if order == nil {
 			if courierState.isOnTheWay {
-				courierState.flush(ctx)
-				order, err = a.orderForCurrentState(ctx, orderRepo, *courierState)
+				if err := courierState.flush(ctx); err != nil {
+					return err
+				}
+				order, err = a.orderForCurrentState(ctx, orderRepo, *courierState, unreachable)
 				if err != nil {
 					return err
 				}
@@ -138,22 +240,42 @@ func (a *ActionAssignByDate) assignToWorkingInterval(
 			}
 		}
 
-		completeDateTime, discountPrice, err := courierState.addOrder(ctx, *order)
+		stop, fits, err := courierState.nextStop(*order)
+		if err != nil {
+			return err
+		}
+		if !fits || stop.completeDateTime.After(courierState.shiftEndDateTime) {
+			// too far to make it in any window before the end of shift
+			unreachable = append(unreachable, order.ID)
+			continue
+		}
+
+		discountPrice, err := courierState.addOrder(ctx, *order, stop)
 		if err != nil {
-			return nil
+			return err
 		}
 
 		err = orderRepo.SetCompletedInfo(ctx, order, repositories.OrderCompleteInfoDTO{
-			CourierID:       wh.CourierID,
-			DeliveryGroupID: courierState.deliveryGroup.ID,
-			Cost:            discountPrice,
-			CompleteTime:    completeDateTime,
+			CourierID:        wh.CourierID,
+			DeliveryGroupID:  courierState.deliveryGroup.ID,
+			Cost:             discountPrice,
+			CompleteTime:     stop.completeDateTime,
+			DeliveryWindowID: &stop.windowID,
 		})
 		if err != nil {
 			return err
 		}
 
-		a.saveForResponse(*courierState, wh, *order)
+		courierState.recordStop(*order)
+	}
+
+	// the last batch of the shift
+	if err := courierState.flush(ctx); err != nil {
+		return err
+	}
+
+	for _, g := range courierState.groups {
+		a.saveForResponse(wh.CourierID, g)
 	}
 
 	return nil
@@ -163,6 +285,7 @@ func (a *ActionAssignByDate) orderForCurrentState(
 	ctx context.Context,
 	orderRepo repositories.OrderRepo,
 	cs courierBatchState,
+	exclude []uint64,
 ) (*entity.Order, error) {
 
 	var (
@@ -172,14 +295,23 @@ func (a *ActionAssignByDate) orderForCurrentState(
 
 	params := repositories.FindInRegionsForCourierDTO{
 		MaxWeight:          cs.availableWeight(),
+		MaxVolume:          cs.availableVolume(),
 		Regions:            []int32{cs.currRegion},
 		DeliveryHoursStart: cs.nextDeliveryStartDateTime,
 		DeliveryHoursEnd:   cs.shiftEndDateTime,
 		OrderByWeightASC:   false,
 		WithGap:            false,
+		// nearest stops first, when the courier position is known
+		Near:       cs.position,
+		ExcludeIDs: exclude,
+		Handling:   cs.handling,
 	}
 
 	if cs.isOnTheWay {
+		// orders of a batch are picked up at one store
+		params.OnlyStore = true
+		params.Store = cs.batchStore
+
 		// Search in specific region
 		if cs.nextWillBeLast() {
 			order, err = orderRepo.FindInRegionForCourier(ctx, params)
@@ -196,6 +328,8 @@ func (a *ActionAssignByDate) orderForCurrentState(
 	} else {
 		// Search in any available region
 		params.Regions = cs.availableRegions
+		// orders of the store the courier is at need no reposition
+		params.PreferStore = cs.placeStore
 		params.OrderByWeightASC = true
 
 		order, err = orderRepo.FindInRegionForCourier(ctx, params)
@@ -204,6 +338,23 @@ func (a *ActionAssignByDate) orderForCurrentState(
 		}
 	}
 
+	if order == nil && cs.isOnTheWay {
+		// continue the batch in a neighbouring region
+		if neighbours := cs.neighbourRegions(a.regions); len(neighbours) > 0 {
+			params.Regions = neighbours
+			params.OrderByWeightASC = true
+
+			order, err = orderRepo.FindInRegionForCourier(ctx, params)
+			if err != nil {
+				return nil, err
+			}
+
+			if order == nil {
+				params.Regions = append([]int32{cs.currRegion}, neighbours...)
+			}
+		}
+	}
+
 	if order == nil {
 		// try find with gap
 		params.WithGap = true
@@ -218,34 +369,16 @@ func (a *ActionAssignByDate) orderForCurrentState(
 	return order, nil
 }
 
-func (a *ActionAssignByDate) saveForResponse(
-	courierState courierBatchState,
-	wh repositories.AllWorkingHoursRes,
-	order entity.Order,
-) {
+func (a *ActionAssignByDate) saveForResponse(courierID uint64, group AssignOrdersGroup) {
 
-	assignedGroups, ok := couriersOrders[wh.CourierID]
+	assignedGroups, ok := a.couriersOrders[courierID]
 	if !ok {
-		gi := AssignResponseGroupItem{
-			CourierId: wh.CourierID,
+		assignedGroups = AssignResponseGroupItem{
+			CourierId: courierID,
 			Orders:    make(map[uint64]AssignOrdersGroup),
 		}
-		couriersOrders[wh.CourierID] = gi
-		assignedGroups = gi
+		a.couriersOrders[courierID] = assignedGroups
 	}
 
-	assignedOrdersGroup, ok := assignedGroups.Orders[courierState.deliveryGroup.ID]
-	if !ok {
-		og := AssignOrdersGroup{
-			GroupOrderId: courierState.deliveryGroup.ID,
-			Orders:       []entity.Order{},
-		}
-
-		assignedGroups.Orders[courierState.deliveryGroup.ID] = og
-		assignedOrdersGroup = og
-	}
-
-	assignedOrdersGroup.Orders = append(assignedOrdersGroup.Orders, order)
-
-	assignedGroups.Orders[courierState.deliveryGroup.ID] = assignedOrdersGroup
+	assignedGroups.Orders[group.GroupOrderId] = group
 }
@@ -9,6 +9,8 @@ import (
 type AssignResponseGroup struct {
 	Date     time.Time
 	Couriers []AssignResponseGroupItem
+	// Unassigned orders whose deadline passes by the end of the date.
+	SLABreaching []entity.Order
 }
 
 type AssignResponseGroupItem struct {
@@ -20,3 +22,14 @@ type AssignOrdersGroup struct {
 	GroupOrderId uint64
 	Orders       []entity.Order
 }
+
+func (r AssignResponseGroup) OrdersCount() int {
+	cnt := 0
+	for _, c := range r.Couriers {
+		for _, g := range c.Orders {
+			cnt += len(g.Orders)
+		}
+	}
+
+	return cnt
+}
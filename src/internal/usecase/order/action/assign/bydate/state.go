@@ -2,31 +2,72 @@ package bydate
 
 import (
 	"context"
+	"sort"
 	"time"
 
 	"yandex-team.ru/bstask/internal/entity"
 	"yandex-team.ru/bstask/internal/repository/repositories"
+	"yandex-team.ru/bstask/internal/usecase/order/action/assign/sequence"
+	"yandex-team.ru/bstask/pkg/geo"
 )
 
 type courierBatchState struct {
 	DeliveryGroupRepo         *repositories.DeliveryGroupRepo
+	OrderRepo                 *repositories.OrderRepo
 	courierID                 uint64
 	CourierWorkingHoursID     uint64
 	potential                 entity.DeliveryPotential
 	isOnTheWay                bool
 	currWeight                float64
+	currVolume                float64
 	currOrders                uint
 	currRegion                int32
+	batchRegions              []int32
 	availableRegions          []int32
 	courierType               entity.CourierType
 	nextDeliveryDuration      time.Duration
+	firstDeliveryDuration     time.Duration
 	nextDeliveryStartDateTime time.Time
+	shiftStartDateTime        time.Time
 	shiftEndDateTime          time.Time
 	deliveryGroup             *repositories.DeliveryGroup
+	travel                    entity.TravelTimeModel
+	regions                   *entity.RegionCatalog
+	// last stop of the batch, nil until known
+	position *geo.Point
+	// when the courier got free for the batch
+	batchStartDateTime time.Time
+	// assigned orders of the batch in pick order
+	stops []entity.Order
+	// flushed delivery groups with orders in delivery sequence
+	groups []AssignOrdersGroup
+	// courier breaks met by the shift, sorted by start
+	breaks []period
+	// shift limits, nil means no limit
+	maxDeliveries   *uint32
+	maxDriving      *time.Duration
+	shiftDeliveries uint32
+	// handling flags of orders the courier is capable of
+	handling []entity.HandlingFlag
+	stores   *entity.StoreCatalog
+	// where the courier waits for the next batch, nil if unknown, and
+	// the store it is at, nil if not at a store
+	place      *geo.Point
+	placeStore *uint64
+	// pickup store of the batch orders, nil for the region depot
+	batchStore *uint64
+	// reposition to the batch store and pickup before the first stop
+	batchLead time.Duration
+}
+
+type period struct {
+	start time.Time
+	end   time.Time
 }
 
 func initCourierState(
 	deliveryGroupRepo *repositories.DeliveryGroupRepo,
+	orderRepo *repositories.OrderRepo,
 	courierID uint64,
 	courierWorkingHoursID uint64,
 	p entity.DeliveryPotential,
@@ -34,28 +75,122 @@ func initCourierState(
 	regions []int32,
 	batchStart,
 	batchEnd time.Time,
+	travel entity.TravelTimeModel,
+	catalog *entity.RegionCatalog,
+	breaks []repositories.CourierWorkingHoursIntervalDTO,
+	maxDeliveries *uint32,
+	maxDriving *time.Duration,
+	capabilities []entity.Capability,
+	stores *entity.StoreCatalog,
+	homeStore *uint64,
 ) (*courierBatchState, error) {
 	duration, err := entity.NextDeliveryTimeInRegion(t, 0)
 	if err != nil {
 		return nil, err
 	}
 
-	return &courierBatchState{
+	c := &courierBatchState{
 		DeliveryGroupRepo:         deliveryGroupRepo,
+		OrderRepo:                 orderRepo,
 		courierID:                 courierID,
 		CourierWorkingHoursID:     courierWorkingHoursID,
 		potential:                 p,
 		isOnTheWay:                false,
 		currWeight:                0,
+		currVolume:                0,
 		currOrders:                0,
 		currRegion:                0,
+		batchRegions:              nil,
 		availableRegions:          regions,
 		courierType:               t,
 		nextDeliveryDuration:      duration,
+		firstDeliveryDuration:     duration,
 		nextDeliveryStartDateTime: batchStart,
+		shiftStartDateTime:        batchStart,
 		shiftEndDateTime:          batchEnd,
 		deliveryGroup:             nil,
-	}, nil
+		travel:                    travel,
+		regions:                   catalog,
+		position:                  nil,
+		breaks:                    shiftBreaks(breaks, batchStart, batchEnd),
+		maxDeliveries:             maxDeliveries,
+		maxDriving:                maxDriving,
+		handling:                  entity.HandledFlags(capabilities),
+		stores:                    stores,
+	}
+	// the shift starts at the courier store
+	if store, ok := stores.Get(homeStore); ok {
+		c.place = &store.Location
+		c.placeStore = homeStore
+	}
+
+	return c, nil
+}
+
+// shiftBreaks places breaks, local clock of the shift, on dates they meet
+// the shift.
+func shiftBreaks(breaks []repositories.CourierWorkingHoursIntervalDTO, shiftStart, shiftEnd time.Time) []period {
+	res := []period{}
+	for _, b := range breaks {
+		for day := -1; day <= 1; day++ {
+			start, end := entity.IntervalOnDate(shiftStart.AddDate(0, 0, day), b.StartTime, b.EndTime)
+			if !end.After(shiftStart) || !start.Before(shiftEnd) {
+				continue
+			}
+
+			res = append(res, period{start: start, end: end})
+		}
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i].start.Before(res[j].start) })
+
+	return res
+}
+
+// depart moves departure of a d long trip out of breaks and, with continuous
+// driving limited, to the end of a break once the courier has to rest.
+// Waiting for a delivery window is not a rest. Implements sequence.DepartFunc.
+func (c *courierBatchState) depart(departure time.Time, d time.Duration) (time.Time, bool) {
+	for {
+		moved := false
+		for _, b := range c.breaks {
+			if departure.Before(b.end) && departure.Add(d).After(b.start) {
+				departure = b.end
+				moved = true
+			}
+		}
+		if moved {
+			continue
+		}
+
+		if c.maxDriving == nil || !departure.Add(d).After(c.lastRestEnd(departure).Add(*c.maxDriving)) {
+			return departure, true
+		}
+
+		// rest on the next break
+		next := -1
+		for i, b := range c.breaks {
+			if b.end.After(departure) {
+				next = i
+				break
+			}
+		}
+		if next == -1 {
+			return time.Time{}, false
+		}
+		departure = c.breaks[next].end
+	}
+}
+
+// lastRestEnd is the end of the last break before t or the shift start.
+func (c *courierBatchState) lastRestEnd(t time.Time) time.Time {
+	res := c.shiftStartDateTime
+	for _, b := range c.breaks {
+		if !b.end.After(t) && b.end.After(res) {
+			res = b.end
+		}
+	}
+
+	return res
 }
 
 func (c *courierBatchState) flush(ctx context.Context) error {
@@ -65,54 +200,147 @@ func (c *courierBatchState) flush(ctx context.Context) error {
 		return err
 	}
 
+	if c.isOnTheWay {
+		// the courier waits for the next batch at the last stop
+		c.place = c.position
+		c.placeStore = nil
+	}
+
 	c.isOnTheWay = false
 	c.currWeight = 0
+	c.currVolume = 0
 	c.currOrders = 0
 	c.currRegion = 0
+	c.batchRegions = nil
+	c.position = nil
+	c.batchStore = nil
+	c.batchLead = 0
 	c.nextDeliveryDuration = duration
 
 	if c.deliveryGroup != nil {
 
+		if err := c.sequenceStops(ctx); err != nil {
+			return err
+		}
+
 		err := c.DeliveryGroupRepo.Update(ctx, c.deliveryGroup)
 		if err != nil {
 			return err
 		}
 
+		c.groups = append(c.groups, AssignOrdersGroup{
+			GroupOrderId: c.deliveryGroup.ID,
+			Orders:       c.stops,
+		})
+
 		c.deliveryGroup = nil
+		c.stops = nil
 	}
 
 	return nil
 }
 
+// plannedStop is delivery of the next order estimated by nextStop.
+type plannedStop struct {
+	completeDateTime time.Time
+	duration         time.Duration
+	// order delivery hours the delivery lands in
+	windowID uint64
+	// part of duration spent before leaving the store, first stop only
+	lead time.Duration
+}
+
+// nextStop estimates when order is delivered if the courier takes it next,
+// in the order window finishing earliest. The batch starts at the pickup
+// store of the order, or at depot of the order region for orders without
+// a store. fits is false if no window can be met.
+func (c *courierBatchState) nextStop(order entity.Order) (stop plannedStop, fits bool, err error) {
+
+	from := c.position
+	ordersInRegion := c.currOrders
+	var lead time.Duration
+	if !c.isOnTheWay {
+		from, lead, err = c.pickup(order)
+		if err != nil {
+			return plannedStop{}, false, err
+		}
+	} else if order.Regions != c.currRegion {
+		// moving to a neighbouring region
+		ordersInRegion = 0
+	}
+
+	duration, err := c.travel.TravelTime(c.courierType, from, order.Location, ordersInRegion)
+	if err != nil {
+		return plannedStop{}, false, err
+	}
+	duration += lead
+
+	windows := c.deliveryWindows(order)
+	w, completeDateTime, fits := sequence.Arrival(c.nextDeliveryStartDateTime, duration, windows, c.depart)
+	if !fits {
+		return plannedStop{}, false, nil
+	}
+
+	return plannedStop{
+		completeDateTime: completeDateTime,
+		duration:         duration,
+		windowID:         w.ID,
+		lead:             lead,
+	}, true, nil
+}
+
+// pickup returns where the batch of order starts and time to get there
+// from the courier place and collect orders. Orders without a store start
+// at depot of the order region, the way there is charged only when both
+// the courier place and the depot are known.
+func (c *courierBatchState) pickup(order entity.Order) (from *geo.Point, lead time.Duration, err error) {
+	store, ok := c.stores.Get(order.StoreID)
+	if !ok {
+		depot := c.regions.Depot(order.Regions)
+		if c.place != nil && depot != nil && *c.place != *depot {
+			lead, err = c.travel.TravelTime(c.courierType, c.place, depot, 0)
+			if err != nil {
+				return nil, 0, err
+			}
+		}
+
+		return depot, lead, nil
+	}
+
+	if c.place != nil && !entity.SameStore(c.placeStore, order.StoreID) {
+		lead, err = c.travel.TravelTime(c.courierType, c.place, &store.Location, 0)
+		if err != nil {
+			return nil, 0, err
+		}
+	}
+
+	return &store.Location, lead + store.PickupTime, nil
+}
+
+// addOrder puts order into the batch as a stop planned by nextStop.
 func (c *courierBatchState) addOrder(
 	ctx context.Context,
 	order entity.Order,
-) (completeDateTime time.Time, discountCost uint32, err error) {
-
-	dh := order.DeliveryHours[0]
-	orderStartDateTime := time.Date(
-		c.nextDeliveryStartDateTime.Year(),
-		c.nextDeliveryStartDateTime.Month(),
-		c.nextDeliveryStartDateTime.Day(),
-		dh.StartTime.Hour(),
-		dh.StartTime.Minute(),
-		dh.StartTime.Second(),
-		dh.StartTime.Nanosecond(),
-		c.nextDeliveryStartDateTime.Location(),
-	)
-
-	if orderStartDateTime.After(c.nextDeliveryStartDateTime) {
-		completeDateTime = orderStartDateTime.Add(c.nextDeliveryDuration)
-	} else {
-		// state on this point already flushed
-		completeDateTime = c.nextDeliveryStartDateTime.Add(c.nextDeliveryDuration)
+	stop plannedStop,
+) (discountCost uint32, err error) {
+
+	if !c.isOnTheWay {
+		c.batchStartDateTime = c.nextDeliveryStartDateTime
+		c.batchStore = order.StoreID
+		c.batchLead = stop.lead
 	}
 
 	c.isOnTheWay = true
+	if !c.visited(order.Regions) {
+		c.batchRegions = append(c.batchRegions, order.Regions)
+	}
 	c.currRegion = order.Regions
 	c.currOrders++
+	c.shiftDeliveries++
 	c.currWeight += order.Weight
-	c.nextDeliveryStartDateTime = completeDateTime
+	c.currVolume += order.VolumeOrZero()
+	c.nextDeliveryStartDateTime = stop.completeDateTime
+	c.position = order.Location
 
 	// calculate price with discount
 	discount := entity.DeliveryInBatchCostDiscountPercents(c.currOrders)
@@ -123,24 +351,175 @@ func (c *courierBatchState) addOrder(
 			ctx,
 			c.courierID,
 			c.CourierWorkingHoursID,
-			c.shiftEndDateTime,
-			completeDateTime.Add(-c.nextDeliveryDuration),
-			completeDateTime,
+			// assigned on the local date the shift starts
+			c.shiftStartDateTime,
+			stop.completeDateTime.Add(-stop.duration),
+			stop.completeDateTime,
 		)
 		if err != nil {
-			return time.Time{}, 0, err
+			return 0, err
 		}
 	} else {
-		c.deliveryGroup.EndDateTime = completeDateTime
+		c.deliveryGroup.EndDateTime = stop.completeDateTime
 	}
 
-	duration, err := entity.NextDeliveryTimeInRegion(c.courierType, c.currOrders)
+	nextDuration, err := entity.NextDeliveryTimeInRegion(c.courierType, c.currOrders)
 	if err != nil {
-		return time.Time{}, 0, err
+		return 0, err
 	}
-	c.nextDeliveryDuration = duration
+	c.nextDeliveryDuration = nextDuration
+
+	return discountCost, nil
+}
 
-	return completeDateTime, discountCost, nil
+// recordStop keeps the order assigned by addOrder for sequencing on flush.
+func (c *courierBatchState) recordStop(order entity.Order) {
+	c.stops = append(c.stops, order)
+}
+
+// sequenceStops reorders stops of the batch to finish it earliest within
+// delivery windows and stores sequence and ETAs. Pick order is kept when
+// no better feasible sequence is found.
+func (c *courierBatchState) sequenceStops(ctx context.Context) error {
+
+	byID := make(map[uint64]entity.Order, len(c.stops))
+	stops := make([]sequence.Stop, 0, len(c.stops))
+	for _, o := range c.stops {
+		byID[o.ID] = o
+		stops = append(stops, sequence.Stop{
+			ID:      o.ID,
+			Windows: c.deliveryWindows(o),
+		})
+	}
+
+	travel := func(from *sequence.Stop, to sequence.Stop) (time.Duration, error) {
+		order := byID[to.ID]
+		if from == nil {
+			start := c.regions.Depot(order.Regions)
+			if store, ok := c.stores.Get(order.StoreID); ok {
+				start = &store.Location
+			}
+
+			d, err := c.travel.TravelTime(c.courierType, start, order.Location, 0)
+			return d + c.batchLead, err
+		}
+
+		prev := byID[from.ID]
+		var ordersInRegion uint = 1
+		if prev.Regions != order.Regions {
+			ordersInRegion = 0
+		}
+
+		return c.travel.TravelTime(c.courierType, prev.Location, order.Location, ordersInRegion)
+	}
+
+	plan, ok, err := sequence.Optimize(c.batchStartDateTime, c.shiftEndDateTime, stops, travel, c.depart)
+	if err != nil {
+		return err
+	}
+
+	if !ok {
+		plan = sequence.Plan{Stops: stops}
+		for _, o := range c.stops {
+			plan.ETAs = append(plan.ETAs, *o.CompletedTime)
+			plan.Windows = append(plan.Windows, sequence.Window{ID: *o.DeliveryWindowID})
+		}
+	} else {
+		first, err := travel(nil, plan.Stops[0])
+		if err != nil {
+			return err
+		}
+		c.deliveryGroup.StartDateTime = plan.ETAs[0].Add(-first)
+	}
+	c.deliveryGroup.EndDateTime = plan.End()
+	c.nextDeliveryStartDateTime = plan.End()
+
+	ordered := make([]entity.Order, 0, len(plan.Stops))
+	dto := make([]repositories.OrderStopDTO, 0, len(plan.Stops))
+	for i, st := range plan.Stops {
+		o := byID[st.ID]
+		seq := int32(i + 1)
+		eta := plan.ETAs[i]
+		windowID := plan.Windows[i].ID
+		o.DeliverySequence = &seq
+		o.ETA = &eta
+		o.CompletedTime = &eta
+		o.DeliveryWindowID = &windowID
+
+		ordered = append(ordered, o)
+		dto = append(dto, repositories.OrderStopDTO{
+			ID:               o.ID,
+			Sequence:         seq,
+			ETA:              eta,
+			DeliveryWindowID: windowID,
+		})
+	}
+
+	if err := c.OrderRepo.SetStops(ctx, dto); err != nil {
+		return err
+	}
+	c.stops = ordered
+
+	return nil
+}
+
+// deliveryWindows places order delivery hours, local clock of the order
+// region, on dates they meet the shift. A shift or a window crossing midnight
+// may meet windows of the previous or the next day. Windows are cut at the
+// order deadline.
+func (c *courierBatchState) deliveryWindows(order entity.Order) []sequence.Window {
+	shiftStart := c.shiftStartDateTime.In(c.regions.Location(order.Regions))
+
+	res := []sequence.Window{}
+	for _, dh := range order.DeliveryHours {
+		for day := -1; day <= 1; day++ {
+			start, end := entity.IntervalOnDate(shiftStart.AddDate(0, 0, day), dh.StartTime, dh.EndTime)
+			if order.Deadline != nil && order.Deadline.Before(end) {
+				end = *order.Deadline
+			}
+			if !end.After(start) || !end.After(c.shiftStartDateTime) || !start.Before(c.shiftEndDateTime) {
+				continue
+			}
+
+			res = append(res, sequence.Window{
+				ID:    dh.ID,
+				Start: start,
+				End:   end,
+			})
+		}
+	}
+
+	return res
+}
+
+// neighbourRegions returns courier regions adjacent to the current one,
+// where the batch may continue without exceeding MaxRegions.
+func (c *courierBatchState) neighbourRegions(catalog *entity.RegionCatalog) []int32 {
+	if !c.isOnTheWay || uint(len(c.batchRegions)) >= c.potential.MaxRegions {
+		return nil
+	}
+	if c.shiftEndDateTime.Before(c.nextDeliveryStartDateTime.Add(c.firstDeliveryDuration)) {
+		return nil
+	}
+
+	res := []int32{}
+	for _, r := range c.availableRegions {
+		if catalog.Adjacent(c.currRegion, r) && !c.visited(r) {
+			res = append(res, r)
+		}
+	}
+
+	return res
+}
+
+func (c *courierBatchState) visited(region int32) bool {
+	for _, r := range c.batchRegions {
+		if r == region {
+			return true
+		}
+	}
+
+	return false
 }
 
 func (c *courierBatchState) nextWillBeLast() bool {
@@ -151,8 +530,14 @@ func (c *courierBatchState) availableWeight() float64 {
 	return c.potential.MaxWeight - c.currWeight
 }
 
+func (c *courierBatchState) availableVolume() float64 {
+	return c.potential.MaxVolume - c.currVolume
+}
+
 func (c *courierBatchState) isTimeToFlush() bool {
-	if c.currOrders >= c.potential.MaxOrders || c.currWeight >= c.potential.MaxWeight {
+	if c.currOrders >= c.potential.MaxOrders ||
+		c.currWeight >= c.potential.MaxWeight ||
+		c.currVolume >= c.potential.MaxVolume {
 		return true
 	}
 
@@ -160,7 +545,12 @@ func (c *courierBatchState) isTimeToFlush() bool {
 }
 
 func (c *courierBatchState) isTimeToStop() bool {
-	if c.shiftEndDateTime.Before(c.nextDeliveryStartDateTime.Add(c.nextDeliveryDuration)) {
+	if c.maxDeliveries != nil && c.shiftDeliveries >= *c.maxDeliveries {
+		return true
+	}
+
+	departure, ok := c.depart(c.nextDeliveryStartDateTime, c.nextDeliveryDuration)
+	if !ok || c.shiftEndDateTime.Before(departure.Add(c.nextDeliveryDuration)) {
 		return true
 	}
 
@@ -0,0 +1,218 @@
+// Package sequence orders stops of a delivery group to finish it as early as
+// possible within delivery windows: nearest neighbour tour improved by 2-opt.
+package sequence
+
+import (
+	"sort"
+	"time"
+)
+
+// Bound on 2-opt passes, groups are small and converge in a few.
+const MAX_2OPT_PASSES = 50
+
+// Window is a delivery window placed on a date, ID refers to the order
+// delivery hours it was made of.
+type Window struct {
+	ID    uint64
+	Start time.Time
+	End   time.Time
+}
+
+type Stop struct {
+	ID      uint64
+	Windows []Window
+}
+
+// TravelFunc returns time to deliver to `to` coming from `from`,
+// from is nil for the first stop of the group.
+type TravelFunc func(from *Stop, to Stop) (time.Duration, error)
+
+// DepartFunc moves departure of a d long trip to the earliest time the courier
+// may make it, e.g. after a break. ok is false if the trip can't be made at all.
+// nil DepartFunc allows any departure.
+type DepartFunc func(departure time.Time, d time.Duration) (time.Time, bool)
+
+// Plan is stops in visiting order with their arrival times and windows
+// the arrivals fall into.
+type Plan struct {
+	Stops   []Stop
+	ETAs    []time.Time
+	Windows []Window
+}
+
+func (p Plan) End() time.Time {
+	if len(p.ETAs) == 0 {
+		return time.Time{}
+	}
+
+	return p.ETAs[len(p.ETAs)-1]
+}
+
+// Simulate visits stops in the given order departing at start. The courier
+// waits for a window to open; ok is false if any stop misses all its windows
+// or is reached after deadline.
+func Simulate(start, deadline time.Time, stops []Stop, travel TravelFunc, depart DepartFunc) (plan Plan, ok bool, err error) {
+
+	plan = Plan{
+		Stops:   stops,
+		ETAs:    make([]time.Time, 0, len(stops)),
+		Windows: make([]Window, 0, len(stops)),
+	}
+
+	t := start
+	var prev *Stop
+	for i := range stops {
+		d, err := travel(prev, stops[i])
+		if err != nil {
+			return Plan{}, false, err
+		}
+
+		w, eta, reachable := Arrival(t, d, stops[i].Windows, depart)
+		if !reachable || eta.After(deadline) {
+			return plan, false, nil
+		}
+
+		plan.ETAs = append(plan.ETAs, eta)
+		plan.Windows = append(plan.Windows, w)
+		t = eta
+		prev = &stops[i]
+	}
+
+	return plan, true, nil
+}
+
+// Arrival picks the window with the earliest delivery for a courier free at
+// t and d away. The courier waits for a window to open and departs as depart
+// allows, delivery must land inside the window.
+func Arrival(t time.Time, d time.Duration, windows []Window, depart DepartFunc) (Window, time.Time, bool) {
+	sorted := append([]Window{}, windows...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Start.Before(sorted[j].Start) })
+
+	for _, w := range sorted {
+		departure := t
+		if w.Start.After(departure) {
+			departure = w.Start.In(t.Location())
+		}
+		if depart != nil {
+			var ok bool
+			if departure, ok = depart(departure, d); !ok {
+				continue
+			}
+		}
+
+		eta := departure.Add(d)
+		if !eta.After(w.End) {
+			return w, eta, true
+		}
+	}
+
+	return Window{}, time.Time{}, false
+}
+
+// Optimize returns the earliest finishing feasible plan among the given order,
+// nearest neighbour tour and their 2-opt improvements. ok is false if no
+// feasible plan is found, then stops should be kept as they are.
+func Optimize(start, deadline time.Time, stops []Stop, travel TravelFunc, depart DepartFunc) (best Plan, ok bool, err error) {
+
+	best, ok, err = Simulate(start, deadline, stops, travel, depart)
+	if err != nil {
+		return Plan{}, false, err
+	}
+
+	nn, nnOk, err := nearestNeighbour(start, deadline, stops, travel, depart)
+	if err != nil {
+		return Plan{}, false, err
+	}
+	if nnOk && (!ok || nn.End().Before(best.End())) {
+		best, ok = nn, true
+	}
+
+	if !ok {
+		// 2-opt may still repair the given order
+		best = Plan{Stops: stops}
+	}
+
+	return twoOpt(start, deadline, best, ok, travel, depart)
+}
+
+// nearestNeighbour repeatedly takes the stop delivered soonest from the current one.
+func nearestNeighbour(start, deadline time.Time, stops []Stop, travel TravelFunc, depart DepartFunc) (Plan, bool, error) {
+
+	plan := Plan{}
+	left := append([]Stop{}, stops...)
+
+	t := start
+	var prev *Stop
+	for len(left) > 0 {
+		next := -1
+		var nextETA time.Time
+		var nextWindow Window
+
+		for i := range left {
+			d, err := travel(prev, left[i])
+			if err != nil {
+				return Plan{}, false, err
+			}
+
+			w, eta, reachable := Arrival(t, d, left[i].Windows, depart)
+			if !reachable || eta.After(deadline) {
+				continue
+			}
+			if next == -1 || eta.Before(nextETA) {
+				next, nextETA, nextWindow = i, eta, w
+			}
+		}
+
+		if next == -1 {
+			return Plan{}, false, nil
+		}
+
+		plan.Stops = append(plan.Stops, left[next])
+		plan.ETAs = append(plan.ETAs, nextETA)
+		plan.Windows = append(plan.Windows, nextWindow)
+		prev = &plan.Stops[len(plan.Stops)-1]
+		t = nextETA
+
+		left = append(left[:next], left[next+1:]...)
+	}
+
+	return plan, true, nil
+}
+
+// twoOpt reverses segments of the tour while it finishes earlier. An
+// infeasible tour is replaced by the first feasible candidate.
+func twoOpt(start, deadline time.Time, plan Plan, feasible bool, travel TravelFunc, depart DepartFunc) (Plan, bool, error) {
+
+	n := len(plan.Stops)
+	for pass := 0; pass < MAX_2OPT_PASSES; pass++ {
+		improved := false
+
+		for i := 0; i < n-1; i++ {
+			for j := i + 1; j < n; j++ {
+				candidate := append([]Stop{}, plan.Stops...)
+				for l, r := i, j; l < r; l, r = l+1, r-1 {
+					candidate[l], candidate[r] = candidate[r], candidate[l]
+				}
+
+				p, ok, err := Simulate(start, deadline, candidate, travel, depart)
+				if err != nil {
+					return Plan{}, false, err
+				}
+				if ok && (!feasible || p.End().Before(plan.End())) {
+					plan, feasible = p, true
+					improved = true
+				}
+			}
+		}
+
+		if !improved {
+			break
+		}
+	}
+
+	if !feasible {
+		return Plan{}, false, nil
+	}
+
+	return plan, true, nil
+}
@@ -0,0 +1,283 @@
+package sequence
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+var start = time.Date(2023, 8, 1, 10, 0, 0, 0, time.UTC)
+
+func at(minutes int) time.Time {
+	return start.Add(time.Duration(minutes) * time.Minute)
+}
+
+// lineTravel places stops on a line, a minute per unit, the group starts at 0.
+func lineTravel(positions map[uint64]int) TravelFunc {
+	return func(from *Stop, to Stop) (time.Duration, error) {
+		d := positions[to.ID]
+		if from != nil {
+			d -= positions[from.ID]
+		}
+		if d < 0 {
+			d = -d
+		}
+
+		return time.Duration(d) * time.Minute, nil
+	}
+}
+
+func stop(id uint64, windows ...Window) Stop {
+	if len(windows) == 0 {
+		windows = []Window{{ID: id, Start: at(0), End: at(120)}}
+	}
+
+	return Stop{ID: id, Windows: windows}
+}
+
+func ids(stops []Stop) []uint64 {
+	res := []uint64{}
+	for _, s := range stops {
+		res = append(res, s.ID)
+	}
+
+	return res
+}
+
+func TestOptimize(t *testing.T) {
+	positions := map[uint64]int{1: 1, 2: 2, 3: 3, 4: 10, 5: -1}
+
+	tests := []struct {
+		name     string
+		stops    []Stop
+		deadline time.Time
+		ok       bool
+		order    []uint64
+		end      time.Time
+	}{
+		{
+			name:     "empty",
+			stops:    nil,
+			deadline: at(60),
+			ok:       true,
+			order:    []uint64{},
+		},
+		{
+			name:     "single stop",
+			stops:    []Stop{stop(3)},
+			deadline: at(60),
+			ok:       true,
+			order:    []uint64{3},
+			end:      at(3),
+		},
+		{
+			name:     "back and forth is straightened",
+			stops:    []Stop{stop(3), stop(1), stop(2)},
+			deadline: at(60),
+			ok:       true,
+			order:    []uint64{1, 2, 3},
+			end:      at(3),
+		},
+		{
+			name:     "stop breaching its window in given order goes first",
+			stops:    []Stop{stop(4), stop(1, Window{ID: 1, Start: at(0), End: at(2)})},
+			deadline: at(60),
+			ok:       true,
+			order:    []uint64{1, 4},
+			end:      at(10),
+		},
+		{
+			name:     "courier waits for a window to open",
+			stops:    []Stop{stop(1, Window{ID: 1, Start: at(30), End: at(40)})},
+			deadline: at(60),
+			ok:       true,
+			order:    []uint64{1},
+			end:      at(31),
+		},
+		{
+			name:     "window missed in any order",
+			stops:    []Stop{stop(4, Window{ID: 4, Start: at(0), End: at(5)}), stop(1)},
+			deadline: at(60),
+			ok:       false,
+		},
+		{
+			name:     "deadline breached",
+			stops:    []Stop{stop(4), stop(5)},
+			deadline: at(10),
+			ok:       false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plan, ok, err := Optimize(start, tt.deadline, tt.stops, lineTravel(positions), nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if ok != tt.ok {
+				t.Fatalf("ok = %v, want %v", ok, tt.ok)
+			}
+			if !ok {
+				return
+			}
+
+			if got := ids(plan.Stops); !reflect.DeepEqual(got, tt.order) {
+				t.Errorf("order = %v, want %v", got, tt.order)
+			}
+			if !plan.End().Equal(tt.end) {
+				t.Errorf("end = %v, want %v", plan.End(), tt.end)
+			}
+			if len(plan.ETAs) != len(plan.Stops) || len(plan.Windows) != len(plan.Stops) {
+				t.Errorf("%d ETAs and %d windows for %d stops", len(plan.ETAs), len(plan.Windows), len(plan.Stops))
+			}
+		})
+	}
+}
+
+func TestSimulate(t *testing.T) {
+	positions := map[uint64]int{1: 1, 2: 2, 3: 3}
+
+	tests := []struct {
+		name  string
+		stops []Stop
+		ok    bool
+		etas  []time.Time
+	}{
+		{
+			name:  "empty",
+			stops: nil,
+			ok:    true,
+			etas:  []time.Time{},
+		},
+		{
+			name:  "given order is kept",
+			stops: []Stop{stop(3), stop(1), stop(2)},
+			ok:    true,
+			etas:  []time.Time{at(3), at(5), at(6)},
+		},
+		{
+			name: "the earliest fitting window is taken",
+			stops: []Stop{stop(2,
+				Window{ID: 20, Start: at(0), End: at(1)},
+				Window{ID: 21, Start: at(20), End: at(30)},
+			)},
+			ok:   true,
+			etas: []time.Time{at(22)},
+		},
+		{
+			name:  "stop breaching its window",
+			stops: []Stop{stop(3), stop(1, Window{ID: 1, Start: at(0), End: at(4)})},
+			ok:    false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plan, ok, err := Simulate(start, at(60), tt.stops, lineTravel(positions), nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if ok != tt.ok {
+				t.Fatalf("ok = %v, want %v", ok, tt.ok)
+			}
+			if ok && !reflect.DeepEqual(plan.ETAs, tt.etas) {
+				t.Errorf("ETAs = %v, want %v", plan.ETAs, tt.etas)
+			}
+		})
+	}
+}
+
+func TestNearestNeighbour(t *testing.T) {
+	// greedy takes 1 and 2 first and pays for the way back to 5
+	positions := map[uint64]int{1: 1, 2: 2, 5: -2}
+
+	plan, ok, err := nearestNeighbour(start, at(60), []Stop{stop(2), stop(5), stop(1)}, lineTravel(positions), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !ok {
+		t.Fatal("plan is not feasible")
+	}
+	if got := ids(plan.Stops); !reflect.DeepEqual(got, []uint64{1, 2, 5}) {
+		t.Errorf("order = %v, want [1 2 5]", got)
+	}
+	if !plan.End().Equal(at(6)) {
+		t.Errorf("end = %v, want %v", plan.End(), at(6))
+	}
+}
+
+func TestTwoOpt(t *testing.T) {
+	positions := map[uint64]int{1: 1, 2: 2, 3: 3}
+	travel := lineTravel(positions)
+
+	tests := []struct {
+		name     string
+		stops    []Stop
+		feasible bool
+		ok       bool
+		order    []uint64
+		end      time.Time
+	}{
+		{
+			name:     "crossing tour is uncrossed",
+			stops:    []Stop{stop(3), stop(1), stop(2)},
+			feasible: true,
+			ok:       true,
+			order:    []uint64{1, 2, 3},
+			end:      at(3),
+		},
+		{
+			name:     "infeasible tour is repaired",
+			stops:    []Stop{stop(3), stop(1, Window{ID: 1, Start: at(0), End: at(2)})},
+			feasible: false,
+			ok:       true,
+			order:    []uint64{1, 3},
+			end:      at(3),
+		},
+		{
+			name:     "single stop",
+			stops:    []Stop{stop(2)},
+			feasible: true,
+			ok:       true,
+			order:    []uint64{2},
+			end:      at(2),
+		},
+		{
+			name:     "empty infeasible tour",
+			stops:    nil,
+			feasible: false,
+			ok:       false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plan := Plan{Stops: tt.stops}
+			if tt.feasible {
+				var err error
+				plan, _, err = Simulate(start, at(60), tt.stops, travel, nil)
+				if err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			plan, ok, err := twoOpt(start, at(60), plan, tt.feasible, travel, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if ok != tt.ok {
+				t.Fatalf("ok = %v, want %v", ok, tt.ok)
+			}
+			if !ok {
+				return
+			}
+
+			if got := ids(plan.Stops); !reflect.DeepEqual(got, tt.order) {
+				t.Errorf("order = %v, want %v", got, tt.order)
+			}
+			if !plan.End().Equal(tt.end) {
+				t.Errorf("end = %v, want %v", plan.End(), tt.end)
+			}
+		})
+	}
+}
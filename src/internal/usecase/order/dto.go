@@ -1,12 +1,48 @@
 package order
 
-import "time"
+import (
+	"time"
+
+	"yandex-team.ru/bstask/internal/entity"
+	"yandex-team.ru/bstask/pkg/geo"
+)
 
 type OrderToCreateDTO struct {
 	Weight        float64  `validate:"required"`
 	Regions       int32    `validate:"required"`
 	DeliveryHours []string `validate:"required,unique,each_HH_MM_HH_MM_time_interval"`
 	Cost          uint32   `validate:"required"`
+	Location      *geo.Point
+	// Empty means entity.DEFAULT_ORDER_PRIORITY.
+	Priority string `validate:"omitempty,order_priority"`
+	Deadline *time.Time
+	// Optional size, volume in litres defaults to the box of dimensions.
+	Volume     *float64 `validate:"omitempty,gt=0"`
+	Dimensions *entity.Dimensions
+	Items      []OrderItemToCreateDTO `validate:"omitempty,dive"`
+	Handling   []string               `validate:"omitempty,unique,dive,handling_flag"`
+	// Pickup store, nil for the region depot.
+	StoreID *uint64
+}
+
+type OrderItemToCreateDTO struct {
+	SKU        string  `validate:"required"`
+	Quantity   uint32  `validate:"required,min=1"`
+	UnitWeight float64 `validate:"min=0"`
+}
+
+// OrderRowReader streams orders to import. Next returns io.EOF after the last row,
+// *bulk.RowError rejects only the current row, any other error aborts the import.
+type OrderRowReader interface {
+	Next() (line int, o OrderToCreateDTO, err error)
+}
+
+type OrderPageDTO struct {
+	Orders []entity.Order
+	// Nil on the last page.
+	NextCursor *string
+	// Filled only on request.
+	Total *int64
 }
 
 type OrderToCompleteDTO struct {
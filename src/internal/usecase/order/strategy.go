@@ -0,0 +1,46 @@
+package order
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"time"
+
+	"yandex-team.ru/bstask/internal/usecase/order/action/assign/bydate"
+)
+
+const DEFAULT_ASSIGN_STRATEGY = "bydate"
+
+// AssignStrategy distributes unassigned orders of the day between couriers.
+type AssignStrategy interface {
+	Assign(ctx context.Context, assignDate time.Time) (bydate.AssignResponseGroup, error)
+}
+
+type assignStrategyFactory func(uc *OrderUseCase) AssignStrategy
+
+var assignStrategies = map[string]assignStrategyFactory{
+	DEFAULT_ASSIGN_STRATEGY: func(uc *OrderUseCase) AssignStrategy {
+		return bydate.New(uc.CourierRepo, uc.OrderRepo, uc.DeliveryGroupRepo, uc.RegionRepo, uc.StoreRepo, uc.TravelModel)
+	},
+}
+
+// AssignStrategies returns names of registered strategies.
+func AssignStrategies() []string {
+	names := make([]string, 0, len(assignStrategies))
+	for name := range assignStrategies {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+type AssignOptions struct {
+	// Empty means DEFAULT_ASSIGN_STRATEGY.
+	Strategy string
+	// Compute assignment and roll it back.
+	DryRun bool
+}
+
+// errDryRun rolls back the transaction of a dry run.
+var errDryRun = errors.New("dry run")
@@ -2,41 +2,57 @@ package order
 
 import (
 	"context"
+	"errors"
 	"strings"
 	"time"
 
-	"github.com/avito-tech/go-transaction-manager/trm/manager"
+	"github.com/avito-tech/go-transaction-manager/trm"
 	"gopkg.in/go-playground/validator.v9"
 	"yandex-team.ru/bstask"
 	"yandex-team.ru/bstask/internal/entity"
+	"yandex-team.ru/bstask/internal/metrics"
 	"yandex-team.ru/bstask/internal/repository/repositories"
+	"yandex-team.ru/bstask/internal/usecase/bulk"
 	"yandex-team.ru/bstask/internal/usecase/order/action/assign/bydate"
+	"yandex-team.ru/bstask/pkg/pagination"
+	"yandex-team.ru/bstask/pkg/tracing"
 	validatations "yandex-team.ru/bstask/pkg/validations"
 )
 
 type OrderUseCase struct {
-	trm               *manager.Manager
+	trm               trm.Manager
 	validator         *validator.Validate
 	OrderRepo         *repositories.OrderRepo
 	CourierRepo       *repositories.CourierRepo
 	DeliveryGroupRepo *repositories.DeliveryGroupRepo
+	RegionRepo        *repositories.RegionRepo
+	StoreRepo         *repositories.StoreRepo
+	// Used by assignment to estimate delivery times.
+	TravelModel entity.TravelTimeModel
 }
 
 func New(
-	trm *manager.Manager,
+	trm trm.Manager,
 	ordrepo *repositories.OrderRepo,
 	courrepo *repositories.CourierRepo,
 	ogrepo *repositories.DeliveryGroupRepo,
+	regrepo *repositories.RegionRepo,
+	storerepo *repositories.StoreRepo,
 ) *OrderUseCase {
 
 	v := validator.New()
 	v.RegisterValidation("each_HH_MM_HH_MM_time_interval", validatations.Each_HH_MM_HH_MM_time_interval)
+	v.RegisterValidation("order_priority", order_priority)
+	v.RegisterValidation("handling_flag", handling_flag)
 
 	return &OrderUseCase{
 		trm:               trm,
 		OrderRepo:         ordrepo,
 		CourierRepo:       courrepo,
 		DeliveryGroupRepo: ogrepo,
+		RegionRepo:        regrepo,
+		StoreRepo:         storerepo,
+		TravelModel:       entity.StraightLineTravelTime{},
 		validator:         v,
 	}
 }
@@ -44,43 +60,31 @@ func New(
 func (uc *OrderUseCase) CreateOrders(ctx context.Context, orders []OrderToCreateDTO) (*[]entity.Order, error) {
 	op := "OrderUseCase.CreateOrders"
 
-	toCreate := []repositories.OrderToCreateDTO{}
-	for _, c := range orders {
-		if err := uc.validator.Struct(c); err != nil {
-			return nil, bstask.OpError(op, err)
-		}
+	ctx, span := tracing.Start(ctx, op)
+	defer span.End()
 
-		intervals := []repositories.OrderDeliveryHoursIntervalDTO{}
-		for _, i := range c.DeliveryHours {
-			spl := strings.Split(i, "-")
+	var savedOrders *[]entity.Order
+	err := uc.trm.Do(ctx, func(ctx context.Context) error {
+		catalog, err := uc.RegionRepo.Catalog(ctx)
+		if err != nil {
+			return err
+		}
 
-			startTime, err := time.Parse("15:04", spl[0])
-			if err != nil {
-				return nil, bstask.OpError(op, err)
-			}
+		stores, err := uc.StoreRepo.Catalog(ctx)
+		if err != nil {
+			return err
+		}
 
-			endTime, err := time.Parse("15:04", spl[1])
+		toCreate := []repositories.OrderToCreateDTO{}
+		for _, c := range orders {
+			dto, err := uc.prepareToCreate(catalog, stores, c)
 			if err != nil {
-				return nil, bstask.OpError(op, err)
+				return err
 			}
 
-			intervals = append(intervals, repositories.OrderDeliveryHoursIntervalDTO{
-				StartTime: startTime,
-				EndTime:   endTime,
-			})
+			toCreate = append(toCreate, dto)
 		}
 
-		toCreate = append(toCreate, repositories.OrderToCreateDTO{
-			Weight:        c.Weight,
-			Regions:       c.Regions,
-			DeliveryHours: intervals,
-			Cost:          c.Cost,
-		})
-	}
-
-	var savedOrders *[]entity.Order
-	var err error
-	err = uc.trm.Do(ctx, func(ctx context.Context) error {
 		savedOrders, err = uc.OrderRepo.BatchCreate(ctx, toCreate)
 		return err
 	})
@@ -91,34 +95,331 @@ func (uc *OrderUseCase) CreateOrders(ctx context.Context, orders []OrderToCreate
 	return savedOrders, nil
 }
 
+// Rounding slack when comparing order weight with its items.
+const ITEMS_WEIGHT_TOLERANCE = 1e-6
+
+// prepareToCreate validates order against regions and stores catalogs and converts it for repository.
+func (uc *OrderUseCase) prepareToCreate(catalog *entity.RegionCatalog, stores *entity.StoreCatalog, o OrderToCreateDTO) (repositories.OrderToCreateDTO, error) {
+	if err := uc.validator.Struct(o); err != nil {
+		return repositories.OrderToCreateDTO{}, err
+	}
+	if err := catalog.CheckActive(o.Regions); err != nil {
+		return repositories.OrderToCreateDTO{}, err
+	}
+	if o.Location != nil && !o.Location.Valid() {
+		return repositories.OrderToCreateDTO{}, &bstask.Error{
+			Code:    bstask.EINVALID,
+			Message: "invalid order coordinates",
+		}
+	}
+	if err := stores.CheckExists(o.StoreID); err != nil {
+		return repositories.OrderToCreateDTO{}, err
+	}
+	if store, ok := stores.Get(o.StoreID); ok && store.Region != o.Regions {
+		return repositories.OrderToCreateDTO{}, &bstask.Error{
+			Code:    bstask.EINVALID,
+			Message: "store must be in the order region",
+			Fields:  map[string]interface{}{"store_id": *o.StoreID},
+		}
+	}
+	if o.Dimensions != nil && !o.Dimensions.Valid() {
+		return repositories.OrderToCreateDTO{}, &bstask.Error{
+			Code:    bstask.EINVALID,
+			Message: "order dimensions must be positive",
+		}
+	}
+
+	volume := o.Volume
+	if volume == nil && o.Dimensions != nil {
+		v := o.Dimensions.Volume()
+		volume = &v
+	}
+
+	intervals := []repositories.OrderDeliveryHoursIntervalDTO{}
+	for _, i := range o.DeliveryHours {
+		spl := strings.Split(i, "-")
+
+		startTime, err := time.Parse("15:04", spl[0])
+		if err != nil {
+			return repositories.OrderToCreateDTO{}, err
+		}
+
+		endTime, err := time.Parse("15:04", spl[1])
+		if err != nil {
+			return repositories.OrderToCreateDTO{}, err
+		}
+
+		intervals = append(intervals, repositories.OrderDeliveryHoursIntervalDTO{
+			StartTime: startTime,
+			EndTime:   endTime,
+		})
+	}
+
+	priority := entity.DEFAULT_ORDER_PRIORITY
+	if o.Priority != "" {
+		priority = entity.OrderPriority(o.Priority)
+	}
+
+	items := []repositories.OrderItemDTO{}
+	var itemsWeight float64
+	for _, i := range o.Items {
+		items = append(items, repositories.OrderItemDTO{
+			SKU:        i.SKU,
+			Quantity:   i.Quantity,
+			UnitWeight: i.UnitWeight,
+		})
+		itemsWeight += float64(i.Quantity) * i.UnitWeight
+	}
+	if itemsWeight > o.Weight+ITEMS_WEIGHT_TOLERANCE {
+		return repositories.OrderToCreateDTO{}, &bstask.Error{
+			Code:    bstask.EINVALID,
+			Message: "order weight is less than weight of its items",
+			Fields:  map[string]interface{}{"weight": o.Weight, "items_weight": itemsWeight},
+		}
+	}
+
+	handling := []entity.HandlingFlag{}
+	for _, f := range o.Handling {
+		handling = append(handling, entity.HandlingFlag(f))
+	}
+
+	return repositories.OrderToCreateDTO{
+		Weight:        o.Weight,
+		Regions:       o.Regions,
+		DeliveryHours: intervals,
+		Cost:          o.Cost,
+		Location:      o.Location,
+		Priority:      priority,
+		Deadline:      o.Deadline,
+		Volume:        volume,
+		Dimensions:    o.Dimensions,
+		Items:         items,
+		Handling:      handling,
+		StoreID:       o.StoreID,
+	}, nil
+}
+
+// Import streams orders from rows and saves them by chunks.
+// Rejected rows are reported in result with their line numbers.
+func (uc *OrderUseCase) Import(ctx context.Context, rows OrderRowReader, mode bulk.Mode) (*bulk.Result, error) {
+	const op = "OrderUseCase.Import"
+
+	ctx, span := tracing.Start(ctx, op)
+	defer span.End()
+
+	res, err := bulk.Import(ctx, uc.trm, mode, rows.Next,
+		func(ctx context.Context) (func(OrderToCreateDTO) (repositories.OrderToCreateDTO, error), error) {
+			catalog, err := uc.RegionRepo.Catalog(ctx)
+			if err != nil {
+				return nil, err
+			}
+
+			stores, err := uc.StoreRepo.Catalog(ctx)
+			if err != nil {
+				return nil, err
+			}
+
+			return func(o OrderToCreateDTO) (repositories.OrderToCreateDTO, error) {
+				return uc.prepareToCreate(catalog, stores, o)
+			}, nil
+		},
+		func(ctx context.Context, rows []repositories.OrderToCreateDTO) error {
+			_, err := uc.OrderRepo.BatchCreate(ctx, rows)
+			return err
+		},
+	)
+	if err != nil {
+		tracing.RecordError(span, err)
+		return nil, bstask.OpError(op, err)
+	}
+
+	return res, nil
+}
+
 func (uc *OrderUseCase) GetById(ctx context.Context, id uint64) (*entity.Order, error) {
 	const op = "OrderUseCase.GetById"
 
+	ctx, span := tracing.Start(ctx, op)
+	defer span.End()
+
 	order, err := uc.OrderRepo.FindById(ctx, id)
 	if err != nil {
 		return nil, bstask.OpError(op, err)
 	}
 
-	return order, nil
+	res := []entity.Order{*order}
+	if err := uc.localize(ctx, res); err != nil {
+		return nil, bstask.OpError(op, err)
+	}
+
+	return &res[0], nil
 }
 
-func (uc *OrderUseCase) PaginatedGetAll(ctx context.Context, offset, limit int32) (*[]entity.Order, error) {
+// localize shows order times in local time of their regions.
+func (uc *OrderUseCase) localize(ctx context.Context, orders []entity.Order) error {
+	catalog, err := uc.RegionRepo.Catalog(ctx)
+	if err != nil {
+		return err
+	}
+	catalog.LocalizeOrders(orders)
+
+	return nil
+}
+
+func (uc *OrderUseCase) PaginatedGetAll(ctx context.Context, query repositories.OrderQuery, offset, limit int32) (*[]entity.Order, error) {
 	op := "OrderUseCase.PaginatedGetAll"
 
-	couriers, err := uc.OrderRepo.PaginatedFetchAll(ctx, offset, limit)
+	ctx, span := tracing.Start(ctx, op)
+	defer span.End()
+
+	if err := validateOrderQuery(op, query); err != nil {
+		return nil, err
+	}
+
+	orders, err := uc.OrderRepo.PaginatedFetchAll(ctx, query, offset, limit)
+	if err != nil {
+		return nil, bstask.OpError(op, err)
+	}
+
+	if err := uc.localize(ctx, *orders); err != nil {
+		return nil, bstask.OpError(op, err)
+	}
+
+	return orders, nil
+}
+
+// KeysetGetAll returns page of orders following cursor in the order of query.
+func (uc *OrderUseCase) KeysetGetAll(ctx context.Context, query repositories.OrderQuery, cursor string, limit int32, withTotal bool) (*OrderPageDTO, error) {
+	const op = "OrderUseCase.KeysetGetAll"
+
+	ctx, span := tracing.Start(ctx, op)
+	defer span.End()
+
+	if err := validateOrderQuery(op, query); err != nil {
+		return nil, err
+	}
+
+	after, err := pagination.Decode(cursor)
+	if err != nil {
+		return nil, &bstask.Error{Op: op, Code: bstask.EINVALID, Err: err, Message: "invalid cursor"}
+	}
+
+	// one extra row tells if there is a next page
+	orders, err := uc.OrderRepo.FetchAfter(ctx, query, after.Values, after.ID, int(limit)+1)
 	if err != nil {
 		return nil, bstask.OpError(op, err)
 	}
 
-	return couriers, nil
+	res := OrderPageDTO{Orders: *orders}
+	if len(res.Orders) > int(limit) {
+		res.Orders = res.Orders[:limit]
+		if limit > 0 {
+			last := res.Orders[limit-1]
+			next := pagination.Cursor{
+				ID:     last.ID,
+				Values: repositories.OrderSortValues(query.Sort, last),
+			}.Encode()
+			res.NextCursor = &next
+		}
+	}
+
+	if withTotal {
+		total, err := uc.OrderRepo.Count(ctx, query.Filter)
+		if err != nil {
+			return nil, bstask.OpError(op, err)
+		}
+		res.Total = &total
+	}
+
+	if err := uc.localize(ctx, res.Orders); err != nil {
+		return nil, bstask.OpError(op, err)
+	}
+
+	return &res, nil
 }
 
-func (uc *OrderUseCase) Complete(ctx context.Context, toComplete []OrderToCompleteDTO) (*[]entity.Order, error) {
-	const op = "OrderUseCase.Complete"
+func validateOrderQuery(op string, query repositories.OrderQuery) error {
+	seen := map[string]bool{}
+	for _, s := range query.Sort {
+		if !repositories.IsValidOrderSortField(s.Field) || seen[s.Field] {
+			return &bstask.Error{
+				Op:      op,
+				Code:    bstask.EINVALID,
+				Message: "invalid sort field",
+				Fields:  map[string]interface{}{"field": s.Field},
+			}
+		}
+		seen[s.Field] = true
+	}
 
-	res := []entity.Order{}
+	f := query.Filter
+	// a window ending before its start crosses midnight
+	if f.DeliveryWindow != nil && f.DeliveryWindow.Start.Equal(f.DeliveryWindow.End) {
+		return &bstask.Error{
+			Op:      op,
+			Code:    bstask.EINVALID,
+			Message: "delivery window must not be empty",
+		}
+	}
+
+	return nil
+}
+
+// Export streams orders matching filter to fn inside one transaction,
+// so the server-side cursor sees a consistent snapshot.
+func (uc *OrderUseCase) Export(ctx context.Context, filter repositories.OrderExportFilter, fn func(repositories.OrderExportDTO) error) error {
+	const op = "OrderUseCase.Export"
+
+	ctx, span := tracing.Start(ctx, op)
+	defer span.End()
+
+	if filter.From != nil && filter.To != nil && filter.From.After(*filter.To) {
+		return &bstask.Error{
+			Op:      op,
+			Code:    bstask.EINVALID,
+			Message: ":from is after :to param",
+		}
+	}
+
+	if filter.Status != "" && !entity.IsValidOrderStatus(string(filter.Status)) {
+		return &bstask.Error{
+			Op:      op,
+			Code:    bstask.EINVALID,
+			Message: "unknown order status",
+			Fields:  map[string]interface{}{"status": filter.Status},
+		}
+	}
 
 	err := uc.trm.Do(ctx, func(ctx context.Context) error {
+		return uc.OrderRepo.Export(ctx, filter, fn)
+	})
+	if err != nil {
+		tracing.RecordError(span, err)
+		return bstask.OpError(op, err)
+	}
+
+	return nil
+}
+
+func (uc *OrderUseCase) Complete(ctx context.Context, toComplete []OrderToCompleteDTO) (res *[]entity.Order, err error) {
+	const op = "OrderUseCase.Complete"
+
+	ctx, span := tracing.Start(ctx, op)
+	defer span.End()
+
+	defer func() {
+		tracing.RecordError(span, err)
+		metrics.OrdersCompleteTotal.WithLabelValues(bstask.ErrorCode(err).String()).Inc()
+	}()
+
+	completed := []entity.Order{}
+
+	err = uc.trm.Do(ctx, func(ctx context.Context) error {
+		catalog, err := uc.RegionRepo.Catalog(ctx)
+		if err != nil {
+			return bstask.OpError(op, err)
+		}
+
 		for _, i := range toComplete {
 			if err := uc.validator.Struct(i); err != nil {
 				return &bstask.Error{Op: op, Err: err, Code: bstask.EINVALID}
@@ -134,11 +435,21 @@ func (uc *OrderUseCase) Complete(ctx context.Context, toComplete []OrderToComple
 				return bstask.OpError(op, err)
 			}
 
-			wh, err := uc.CourierRepo.WorkingIntervalForDelivery(ctx, courierEntity.ID, i.CompleteTime, i.CompleteTime)
+			// working hours are local clock of the courier regions
+			completeTime := i.CompleteTime.In(catalog.LocationOf(courierEntity.Regions))
+
+			wh, err := uc.CourierRepo.WorkingIntervalForDelivery(ctx, courierEntity.ID, completeTime, completeTime)
 			if err != nil {
 				return bstask.OpError(op, err)
 			}
 
+			// an overnight shift completed after midnight is assigned the day before
+			assignDate := completeTime
+			_, prevShiftEnd := entity.IntervalOnDate(completeTime.AddDate(0, 0, -1), time.Time(wh.StartTime), time.Time(wh.EndTime))
+			if !completeTime.After(prevShiftEnd) {
+				assignDate = assignDate.AddDate(0, 0, -1)
+			}
+
 			duration, err := entity.NextDeliveryTimeInRegion(courierEntity.CourierType, 0)
 			if err != nil {
 				return bstask.OpError(op, err)
@@ -148,7 +459,7 @@ func (uc *OrderUseCase) Complete(ctx context.Context, toComplete []OrderToComple
 				ctx,
 				courierEntity.ID,
 				wh.ID,
-				i.CompleteTime,
+				assignDate,
 				i.CompleteTime.Add(-duration),
 				i.CompleteTime,
 			)
@@ -172,14 +483,16 @@ func (uc *OrderUseCase) Complete(ctx context.Context, toComplete []OrderToComple
 				DeliveryGroupID: deliveryGroupEntity.ID,
 				Cost:            orderEntity.Cost,
 				CompleteTime:    i.CompleteTime.UTC(),
+				Delivered:       true,
 			})
 
 			if err != nil {
 				return bstask.OpError(op, err)
 			}
 
-			res = append(res, *orderEntity)
+			completed = append(completed, *orderEntity)
 		}
+		catalog.LocalizeOrders(completed)
 
 		return nil
 	})
@@ -188,18 +501,69 @@ func (uc *OrderUseCase) Complete(ctx context.Context, toComplete []OrderToComple
 		return nil, err
 	}
 
-	return &res, nil
+	return &completed, nil
 }
 
 func (uc *OrderUseCase) AssignByDate(ctx context.Context, assignDate time.Time) (bydate.AssignResponseGroup, error) {
-	action := bydate.New(uc.CourierRepo, uc.OrderRepo, uc.DeliveryGroupRepo)
+	return uc.Assign(ctx, assignDate, AssignOptions{})
+}
+
+func (uc *OrderUseCase) Assign(ctx context.Context, assignDate time.Time, opts AssignOptions) (bydate.AssignResponseGroup, error) {
+	const op = "OrderUseCase.AssignByDate"
+
+	ctx, span := tracing.Start(ctx, op)
+	defer span.End()
+
+	if opts.Strategy == "" {
+		opts.Strategy = DEFAULT_ASSIGN_STRATEGY
+	}
+	newStrategy, ok := assignStrategies[opts.Strategy]
+	if !ok {
+		return bydate.AssignResponseGroup{}, &bstask.Error{
+			Op:      op,
+			Code:    bstask.EINVALID,
+			Message: "unknown assign strategy",
+			Fields: map[string]interface{}{
+				"strategy":  opts.Strategy,
+				"available": AssignStrategies(),
+			},
+		}
+	}
+
+	strategy := newStrategy(uc)
+	start := time.Now()
 
 	var res bydate.AssignResponseGroup
 	var err error
 	err = uc.trm.Do(ctx, func(ctx context.Context) error {
-		res, err = action.Assign(ctx, assignDate)
+		res, err = strategy.Assign(ctx, assignDate)
+		if err == nil && opts.DryRun {
+			return errDryRun
+		}
 		return err
 	})
+	if err == nil || errors.Is(err, errDryRun) {
+		catalog, cerr := uc.RegionRepo.Catalog(ctx)
+		if cerr != nil {
+			return bydate.AssignResponseGroup{}, bstask.OpError(op, cerr)
+		}
+		for _, c := range res.Couriers {
+			for _, g := range c.Orders {
+				catalog.LocalizeOrders(g.Orders)
+			}
+		}
+		catalog.LocalizeOrders(res.SLABreaching)
+	}
+	if errors.Is(err, errDryRun) {
+		return res, nil
+	}
+
+	tracing.RecordError(span, err)
+	metrics.AssignRunsTotal.WithLabelValues(bstask.ErrorCode(err).String()).Inc()
+	if err == nil {
+		metrics.AssignRunDuration.Observe(time.Since(start).Seconds())
+		metrics.AssignRunOrdersAssigned.Set(float64(res.OrdersCount()))
+	}
 
 	return res, err
 }
@@ -0,0 +1,34 @@
+package order
+
+import (
+	"reflect"
+
+	"gopkg.in/go-playground/validator.v9"
+	"yandex-team.ru/bstask/internal/entity"
+)
+
+func order_priority(fl validator.FieldLevel) bool {
+	if fl.Field().Type().Kind() != reflect.String {
+		return false
+	}
+
+	s, ok := fl.Field().Interface().(string)
+	if !ok {
+		return false
+	}
+
+	return entity.IsValidOrderPriority(s)
+}
+
+func handling_flag(fl validator.FieldLevel) bool {
+	if fl.Field().Type().Kind() != reflect.String {
+		return false
+	}
+
+	s, ok := fl.Field().Interface().(string)
+	if !ok {
+		return false
+	}
+
+	return entity.IsValidHandlingFlag(s)
+}
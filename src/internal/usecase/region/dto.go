@@ -0,0 +1,14 @@
+package region
+
+import (
+	"yandex-team.ru/bstask/pkg/geo"
+)
+
+type RegionDTO struct {
+	ID         int32  `validate:"required,min=1"`
+	Name       string `validate:"required,max=255"`
+	Active     bool
+	Neighbours []int32 `validate:"unique,dive,min=1"`
+	Depot      *geo.Point
+	TimeZone   string `validate:"required"`
+}
@@ -0,0 +1,234 @@
+package region
+
+import (
+	"context"
+
+	"github.com/avito-tech/go-transaction-manager/trm"
+	"gopkg.in/go-playground/validator.v9"
+	"yandex-team.ru/bstask"
+	"yandex-team.ru/bstask/internal/entity"
+	"yandex-team.ru/bstask/internal/repository/repositories"
+	"yandex-team.ru/bstask/pkg/tracing"
+)
+
+type RegionUseCase struct {
+	trm         trm.Manager
+	validator   *validator.Validate
+	RegionRepo  *repositories.RegionRepo
+	CourierRepo *repositories.CourierRepo
+}
+
+func New(trm trm.Manager, regrepo *repositories.RegionRepo, crepo *repositories.CourierRepo) *RegionUseCase {
+	return &RegionUseCase{
+		trm:         trm,
+		validator:   validator.New(),
+		RegionRepo:  regrepo,
+		CourierRepo: crepo,
+	}
+}
+
+func (uc *RegionUseCase) CreateRegions(ctx context.Context, regions []RegionDTO) (*[]entity.Region, error) {
+	const op = "RegionUseCase.CreateRegions"
+
+	ctx, span := tracing.Start(ctx, op)
+	defer span.End()
+
+	for _, r := range regions {
+		if err := uc.validate(op, r); err != nil {
+			return nil, err
+		}
+	}
+
+	var saved *[]entity.Region
+	err := uc.trm.Do(ctx, func(ctx context.Context) error {
+		catalog, err := uc.RegionRepo.Catalog(ctx)
+		if err != nil {
+			return err
+		}
+
+		known := map[int32]bool{}
+		for _, r := range regions {
+			if _, ok := catalog.Get(r.ID); ok || known[r.ID] {
+				return &bstask.Error{
+					Op:      op,
+					Code:    bstask.EALREADYEXISTS,
+					Message: "region already exists",
+					Fields:  map[string]interface{}{"region_id": r.ID},
+				}
+			}
+			known[r.ID] = true
+		}
+
+		toCreate := []repositories.RegionDTO{}
+		for _, r := range regions {
+			if err := checkNeighbours(op, catalog, known, r); err != nil {
+				return err
+			}
+			toCreate = append(toCreate, repositories.RegionDTO(r))
+		}
+
+		saved, err = uc.RegionRepo.BatchCreate(ctx, toCreate)
+		return err
+	})
+	if err != nil {
+		return nil, bstask.OpError(op, err)
+	}
+
+	return saved, nil
+}
+
+func (uc *RegionUseCase) Update(ctx context.Context, r RegionDTO) (*entity.Region, error) {
+	const op = "RegionUseCase.Update"
+
+	ctx, span := tracing.Start(ctx, op)
+	defer span.End()
+
+	if err := uc.validate(op, r); err != nil {
+		return nil, err
+	}
+
+	var saved *entity.Region
+	err := uc.trm.Do(ctx, func(ctx context.Context) error {
+		catalog, err := uc.RegionRepo.Catalog(ctx)
+		if err != nil {
+			return err
+		}
+
+		if err := checkNeighbours(op, catalog, nil, r); err != nil {
+			return err
+		}
+
+		if current, ok := catalog.Get(r.ID); ok && current.TimeZone != r.TimeZone {
+			if err := uc.checkCourierTimeZones(ctx, op, r); err != nil {
+				return err
+			}
+		}
+
+		saved, err = uc.RegionRepo.Update(ctx, repositories.RegionDTO(r))
+		return err
+	})
+	if err != nil {
+		return nil, bstask.OpError(op, err)
+	}
+
+	return saved, nil
+}
+
+func (uc *RegionUseCase) Delete(ctx context.Context, id int32) error {
+	const op = "RegionUseCase.Delete"
+
+	ctx, span := tracing.Start(ctx, op)
+	defer span.End()
+
+	err := uc.trm.Do(ctx, func(ctx context.Context) error {
+		return uc.RegionRepo.Delete(ctx, id)
+	})
+	if err != nil {
+		return bstask.OpError(op, err)
+	}
+
+	return nil
+}
+
+func (uc *RegionUseCase) GetById(ctx context.Context, id int32) (*entity.Region, error) {
+	const op = "RegionUseCase.GetById"
+
+	ctx, span := tracing.Start(ctx, op)
+	defer span.End()
+
+	region, err := uc.RegionRepo.FindById(ctx, id)
+	if err != nil {
+		return nil, bstask.OpError(op, err)
+	}
+
+	return region, nil
+}
+
+func (uc *RegionUseCase) GetAll(ctx context.Context) (*[]entity.Region, error) {
+	const op = "RegionUseCase.GetAll"
+
+	ctx, span := tracing.Start(ctx, op)
+	defer span.End()
+
+	regions, err := uc.RegionRepo.FetchAll(ctx)
+	if err != nil {
+		return nil, bstask.OpError(op, err)
+	}
+
+	return regions, nil
+}
+
+func (uc *RegionUseCase) validate(op string, r RegionDTO) error {
+	if err := uc.validator.Struct(r); err != nil {
+		return bstask.ErrorWithCode(bstask.OpError(op, err), bstask.EINVALID)
+	}
+	if r.Depot != nil && !r.Depot.Valid() {
+		return &bstask.Error{
+			Op:      op,
+			Code:    bstask.EINVALID,
+			Message: "invalid depot coordinates",
+			Fields:  map[string]interface{}{"region_id": r.ID},
+		}
+	}
+	if _, err := entity.LoadTimeZone(r.TimeZone); err != nil {
+		return bstask.OpError(op, err)
+	}
+
+	return nil
+}
+
+// checkCourierTimeZones keeps couriers working in the region within one time
+// zone: the zone of the region can't change while its couriers also work in
+// regions left in the old zone.
+func (uc *RegionUseCase) checkCourierTimeZones(ctx context.Context, op string, r RegionDTO) error {
+	regions, err := uc.RegionRepo.FetchAll(ctx)
+	if err != nil {
+		return err
+	}
+
+	updated := []entity.Region{}
+	for _, region := range *regions {
+		if region.ID == r.ID {
+			region.TimeZone = r.TimeZone
+		}
+		updated = append(updated, region)
+	}
+	catalog := entity.NewRegionCatalog(updated)
+
+	sets, err := uc.CourierRepo.DistinctRegionSets(ctx, repositories.CourierFilter{Region: &r.ID})
+	if err != nil {
+		return err
+	}
+
+	for _, set := range sets {
+		if err := catalog.CheckSameTimeZone(set...); err != nil {
+			return &bstask.Error{
+				Op:      op,
+				Code:    bstask.EINVALID,
+				Err:     err,
+				Message: "couriers of the region also work in regions of another time zone",
+				Fields:  map[string]interface{}{"region_id": r.ID, "time_zone": r.TimeZone, "regions": set},
+			}
+		}
+	}
+
+	return nil
+}
+
+// checkNeighbours requires neighbours of r to be other regions,
+// existing in catalog or created together with r.
+func checkNeighbours(op string, catalog *entity.RegionCatalog, created map[int32]bool, r RegionDTO) error {
+	for _, n := range r.Neighbours {
+		_, exists := catalog.Get(n)
+		if n == r.ID || !(exists || created[n]) {
+			return &bstask.Error{
+				Op:      op,
+				Code:    bstask.EINVALID,
+				Message: "neighbour must be another existing region",
+				Fields:  map[string]interface{}{"region_id": r.ID, "neighbour": n},
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,13 @@
+package store
+
+import (
+	"yandex-team.ru/bstask/pkg/geo"
+)
+
+type StoreDTO struct {
+	ID            uint64
+	Name          string `validate:"required,max=255"`
+	Region        int32  `validate:"required,min=1"`
+	Location      geo.Point
+	PickupMinutes uint32 `validate:"max=1440"`
+}
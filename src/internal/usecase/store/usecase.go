@@ -0,0 +1,156 @@
+package store
+
+import (
+	"context"
+
+	"github.com/avito-tech/go-transaction-manager/trm"
+	"gopkg.in/go-playground/validator.v9"
+	"yandex-team.ru/bstask"
+	"yandex-team.ru/bstask/internal/entity"
+	"yandex-team.ru/bstask/internal/repository/repositories"
+	"yandex-team.ru/bstask/pkg/tracing"
+)
+
+type StoreUseCase struct {
+	trm        trm.Manager
+	validator  *validator.Validate
+	StoreRepo  *repositories.StoreRepo
+	RegionRepo *repositories.RegionRepo
+}
+
+func New(trm trm.Manager, storerepo *repositories.StoreRepo, regrepo *repositories.RegionRepo) *StoreUseCase {
+	return &StoreUseCase{
+		trm:        trm,
+		validator:  validator.New(),
+		StoreRepo:  storerepo,
+		RegionRepo: regrepo,
+	}
+}
+
+func (uc *StoreUseCase) CreateStores(ctx context.Context, stores []StoreDTO) (*[]entity.Store, error) {
+	const op = "StoreUseCase.CreateStores"
+
+	ctx, span := tracing.Start(ctx, op)
+	defer span.End()
+
+	for _, s := range stores {
+		if err := uc.validate(op, s); err != nil {
+			return nil, err
+		}
+	}
+
+	var saved *[]entity.Store
+	err := uc.trm.Do(ctx, func(ctx context.Context) error {
+		catalog, err := uc.RegionRepo.Catalog(ctx)
+		if err != nil {
+			return err
+		}
+
+		toCreate := []repositories.StoreDTO{}
+		for _, s := range stores {
+			if err := catalog.CheckActive(s.Region); err != nil {
+				return err
+			}
+			toCreate = append(toCreate, repositories.StoreDTO(s))
+		}
+
+		saved, err = uc.StoreRepo.BatchCreate(ctx, toCreate)
+		return err
+	})
+	if err != nil {
+		return nil, bstask.OpError(op, err)
+	}
+
+	return saved, nil
+}
+
+func (uc *StoreUseCase) Update(ctx context.Context, s StoreDTO) (*entity.Store, error) {
+	const op = "StoreUseCase.Update"
+
+	ctx, span := tracing.Start(ctx, op)
+	defer span.End()
+
+	if err := uc.validate(op, s); err != nil {
+		return nil, err
+	}
+
+	var saved *entity.Store
+	err := uc.trm.Do(ctx, func(ctx context.Context) error {
+		catalog, err := uc.RegionRepo.Catalog(ctx)
+		if err != nil {
+			return err
+		}
+
+		if err := catalog.CheckActive(s.Region); err != nil {
+			return err
+		}
+
+		saved, err = uc.StoreRepo.Update(ctx, repositories.StoreDTO(s))
+		return err
+	})
+	if err != nil {
+		return nil, bstask.OpError(op, err)
+	}
+
+	return saved, nil
+}
+
+func (uc *StoreUseCase) Delete(ctx context.Context, id uint64) error {
+	const op = "StoreUseCase.Delete"
+
+	ctx, span := tracing.Start(ctx, op)
+	defer span.End()
+
+	err := uc.trm.Do(ctx, func(ctx context.Context) error {
+		return uc.StoreRepo.Delete(ctx, id)
+	})
+	if err != nil {
+		return bstask.OpError(op, err)
+	}
+
+	return nil
+}
+
+func (uc *StoreUseCase) GetById(ctx context.Context, id uint64) (*entity.Store, error) {
+	const op = "StoreUseCase.GetById"
+
+	ctx, span := tracing.Start(ctx, op)
+	defer span.End()
+
+	store, err := uc.StoreRepo.FindById(ctx, id)
+	if err != nil {
+		return nil, bstask.OpError(op, err)
+	}
+
+	return store, nil
+}
+
+func (uc *StoreUseCase) GetAll(ctx context.Context) (*[]entity.Store, error) {
+	const op = "StoreUseCase.GetAll"
+
+	ctx, span := tracing.Start(ctx, op)
+	defer span.End()
+
+	stores, err := uc.StoreRepo.FetchAll(ctx)
+	if err != nil {
+		return nil, bstask.OpError(op, err)
+	}
+
+	return stores, nil
+}
+
+func (uc *StoreUseCase) validate(op string, s StoreDTO) error {
+	if err := uc.validator.Struct(s); err != nil {
+		return bstask.ErrorWithCode(bstask.OpError(op, err), bstask.EINVALID)
+	}
+	if !s.Location.Valid() {
+		return &bstask.Error{
+			Op:      op,
+			Code:    bstask.EINVALID,
+			Message: "invalid store coordinates",
+			Fields:  map[string]interface{}{"name": s.Name},
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,2 @@
+ALTER TABLE public.orders
+    DROP COLUMN IF EXISTS delivered_time;
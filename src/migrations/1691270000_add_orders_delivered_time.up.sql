@@ -0,0 +1,7 @@
+ALTER TABLE public.orders
+    ADD COLUMN IF NOT EXISTS delivered_time timestamp with time zone;
+
+-- before assignment planned completion every completion was a delivery
+UPDATE public.orders
+    SET delivered_time = completed_time
+    WHERE completed_time IS NOT NULL;
@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS public.order_delivery_hours_order_id_idx;
+
+DROP INDEX IF EXISTS public.orders_completed_time_idx;
+
+DROP INDEX IF EXISTS public.orders_delivery_group_id_idx;
+
+DROP INDEX IF EXISTS public.orders_regions_weight_idx;
@@ -0,0 +1,7 @@
+CREATE INDEX IF NOT EXISTS orders_regions_weight_idx ON public.orders (regions, weight);
+
+CREATE INDEX IF NOT EXISTS orders_delivery_group_id_idx ON public.orders (delivery_group_id);
+
+CREATE INDEX IF NOT EXISTS orders_completed_time_idx ON public.orders (completed_time);
+
+CREATE INDEX IF NOT EXISTS order_delivery_hours_order_id_idx ON public.order_delivery_hours (order_id);
@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS public.courier_working_hours_courier_id_idx;
+
+DROP INDEX IF EXISTS public.couriers_courier_type_idx;
+
+DROP INDEX IF EXISTS public.couriers_regions_gin_idx;
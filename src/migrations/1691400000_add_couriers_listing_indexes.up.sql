@@ -0,0 +1,5 @@
+CREATE INDEX IF NOT EXISTS couriers_regions_gin_idx ON public.couriers USING GIN (regions);
+
+CREATE INDEX IF NOT EXISTS couriers_courier_type_idx ON public.couriers (courier_type);
+
+CREATE INDEX IF NOT EXISTS courier_working_hours_courier_id_idx ON public.courier_working_hours (courier_id, start_time, end_time);
@@ -0,0 +1,10 @@
+CREATE TABLE IF NOT EXISTS public.regions
+(
+    id integer NOT NULL,
+    name text COLLATE pg_catalog."default" NOT NULL,
+    active boolean NOT NULL DEFAULT true,
+    neighbours integer[] NOT NULL DEFAULT '{}',
+    CONSTRAINT regions_pkey PRIMARY KEY (id)
+)
+
+TABLESPACE pg_default;
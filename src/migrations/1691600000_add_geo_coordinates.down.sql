@@ -0,0 +1,7 @@
+ALTER TABLE public.regions
+    DROP COLUMN IF EXISTS depot_lon,
+    DROP COLUMN IF EXISTS depot_lat;
+
+ALTER TABLE public.orders
+    DROP COLUMN IF EXISTS lon,
+    DROP COLUMN IF EXISTS lat;
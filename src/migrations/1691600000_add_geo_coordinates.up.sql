@@ -0,0 +1,7 @@
+ALTER TABLE public.orders
+    ADD COLUMN IF NOT EXISTS lat double precision,
+    ADD COLUMN IF NOT EXISTS lon double precision;
+
+ALTER TABLE public.regions
+    ADD COLUMN IF NOT EXISTS depot_lat double precision,
+    ADD COLUMN IF NOT EXISTS depot_lon double precision;
@@ -0,0 +1,3 @@
+ALTER TABLE public.orders
+    DROP COLUMN IF EXISTS eta,
+    DROP COLUMN IF EXISTS delivery_sequence;
@@ -0,0 +1,3 @@
+ALTER TABLE public.orders
+    ADD COLUMN IF NOT EXISTS delivery_sequence integer,
+    ADD COLUMN IF NOT EXISTS eta timestamptz;
@@ -0,0 +1,3 @@
+ALTER TABLE public.orders
+    DROP CONSTRAINT IF EXISTS fk_orders_chosen_delivery_hours,
+    DROP COLUMN IF EXISTS delivery_hours_id;
@@ -0,0 +1,6 @@
+ALTER TABLE public.orders
+    ADD COLUMN IF NOT EXISTS delivery_hours_id bigint,
+    ADD CONSTRAINT fk_orders_chosen_delivery_hours FOREIGN KEY (delivery_hours_id)
+        REFERENCES public.order_delivery_hours (id) MATCH SIMPLE
+        ON UPDATE CASCADE
+        ON DELETE SET NULL;
@@ -0,0 +1,2 @@
+ALTER TABLE public.regions
+    DROP COLUMN IF EXISTS time_zone;
@@ -0,0 +1,2 @@
+ALTER TABLE public.regions
+    ADD COLUMN IF NOT EXISTS time_zone text NOT NULL DEFAULT 'UTC';
@@ -0,0 +1,7 @@
+DROP TABLE IF EXISTS public.courier_breaks;
+
+DROP SEQUENCE IF EXISTS courier_breaks_id_seq;
+
+ALTER TABLE public.couriers
+    DROP COLUMN IF EXISTS max_continuous_driving_minutes,
+    DROP COLUMN IF EXISTS max_deliveries_per_shift;
@@ -0,0 +1,22 @@
+ALTER TABLE public.couriers
+    ADD COLUMN IF NOT EXISTS max_deliveries_per_shift integer,
+    ADD COLUMN IF NOT EXISTS max_continuous_driving_minutes integer;
+
+CREATE SEQUENCE IF NOT EXISTS courier_breaks_id_seq start 1 increment 1;
+
+CREATE TABLE IF NOT EXISTS public.courier_breaks
+(
+    id bigint NOT NULL DEFAULT nextval('courier_breaks_id_seq'::regclass),
+    courier_id bigint,
+    start_time time without time zone,
+    end_time time without time zone,
+    CONSTRAINT courier_breaks_pkey PRIMARY KEY (id),
+    CONSTRAINT fk_couriers_breaks FOREIGN KEY (courier_id)
+        REFERENCES public.couriers (id) MATCH SIMPLE
+        ON UPDATE CASCADE
+        ON DELETE CASCADE
+)
+
+TABLESPACE pg_default;
+
+CREATE INDEX IF NOT EXISTS courier_breaks_courier_id_idx ON public.courier_breaks (courier_id);
@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS orders_unassigned_deadline_idx;
+
+ALTER TABLE public.orders
+    DROP COLUMN IF EXISTS deadline,
+    DROP COLUMN IF EXISTS priority;
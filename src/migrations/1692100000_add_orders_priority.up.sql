@@ -0,0 +1,6 @@
+ALTER TABLE public.orders
+    ADD COLUMN IF NOT EXISTS priority text NOT NULL DEFAULT 'STANDARD',
+    ADD COLUMN IF NOT EXISTS deadline timestamptz;
+
+CREATE INDEX IF NOT EXISTS orders_unassigned_deadline_idx ON public.orders (deadline)
+    WHERE delivery_group_id IS NULL AND deadline IS NOT NULL;
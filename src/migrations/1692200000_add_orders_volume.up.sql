@@ -0,0 +1,5 @@
+ALTER TABLE public.orders
+    ADD COLUMN IF NOT EXISTS volume double precision,
+    ADD COLUMN IF NOT EXISTS length double precision,
+    ADD COLUMN IF NOT EXISTS width double precision,
+    ADD COLUMN IF NOT EXISTS height double precision;
@@ -0,0 +1,9 @@
+DROP TABLE IF EXISTS public.order_items;
+
+DROP SEQUENCE IF EXISTS order_items_id_seq;
+
+ALTER TABLE public.couriers
+    DROP COLUMN IF EXISTS capabilities;
+
+ALTER TABLE public.orders
+    DROP COLUMN IF EXISTS handling;
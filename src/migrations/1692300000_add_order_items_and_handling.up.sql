@@ -0,0 +1,25 @@
+ALTER TABLE public.orders
+    ADD COLUMN IF NOT EXISTS handling text[] NOT NULL DEFAULT '{}';
+
+ALTER TABLE public.couriers
+    ADD COLUMN IF NOT EXISTS capabilities text[] NOT NULL DEFAULT '{}';
+
+CREATE SEQUENCE IF NOT EXISTS order_items_id_seq start 1 increment 1;
+
+CREATE TABLE IF NOT EXISTS public.order_items
+(
+    id bigint NOT NULL DEFAULT nextval('order_items_id_seq'::regclass),
+    order_id bigint,
+    sku text NOT NULL,
+    quantity integer NOT NULL,
+    unit_weight double precision NOT NULL,
+    CONSTRAINT order_items_pkey PRIMARY KEY (id),
+    CONSTRAINT fk_orders_items FOREIGN KEY (order_id)
+        REFERENCES public.orders (id) MATCH SIMPLE
+        ON UPDATE CASCADE
+        ON DELETE CASCADE
+)
+
+TABLESPACE pg_default;
+
+CREATE INDEX IF NOT EXISTS order_items_order_id_idx ON public.order_items (order_id);
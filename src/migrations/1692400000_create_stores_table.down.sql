@@ -0,0 +1,9 @@
+ALTER TABLE public.courier_working_hours
+    DROP COLUMN IF EXISTS store_id;
+
+ALTER TABLE public.orders
+    DROP COLUMN IF EXISTS store_id;
+
+DROP TABLE IF EXISTS public.stores;
+
+DROP SEQUENCE IF EXISTS stores_id_seq;
@@ -0,0 +1,28 @@
+CREATE SEQUENCE IF NOT EXISTS stores_id_seq start 1 increment 1;
+
+CREATE TABLE IF NOT EXISTS public.stores
+(
+    id bigint NOT NULL DEFAULT nextval('stores_id_seq'::regclass),
+    name text COLLATE pg_catalog."default" NOT NULL,
+    region integer NOT NULL,
+    lat double precision NOT NULL,
+    lon double precision NOT NULL,
+    pickup_minutes integer NOT NULL DEFAULT 5,
+    CONSTRAINT stores_pkey PRIMARY KEY (id)
+)
+
+TABLESPACE pg_default;
+
+ALTER TABLE public.orders
+    ADD COLUMN IF NOT EXISTS store_id bigint,
+    ADD CONSTRAINT fk_orders_store FOREIGN KEY (store_id)
+        REFERENCES public.stores (id) MATCH SIMPLE
+        ON UPDATE CASCADE
+        ON DELETE SET NULL;
+
+ALTER TABLE public.courier_working_hours
+    ADD COLUMN IF NOT EXISTS store_id bigint,
+    ADD CONSTRAINT fk_courier_working_hours_store FOREIGN KEY (store_id)
+        REFERENCES public.stores (id) MATCH SIMPLE
+        ON UPDATE CASCADE
+        ON DELETE SET NULL;
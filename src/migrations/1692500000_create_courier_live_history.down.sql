@@ -0,0 +1,7 @@
+DROP TABLE IF EXISTS public.courier_locations;
+
+DROP SEQUENCE IF EXISTS courier_locations_id_seq;
+
+DROP TABLE IF EXISTS public.courier_statuses;
+
+DROP SEQUENCE IF EXISTS courier_statuses_id_seq;
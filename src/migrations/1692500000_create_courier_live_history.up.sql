@@ -0,0 +1,40 @@
+CREATE SEQUENCE IF NOT EXISTS courier_statuses_id_seq start 1 increment 1;
+
+CREATE TABLE IF NOT EXISTS public.courier_statuses
+(
+    id bigint NOT NULL DEFAULT nextval('courier_statuses_id_seq'::regclass),
+    courier_id bigint NOT NULL,
+    status text NOT NULL,
+    reported_at timestamp with time zone NOT NULL,
+    CONSTRAINT courier_statuses_pkey PRIMARY KEY (id),
+    CONSTRAINT fk_couriers_statuses FOREIGN KEY (courier_id)
+        REFERENCES public.couriers (id) MATCH SIMPLE
+        ON UPDATE CASCADE
+        ON DELETE CASCADE
+)
+
+TABLESPACE pg_default;
+
+CREATE INDEX IF NOT EXISTS courier_statuses_courier_id_reported_at_idx
+    ON public.courier_statuses (courier_id, reported_at DESC);
+
+CREATE SEQUENCE IF NOT EXISTS courier_locations_id_seq start 1 increment 1;
+
+CREATE TABLE IF NOT EXISTS public.courier_locations
+(
+    id bigint NOT NULL DEFAULT nextval('courier_locations_id_seq'::regclass),
+    courier_id bigint NOT NULL,
+    lat double precision NOT NULL,
+    lon double precision NOT NULL,
+    reported_at timestamp with time zone NOT NULL,
+    CONSTRAINT courier_locations_pkey PRIMARY KEY (id),
+    CONSTRAINT fk_couriers_locations FOREIGN KEY (courier_id)
+        REFERENCES public.couriers (id) MATCH SIMPLE
+        ON UPDATE CASCADE
+        ON DELETE CASCADE
+)
+
+TABLESPACE pg_default;
+
+CREATE INDEX IF NOT EXISTS courier_locations_courier_id_reported_at_idx
+    ON public.courier_locations (courier_id, reported_at DESC);
@@ -0,0 +1,8 @@
+// Package migrations embeds SQL schema migrations in golang-migrate format:
+// `<version>_<name>.up.sql` and `<version>_<name>.down.sql`.
+package migrations
+
+import "embed"
+
+//go:embed *.sql
+var FS embed.FS
@@ -0,0 +1,126 @@
+package migrator
+
+import (
+	"database/sql"
+	"errors"
+	"io/fs"
+
+	"github.com/golang-migrate/migrate/v4"
+	migratePgx "github.com/golang-migrate/migrate/v4/database/pgx/v5"
+	"github.com/golang-migrate/migrate/v4/source/iofs"
+)
+
+// Migrator applies embedded migrations.
+//
+// Every operation changing the schema holds postgres advisory lock
+// (pg_advisory_lock, keyed by database and migrations table), so replicas
+// migrating on boot at the same time are applied one after another.
+type Migrator struct {
+	source fs.FS
+	m      *migrate.Migrate
+}
+
+type Status struct {
+	Version uint
+	Dirty   bool
+	// The last version available in source.
+	Latest uint
+}
+
+func (s Status) Pending() bool {
+	return s.Version < s.Latest
+}
+
+func New(db *sql.DB, source fs.FS, table string) (*Migrator, error) {
+	src, err := iofs.New(source, ".")
+	if err != nil {
+		return nil, err
+	}
+
+	driver, err := migratePgx.WithInstance(db, &migratePgx.Config{
+		MigrationsTable: table,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	m, err := migrate.NewWithInstance("iofs", src, "postgres", driver)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Migrator{source: source, m: m}, nil
+}
+
+// Up applies all pending migrations.
+func (mg *Migrator) Up() error {
+	return ignoreNoChange(mg.m.Up())
+}
+
+// Down rolls back the last applied migration.
+func (mg *Migrator) Down() error {
+	return ignoreNoChange(mg.m.Steps(-1))
+}
+
+// Goto migrates up or down to the version.
+func (mg *Migrator) Goto(version uint) error {
+	return ignoreNoChange(mg.m.Migrate(version))
+}
+
+func (mg *Migrator) Status() (Status, error) {
+	latest, err := LatestVersion(mg.source)
+	if err != nil {
+		return Status{}, err
+	}
+
+	version, dirty, err := mg.m.Version()
+	if err != nil && !errors.Is(err, migrate.ErrNilVersion) {
+		return Status{}, err
+	}
+
+	return Status{Version: version, Dirty: dirty, Latest: latest}, nil
+}
+
+// Close releases database connection held by migrator.
+func (mg *Migrator) Close() error {
+	srcErr, dbErr := mg.m.Close()
+	if srcErr != nil {
+		return srcErr
+	}
+
+	return dbErr
+}
+
+// LatestVersion returns version of the last migration in source.
+func LatestVersion(source fs.FS) (uint, error) {
+	src, err := iofs.New(source, ".")
+	if err != nil {
+		return 0, err
+	}
+	defer src.Close()
+
+	version, err := src.First()
+	if err != nil {
+		return 0, err
+	}
+
+	for {
+		next, err := src.Next(version)
+		if errors.Is(err, fs.ErrNotExist) {
+			return version, nil
+		}
+		if err != nil {
+			return 0, err
+		}
+
+		version = next
+	}
+}
+
+func ignoreNoChange(err error) error {
+	if errors.Is(err, migrate.ErrNoChange) {
+		return nil
+	}
+
+	return err
+}
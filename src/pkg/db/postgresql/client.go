@@ -3,22 +3,32 @@ package postgresql
 import (
 	"context"
 	"fmt"
-	"log"
 	"sync"
 	"time"
 
+	"go.uber.org/zap"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
-	"gorm.io/gorm/logger"
+	gormlogger "gorm.io/gorm/logger"
+	"yandex-team.ru/bstask/pkg/logger"
 )
 
-const CONNECTION_RETRIES = 5
 const CONNECTION_RETRY_BACKOF = 5
+const CONNECTION_PING_TIMEOUT = 3 * time.Second
 
 var onceDb sync.Once
 var instance *gorm.DB
 
-func GetInstance(host, user, password, dbname string, port int) *gorm.DB {
+type PoolConfig struct {
+	MaxOpenConns    int
+	MaxIdleConns    int
+	ConnMaxLifetime time.Duration
+}
+
+// GetInstance opens connection pool without waiting for the database.
+// Connection is checked in background every CONNECTION_RETRY_BACKOF seconds,
+// so the process keeps running while postgres is unavailable.
+func GetInstance(host, user, password, dbname string, port int, pool PoolConfig) *gorm.DB {
 
 	onceDb.Do(func() {
 
@@ -30,28 +40,80 @@ func GetInstance(host, user, password, dbname string, port int) *gorm.DB {
 			password,
 		)
 
-		logger := logger.Default.LogMode(logger.Warn)
-		retries := CONNECTION_RETRIES
-		db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{Logger: logger})
-
-		for err != nil {
-			retries--
-			if retries < 0 {
-				log.Fatalf("Could not connect to database: %v", err)
-			}
-
-			time.Sleep(CONNECTION_RETRY_BACKOF * time.Second)
-
-			logger.Warn(
-				context.TODO(),
-				fmt.Sprintf("Failed to connect to postgres. Doing retry %d of %d", retries, CONNECTION_RETRIES),
-			)
+		db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{
+			Logger:               NewGormLogger(gormlogger.Warn),
+			DisableAutomaticPing: true,
+		})
+		if err != nil {
+			// DSN is malformed, retrying will not help
+			logger.Global().Fatal("could not open database", zap.Error(err))
+		}
 
-			db, err = gorm.Open(postgres.Open(dsn), &gorm.Config{Logger: logger})
+		sqlDB, err := db.DB()
+		if err != nil {
+			logger.Global().Fatal("could not get database pool", zap.Error(err))
 		}
+		sqlDB.SetMaxOpenConns(pool.MaxOpenConns)
+		sqlDB.SetMaxIdleConns(pool.MaxIdleConns)
+		sqlDB.SetConnMaxLifetime(pool.ConnMaxLifetime)
 
 		instance = db
+
+		go watchConnection(db)
 	})
 
 	return instance
 }
+
+// Ping checks connection to the database.
+func Ping(ctx context.Context, db *gorm.DB) error {
+	sqlDB, err := db.DB()
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, CONNECTION_PING_TIMEOUT)
+	defer cancel()
+
+	return sqlDB.PingContext(ctx)
+}
+
+func watchConnection(db *gorm.DB) {
+	log := logger.Global()
+	connected := false
+
+	for {
+		err := Ping(context.Background(), db)
+
+		switch {
+		case err != nil && connected:
+			log.Error("lost connection to postgres", zap.Error(err))
+		case err != nil:
+			log.Warn("failed to connect to postgres, doing retry", zap.Error(err))
+		case !connected:
+			log.Info("connected to postgres")
+		}
+
+		connected = err == nil
+
+		time.Sleep(CONNECTION_RETRY_BACKOF * time.Second)
+	}
+}
+
+// WaitConnected blocks until the database accepts connections or ctx is done.
+func WaitConnected(ctx context.Context, db *gorm.DB) error {
+	for {
+		err := Ping(ctx, db)
+		if err == nil {
+			return nil
+		}
+
+		logger.Global().Warn("waiting for postgres", zap.Error(err))
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(CONNECTION_RETRY_BACKOF * time.Second):
+		}
+	}
+}
@@ -0,0 +1,47 @@
+package postgresql
+
+import (
+	"database/sql"
+	"fmt"
+
+	"gorm.io/gorm"
+)
+
+// Cursor runs query through a server-side cursor, fetching `batch` rows
+// at a time, and calls fn for every row. Memory stays flat whatever the
+// size of the result. db must be bound to a transaction.
+func Cursor(db *gorm.DB, name string, batch int, query string, args []interface{}, fn func(db *gorm.DB, rows *sql.Rows) error) error {
+
+	err := db.Exec("DECLARE "+name+" NO SCROLL CURSOR FOR "+query, args...).Error
+	if err != nil {
+		return err
+	}
+
+	fetch := fmt.Sprintf("FETCH FORWARD %d FROM %s", batch, name)
+	for {
+		rows, err := db.Raw(fetch).Rows()
+		if err != nil {
+			return err
+		}
+
+		n := 0
+		for rows.Next() {
+			n++
+			if err := fn(db, rows); err != nil {
+				rows.Close()
+				return err
+			}
+		}
+		err = rows.Err()
+		rows.Close()
+		if err != nil {
+			return err
+		}
+
+		if n < batch {
+			break
+		}
+	}
+
+	return db.Exec("CLOSE " + name).Error
+}
@@ -0,0 +1,38 @@
+// Package geo has helpers for coordinates on the Earth surface.
+package geo
+
+import "math"
+
+const EARTH_RADIUS_KM = 6371.0
+
+// Point is WGS 84 coordinates in degrees.
+type Point struct {
+	Lat float64
+	Lon float64
+}
+
+func (p Point) Valid() bool {
+	return p.Lat >= -90 && p.Lat <= 90 && p.Lon >= -180 && p.Lon <= 180
+}
+
+// Distance returns great-circle distance between points in kilometers.
+func Distance(a, b Point) float64 {
+	lat1 := radians(a.Lat)
+	lat2 := radians(b.Lat)
+	dLat := lat2 - lat1
+	dLon := radians(b.Lon - a.Lon)
+
+	h := math.Sin(dLat/2)*math.Sin(dLat/2) + math.Cos(lat1)*math.Cos(lat2)*math.Sin(dLon/2)*math.Sin(dLon/2)
+
+	return 2 * EARTH_RADIUS_KM * math.Asin(math.Min(1, math.Sqrt(h)))
+}
+
+// LonScale is the length of a longitude degree relative to a latitude degree
+// at lat. Planar distances scaled by it are good enough to compare nearby points.
+func LonScale(lat float64) float64 {
+	return math.Cos(radians(lat))
+}
+
+func radians(deg float64) float64 {
+	return deg * math.Pi / 180
+}
@@ -0,0 +1,56 @@
+package hooks
+
+import "gorm.io/gorm"
+
+// Operations lists gorm processors, which can be wrapped by Around.
+var Operations = []string{"create", "query", "update", "delete", "row", "raw"}
+
+// Around registers `before` and `after` callbacks around every gorm operation.
+// Callbacks are named `<name>:before_<operation>` and `<name>:after_<operation>`.
+func Around(db *gorm.DB, name string, before, after func(operation string) func(*gorm.DB)) error {
+	cb := db.Callback()
+
+	for _, op := range Operations {
+		gormCallback := "gorm:" + op
+
+		var err error
+		switch op {
+		case "create":
+			err = cb.Create().Before(gormCallback).Register(name+":before_"+op, before(op))
+			if err == nil {
+				err = cb.Create().After(gormCallback).Register(name+":after_"+op, after(op))
+			}
+		case "query":
+			err = cb.Query().Before(gormCallback).Register(name+":before_"+op, before(op))
+			if err == nil {
+				err = cb.Query().After(gormCallback).Register(name+":after_"+op, after(op))
+			}
+		case "update":
+			err = cb.Update().Before(gormCallback).Register(name+":before_"+op, before(op))
+			if err == nil {
+				err = cb.Update().After(gormCallback).Register(name+":after_"+op, after(op))
+			}
+		case "delete":
+			err = cb.Delete().Before(gormCallback).Register(name+":before_"+op, before(op))
+			if err == nil {
+				err = cb.Delete().After(gormCallback).Register(name+":after_"+op, after(op))
+			}
+		case "row":
+			err = cb.Row().Before(gormCallback).Register(name+":before_"+op, before(op))
+			if err == nil {
+				err = cb.Row().After(gormCallback).Register(name+":after_"+op, after(op))
+			}
+		case "raw":
+			err = cb.Raw().Before(gormCallback).Register(name+":before_"+op, before(op))
+			if err == nil {
+				err = cb.Raw().After(gormCallback).Register(name+":after_"+op, after(op))
+			}
+		}
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
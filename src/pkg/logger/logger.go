@@ -0,0 +1,94 @@
+package logger
+
+import (
+	"context"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"yandex-team.ru/bstask"
+)
+
+type ctxKey int
+
+const (
+	loggerKey ctxKey = iota
+	requestIDKey
+)
+
+var global = zap.NewNop()
+
+// New builds JSON logger. For `prod` env level is Info, otherwise Debug.
+func New(env string) (*zap.Logger, error) {
+	conf := zap.NewProductionConfig()
+	conf.EncoderConfig.TimeKey = "time"
+	conf.EncoderConfig.EncodeTime = zapcore.ISO8601TimeEncoder
+	conf.DisableStacktrace = true
+
+	if env != "prod" {
+		conf.Level = zap.NewAtomicLevelAt(zap.DebugLevel)
+	}
+
+	return conf.Build()
+}
+
+// SetGlobal sets logger, which is used when context carries no logger.
+func SetGlobal(l *zap.Logger) {
+	global = l
+}
+
+func Global() *zap.Logger {
+	return global
+}
+
+// WithContext returns copy of ctx carrying l.
+func WithContext(ctx context.Context, l *zap.Logger) context.Context {
+	return context.WithValue(ctx, loggerKey, l)
+}
+
+// FromContext returns logger attached to ctx or global logger.
+func FromContext(ctx context.Context) *zap.Logger {
+	if ctx == nil {
+		return global
+	}
+
+	if l, ok := ctx.Value(loggerKey).(*zap.Logger); ok {
+		return l
+	}
+
+	return global
+}
+
+// WithRequestID returns copy of ctx carrying request id and logger
+// which adds it to every line.
+func WithRequestID(ctx context.Context, id string) context.Context {
+	ctx = context.WithValue(ctx, requestIDKey, id)
+	return WithContext(ctx, FromContext(ctx).With(zap.String("request_id", id)))
+}
+
+func RequestIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey).(string)
+	return id
+}
+
+// ErrorFields converts err into log fields: error message, code,
+// trace of operations and fields of bstask.Error.
+func ErrorFields(err error) []zap.Field {
+	if err == nil {
+		return nil
+	}
+
+	fields := []zap.Field{
+		zap.Error(err),
+		zap.String("code", bstask.ErrorCode(err).String()),
+	}
+
+	if trace := bstask.ErrorTrace(err); len(trace) > 0 {
+		fields = append(fields, zap.Strings("trace", trace))
+	}
+
+	if errFields := bstask.ErrorFields(err); len(errFields) > 0 {
+		fields = append(fields, zap.Any("fields", errFields))
+	}
+
+	return fields
+}
@@ -0,0 +1,41 @@
+// Package pagination encodes opaque cursors for keyset pagination.
+package pagination
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+)
+
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// Cursor points right after the last row of a page.
+type Cursor struct {
+	ID uint64 `json:"id"`
+	// Values of sort fields of the row, when listing isn't sorted by ID only.
+	Values []float64 `json:"v,omitempty"`
+}
+
+// Encode returns url-safe representation of cursor.
+func (c Cursor) Encode() string {
+	b, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// Decode parses cursor made by Encode. Empty string means the first page.
+func Decode(s string) (Cursor, error) {
+	var c Cursor
+	if s == "" {
+		return c, nil
+	}
+
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return c, ErrInvalidCursor
+	}
+	if err := json.Unmarshal(b, &c); err != nil {
+		return c, ErrInvalidCursor
+	}
+
+	return c, nil
+}
@@ -0,0 +1,63 @@
+package tracing
+
+import (
+	"errors"
+
+	"go.opentelemetry.io/otel/attribute"
+	semconv "go.opentelemetry.io/otel/semconv/v1.21.0"
+	"go.opentelemetry.io/otel/trace"
+	"gorm.io/gorm"
+	"yandex-team.ru/bstask/pkg/gorm/hooks"
+)
+
+const gormSpanKey = "tracing:span"
+
+// GormPlugin starts span for every query made through gorm.
+type GormPlugin struct{}
+
+func (GormPlugin) Name() string {
+	return "tracing"
+}
+
+func (p GormPlugin) Initialize(db *gorm.DB) error {
+	return hooks.Around(db, p.Name(), p.before, p.after)
+}
+
+func (GormPlugin) before(operation string) func(*gorm.DB) {
+	return func(db *gorm.DB) {
+		ctx, span := Tracer().Start(
+			db.Statement.Context,
+			"gorm."+operation,
+			trace.WithSpanKind(trace.SpanKindClient),
+			trace.WithAttributes(semconv.DBSystemPostgreSQL),
+		)
+
+		db.Statement.Context = ctx
+		db.InstanceSet(gormSpanKey, span)
+	}
+}
+
+func (GormPlugin) after(string) func(*gorm.DB) {
+	return func(db *gorm.DB) {
+		v, ok := db.InstanceGet(gormSpanKey)
+		if !ok {
+			return
+		}
+
+		span, ok := v.(trace.Span)
+		if !ok {
+			return
+		}
+		defer span.End()
+
+		span.SetAttributes(
+			semconv.DBStatement(db.Statement.SQL.String()),
+			semconv.DBSQLTable(db.Statement.Table),
+			attribute.Int64("db.rows_affected", db.Statement.RowsAffected),
+		)
+
+		if !errors.Is(db.Error, gorm.ErrRecordNotFound) {
+			RecordError(span, db.Error)
+		}
+	}
+}
@@ -0,0 +1,38 @@
+package tracing
+
+import (
+	"context"
+
+	"github.com/avito-tech/go-transaction-manager/trm"
+)
+
+// Manager starts span for every transaction of the wrapped manager.
+type Manager struct {
+	trm.Manager
+}
+
+func NewManager(m trm.Manager) *Manager {
+	return &Manager{Manager: m}
+}
+
+func (m *Manager) Do(ctx context.Context, fn func(ctx context.Context) error) error {
+	ctx, span := Start(ctx, "trm.Do")
+	defer span.End()
+
+	err := m.Manager.Do(ctx, fn)
+	RecordError(span, err)
+
+	return err
+}
+
+func (m *Manager) DoWithSettings(ctx context.Context, s trm.Settings, fn func(ctx context.Context) error) error {
+	ctx, span := Start(ctx, "trm.DoWithSettings")
+	defer span.End()
+
+	err := m.Manager.DoWithSettings(ctx, s, fn)
+	RecordError(span, err)
+
+	return err
+}
+
+var _ trm.Manager = &Manager{}
@@ -3,6 +3,7 @@ package validations
 import (
 	"reflect"
 	"regexp"
+	"strings"
 
 	"gopkg.in/go-playground/validator.v9"
 )
@@ -44,6 +45,13 @@ func Each_HH_MM_HH_MM_time_interval(fl validator.FieldLevel) bool {
 		if !match || err != nil {
 			return false
 		}
+
+		// end before start crosses midnight, 22:00-02:00; equal ends are rejected,
+		// a whole day or nothing depending on who reads them
+		spl := strings.Split(item, "-")
+		if spl[0] == spl[1] {
+			return false
+		}
 	}
 
 	return true
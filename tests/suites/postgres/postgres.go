@@ -18,6 +18,11 @@ import (
 
 const MIGRATIONS_TABLE = "migrations"
 
+// SUITE_LOCK_KEY is the advisory lock held by a suite from setup to teardown.
+// Test packages run as separate processes over the one database, the lock
+// keeps them from migrating and truncating it under each other.
+const SUITE_LOCK_KEY = 1691262127
+
 type Suite struct {
 	Ctx     context.Context
 	Pgx     *pgx.Conn
@@ -52,8 +57,21 @@ func SetupInstance(ctx context.Context) *Suite {
 		panic(fmt.Errorf("DB ping error: %w", err))
 	}
 
-	_, filename, _, _ := runtime.Caller(0)
-	migrationsPath := "file://" + path.Join(path.Dir(filename), "migrations")
+	pgxConnUrl := fmt.Sprintf(
+		"postgres://%s:%s@%s:%d/%s",
+		dbUser, dbPassword, dbHost, dbPort, dbName,
+	)
+	suite.Pgx, err = pgx.Connect(ctx, pgxConnUrl)
+	if err != nil {
+		panic(fmt.Errorf("failed to connect by pgx: %w", err))
+	}
+
+	// released when the connection is closed in TearDownInstance
+	if _, err = suite.Pgx.Exec(ctx, "SELECT pg_advisory_lock($1)", SUITE_LOCK_KEY); err != nil {
+		panic(fmt.Errorf("failed to lock database for the suite: %w", err))
+	}
+
+	migrationsPath := "file://" + migrationsDir()
 
 	driver, err := migratePgx.WithInstance(db, &migratePgx.Config{
 		MigrationsTable: MIGRATIONS_TABLE,
@@ -74,16 +92,18 @@ func SetupInstance(ctx context.Context) *Suite {
 		panic(fmt.Errorf("failed to up migrations: %w", err))
 	}
 
-	pgxConnUrl := fmt.Sprintf(
-		"postgres://%s:%s@%s:%d/%s",
-		dbUser, dbPassword, dbHost, dbPort, dbName,
-	)
-	suite.Pgx, err = pgx.Connect(ctx, pgxConnUrl)
-	if err != nil {
-		panic(fmt.Errorf("failed to connect by pgx: %w", err))
+	return &suite
+}
+
+// migrationsDir returns directory with migrations of the service,
+// MIGRATIONS_PATH env variable overrides location inside the repository.
+func migrationsDir() string {
+	if dir := os.Getenv("MIGRATIONS_PATH"); dir != "" {
+		return dir
 	}
 
-	return &suite
+	_, filename, _, _ := runtime.Caller(0)
+	return path.Join(path.Dir(filename), "..", "..", "..", "src", "migrations")
 }
 
 func (suite *Suite) TearDownInstance() {
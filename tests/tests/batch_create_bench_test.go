@@ -0,0 +1,73 @@
+package tests
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+const BENCH_BATCH_SIZE = 10000
+
+// BenchmarkCreateOrders10k measures POST /orders with a batch of 10k orders,
+// two delivery intervals each. Run against a live service:
+//
+//	host=http://localhost:8080 go test ./tests -run '^$' -bench CreateOrders
+//
+// To compare with single-row inserts run it against a service built from
+// a revision before batched inserts.
+func BenchmarkCreateOrders10k(b *testing.B) {
+	rows := make([]string, 0, BENCH_BATCH_SIZE)
+	for i := 0; i < BENCH_BATCH_SIZE; i++ {
+		rows = append(rows, fmt.Sprintf(
+			`{"weight": %d.5, "regions": %d, "delivery_hours": ["10:00-12:00", "14:00-16:00"], "cost": %d}`,
+			1+i%20, 1+i%10, 100+i%900,
+		))
+	}
+
+	benchmarkBatchCreate(b, "/orders", `{"orders": [`+strings.Join(rows, ",")+`]}`)
+}
+
+// BenchmarkCreateCouriers10k measures POST /couriers with a batch of 10k
+// couriers, two working intervals each.
+func BenchmarkCreateCouriers10k(b *testing.B) {
+	types := []string{"FOOT", "BIKE", "AUTO"}
+
+	rows := make([]string, 0, BENCH_BATCH_SIZE)
+	for i := 0; i < BENCH_BATCH_SIZE; i++ {
+		rows = append(rows, fmt.Sprintf(
+			`{"courier_type": "%s", "regions": [%d], "working_hours": ["08:00-12:00", "14:00-18:00"]}`,
+			types[i%len(types)], 1+i%10,
+		))
+	}
+
+	benchmarkBatchCreate(b, "/couriers", `{"couriers": [`+strings.Join(rows, ",")+`]}`)
+}
+
+func benchmarkBatchCreate(b *testing.B, path, body string) {
+	host := os.Getenv("host")
+	if host == "" {
+		b.Skip("host env is not set")
+	}
+
+	payload := []byte(body)
+
+	start := time.Now()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		resp, err := http.Post(host+path, "application/json", bytes.NewReader(payload))
+		if err != nil {
+			b.Fatal(err)
+		}
+		resp.Body.Close()
+
+		if resp.StatusCode != http.StatusOK {
+			b.Fatalf("unexpected status %d", resp.StatusCode)
+		}
+	}
+
+	b.ReportMetric(float64(BENCH_BATCH_SIZE*b.N)/time.Since(start).Seconds(), "rows/s")
+}
@@ -0,0 +1,76 @@
+package courier
+
+import (
+	"net/http"
+	"strings"
+	"tests/tests"
+
+	"github.com/stretchr/testify/require"
+)
+
+func (s *CourierTestSuite) TestCreateBatchWiresWorkingHours() {
+	resp, err := http.Post(COURIER_CREATE_URL, "application/json", strings.NewReader(`{"couriers": [
+		{"courier_type": "FOOT", "regions": [1], "working_hours": ["08:00-09:00", "10:00-11:00", "12:00-13:00"]},
+		{"courier_type": "BIKE", "regions": [2], "working_hours": ["14:00-15:00"]},
+		{"courier_type": "AUTO", "regions": [3], "working_hours": ["16:00-17:00", "18:00-19:00"], "breaks": ["16:30-16:45"]},
+		{"courier_type": "FOOT", "regions": [4], "working_hours": ["20:00-21:00"]}
+	]}`))
+	require.NoError(s.T(), err, "HTTP error")
+	defer resp.Body.Close()
+	require.Equal(s.T(), http.StatusOK, resp.StatusCode, "HTTP status code")
+
+	var created struct {
+		Couriers []struct {
+			CourierId    uint64   `json:"courier_id"`
+			CourierType  string   `json:"courier_type"`
+			Regions      []int32  `json:"regions"`
+			WorkingHours []string `json:"working_hours"`
+			Breaks       []string `json:"breaks"`
+		} `json:"couriers"`
+	}
+	require.NoError(s.T(), tests.ResponseToStruct(resp.Body, &created), "Unmarshall")
+	require.Len(s.T(), created.Couriers, 4)
+
+	expected := []struct {
+		courierType  string
+		region       int32
+		workingHours []string
+	}{
+		{"FOOT", 1, []string{"08:00-09:00", "10:00-11:00", "12:00-13:00"}},
+		{"BIKE", 2, []string{"14:00-15:00"}},
+		{"AUTO", 3, []string{"16:00-17:00", "18:00-19:00"}},
+		{"FOOT", 4, []string{"20:00-21:00"}},
+	}
+
+	// returned in input order with IDs of inserted rows
+	for i, c := range created.Couriers {
+		require.Equal(s.T(), uint64(i+1), c.CourierId)
+		require.Equal(s.T(), expected[i].courierType, c.CourierType)
+		require.Equal(s.T(), []int32{expected[i].region}, c.Regions)
+		require.ElementsMatch(s.T(), expected[i].workingHours, c.WorkingHours)
+	}
+	require.Equal(s.T(), []string{"16:30-16:45"}, created.Couriers[2].Breaks)
+
+	// every working hours row belongs to its courier
+	for i, c := range created.Couriers {
+		rows, err := s.pgSuite.Pgx.Query(s.pgSuite.Ctx, `
+			SELECT to_char(start_time, 'HH24:MI') || '-' || to_char(end_time, 'HH24:MI')
+			FROM courier_working_hours WHERE courier_id = $1`, c.CourierId)
+		require.NoError(s.T(), err)
+
+		hours := []string{}
+		for rows.Next() {
+			var h string
+			require.NoError(s.T(), rows.Scan(&h))
+			hours = append(hours, h)
+		}
+		rows.Close()
+
+		require.ElementsMatch(s.T(), expected[i].workingHours, hours)
+	}
+
+	var breaksCourier uint64
+	err = s.pgSuite.Pgx.QueryRow(s.pgSuite.Ctx, "SELECT courier_id FROM courier_breaks").Scan(&breaksCourier)
+	require.NoError(s.T(), err)
+	require.Equal(s.T(), created.Couriers[2].CourierId, breaksCourier)
+}
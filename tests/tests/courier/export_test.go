@@ -0,0 +1,84 @@
+package courier
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"tests/suites/postgres/fake"
+
+	"github.com/stretchr/testify/require"
+)
+
+var COURIER_EXPORT_URL string = fmt.Sprintf("%s/export/couriers", os.Getenv("host"))
+
+func (s *CourierTestSuite) insertCouriers(n uint8) {
+	for _, c := range fake.CorrectCouriers(n) {
+		courierId := s.pgSuite.InsertCourier(c)
+
+		for _, i := range fake.CorrectCourierWorkingHours(2) {
+			i.CourierID = courierId
+			s.pgSuite.InsertWorkingHours(i)
+		}
+	}
+}
+
+func (s *CourierTestSuite) TestExportCouriersCSV() {
+	s.insertCouriers(5)
+
+	resp, err := http.Get(COURIER_EXPORT_URL)
+	require.NoError(s.T(), err, "HTTP error")
+	defer resp.Body.Close()
+
+	require.Equal(s.T(), http.StatusOK, resp.StatusCode, "HTTP status code")
+	require.Contains(s.T(), resp.Header.Get("Content-Type"), "text/csv")
+
+	records, err := csv.NewReader(resp.Body).ReadAll()
+	require.NoError(s.T(), err)
+	require.Len(s.T(), records, 6)
+	require.Equal(s.T(), []string{"courier_id", "courier_type", "regions", "working_hours"}, records[0])
+}
+
+func (s *CourierTestSuite) TestExportCouriersNDJSON() {
+	s.insertCouriers(5)
+
+	resp, err := http.Get(COURIER_EXPORT_URL + "?format=ndjson")
+	require.NoError(s.T(), err, "HTTP error")
+	defer resp.Body.Close()
+
+	require.Equal(s.T(), http.StatusOK, resp.StatusCode, "HTTP status code")
+
+	rows := 0
+	scanner := bufio.NewScanner(resp.Body)
+	for scanner.Scan() {
+		var item struct {
+			CourierId    uint64   `json:"courier_id"`
+			WorkingHours []string `json:"working_hours"`
+		}
+		require.NoError(s.T(), json.Unmarshal(scanner.Bytes(), &item))
+		require.NotZero(s.T(), item.CourierId)
+		require.Len(s.T(), item.WorkingHours, 2)
+		rows++
+	}
+	require.Equal(s.T(), 5, rows)
+}
+
+func (s *CourierTestSuite) TestExportValidationErrors() {
+	urls := []string{
+		COURIER_EXPORT_URL + "?format=xml",
+		COURIER_EXPORT_URL + "?courier_type=PLANE",
+		COURIER_EXPORT_URL + "?region=-1",
+		fmt.Sprintf("%s/export/assignments", os.Getenv("host")),
+		fmt.Sprintf("%s/export/orders?status=lost", os.Getenv("host")),
+	}
+
+	for _, url := range urls {
+		resp, err := http.Get(url)
+		require.NoError(s.T(), err, "HTTP error")
+		resp.Body.Close()
+
+		require.Equal(s.T(), http.StatusBadRequest, resp.StatusCode, url)
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"tests/suites/postgres"
 	"tests/suites/postgres/fake"
 	"tests/tests"
+	"time"
 
 	"github.com/georgysavva/scany/v2/pgxscan"
 	"github.com/stretchr/testify/require"
@@ -158,3 +159,134 @@ func (s *CourierTestSuite) TestGetAllExpectSuccess() {
 		require.Equal(s.T(), expectedRes, parsedRes)
 	}
 }
+
+func (s *CourierTestSuite) TestGetAllKeysetPagination() {
+
+	type PageResponse struct {
+		Couriers []struct {
+			CourierId uint64 `json:"courier_id"`
+		} `json:"couriers"`
+		NextCursor *string `json:"next_cursor"`
+		Total      *int64  `json:"total"`
+	}
+
+	s.insertCouriers(5)
+
+	ids := []uint64{}
+	cursor := ""
+	for pages := 0; ; pages++ {
+		require.Less(s.T(), pages, 5, "pagination must stop")
+
+		resp, err := http.Get(fmt.Sprintf("%s?limit=2&total=true&cursor=%s", COURIER_GET_ALL_URL, cursor))
+		require.NoError(s.T(), err, "HTTP error")
+		defer resp.Body.Close()
+
+		require.Equal(s.T(), http.StatusOK, resp.StatusCode, "HTTP status code")
+
+		var page PageResponse
+		require.NoError(s.T(), tests.ResponseToStruct(resp.Body, &page), "Unmarshall")
+		require.NotNil(s.T(), page.Total)
+		require.EqualValues(s.T(), 5, *page.Total)
+
+		for _, c := range page.Couriers {
+			ids = append(ids, c.CourierId)
+		}
+
+		if page.NextCursor == nil {
+			break
+		}
+		cursor = *page.NextCursor
+	}
+
+	require.Len(s.T(), ids, 5)
+	require.IsIncreasing(s.T(), ids)
+
+	resp, err := http.Get(COURIER_GET_ALL_URL + "?cursor=garbage")
+	require.NoError(s.T(), err, "HTTP error")
+	defer resp.Body.Close()
+	require.Equal(s.T(), http.StatusBadRequest, resp.StatusCode, "HTTP status code")
+}
+
+func (s *CourierTestSuite) TestGetAllFilters() {
+
+	type PageResponse struct {
+		Couriers []struct {
+			CourierId uint64 `json:"courier_id"`
+		} `json:"couriers"`
+		Total *int64 `json:"total"`
+	}
+
+	clock := func(v string) time.Time {
+		t, _ := time.Parse("15:04", v)
+		return t
+	}
+
+	couriers := []struct {
+		courier postgres.Courier
+		start   string
+		end     string
+	}{
+		{postgres.Courier{CourierType: "BIKE", Regions: []int32{1, 3}}, "16:00", "20:00"},
+		{postgres.Courier{CourierType: "BIKE", Regions: []int32{3}}, "09:00", "18:00"},
+		{postgres.Courier{CourierType: "FOOT", Regions: []int32{3}}, "16:00", "20:00"},
+		{postgres.Courier{CourierType: "BIKE", Regions: []int32{2}}, "16:00", "20:00"},
+		{postgres.Courier{CourierType: "AUTO", Regions: []int32{4}}, "22:00", "02:00"},
+	}
+
+	ids := []uint64{}
+	for _, c := range couriers {
+		id := s.pgSuite.InsertCourier(c.courier)
+		s.pgSuite.InsertWorkingHours(postgres.CourierWorkingHours{
+			CourierID: id,
+			StartTime: clock(c.start),
+			EndTime:   clock(c.end),
+		})
+		ids = append(ids, id)
+	}
+
+	requests := []struct {
+		query    string
+		expected []uint64
+	}{
+		{"courier_type=BIKE&region=3&available_at=18:00", []uint64{ids[0]}},
+		{"courier_type=BIKE&region=3", []uint64{ids[0], ids[1]}},
+		{"region=3&available_between=17:00-19:00", []uint64{ids[0], ids[2]}},
+		{"available_at=09:30", []uint64{ids[1]}},
+		// overnight shift
+		{"available_at=01:00", []uint64{ids[4]}},
+		{"available_between=23:00-01:30", []uint64{ids[4]}},
+		{"region=4&available_at=03:00", []uint64{}},
+	}
+
+	for _, r := range requests {
+		resp, err := http.Get(fmt.Sprintf("%s?limit=10&total=true&cursor=&%s", COURIER_GET_ALL_URL, r.query))
+		require.NoError(s.T(), err, "HTTP error")
+		defer resp.Body.Close()
+
+		require.Equal(s.T(), http.StatusOK, resp.StatusCode, r.query)
+
+		var page PageResponse
+		require.NoError(s.T(), tests.ResponseToStruct(resp.Body, &page), "Unmarshall")
+		got := []uint64{}
+		for _, c := range page.Couriers {
+			got = append(got, c.CourierId)
+		}
+		require.Equal(s.T(), r.expected, got, r.query)
+		require.EqualValues(s.T(), len(r.expected), *page.Total, r.query)
+	}
+
+	invalid := []string{
+		"courier_type=PLANE",
+		"region=-3",
+		"available_at=25:00",
+		"available_between=17:00-17:00",
+		"available_between=17:00",
+	}
+	for _, q := range invalid {
+		resp, err := http.Get(fmt.Sprintf("%s?%s", COURIER_GET_ALL_URL, q))
+		require.NoError(s.T(), err, "HTTP error")
+		defer resp.Body.Close()
+
+		require.Equal(s.T(), http.StatusBadRequest, resp.StatusCode, q)
+	}
+}
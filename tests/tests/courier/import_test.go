@@ -0,0 +1,100 @@
+package courier
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"tests/tests"
+
+	"github.com/stretchr/testify/require"
+)
+
+var COURIER_IMPORT_URL string = fmt.Sprintf("%s/couriers/import", os.Getenv("host"))
+
+type importResponse struct {
+	Mode     string `json:"mode"`
+	Imported int    `json:"imported"`
+	Failed   int    `json:"failed"`
+	Errors   []struct {
+		Line    int    `json:"line"`
+		Message string `json:"message"`
+	} `json:"errors"`
+}
+
+const importCSV = `courier_type,regions,working_hours
+FOOT,1;2,10:00-12:00;14:00-18:00
+invalid_type,1,10:00-12:00
+BIKE,3,09:00-11:00
+AUTO,x,09:00-11:00
+`
+
+func (s *CourierTestSuite) TestImportAtomicRejectsAllOnInvalidRow() {
+	resp, err := http.Post(COURIER_IMPORT_URL, "text/csv", strings.NewReader(importCSV))
+	require.NoError(s.T(), err, "HTTP error")
+	defer resp.Body.Close()
+
+	require.Equal(s.T(), http.StatusBadRequest, resp.StatusCode, "HTTP status code")
+
+	var parsedRes importResponse
+	require.NoError(s.T(), tests.ResponseToStruct(resp.Body, &parsedRes), "Unmarshall")
+
+	require.Equal(s.T(), "atomic", parsedRes.Mode)
+	require.Equal(s.T(), 0, parsedRes.Imported)
+	require.Equal(s.T(), 2, parsedRes.Failed)
+	require.Equal(s.T(), 3, parsedRes.Errors[0].Line)
+	require.Equal(s.T(), 5, parsedRes.Errors[1].Line)
+
+	var cnt int
+	err = s.pgSuite.Pgx.QueryRow(s.pgSuite.Ctx, "SELECT COUNT(*) FROM couriers").Scan(&cnt)
+	require.NoError(s.T(), err)
+	require.Equal(s.T(), 0, cnt)
+}
+
+func (s *CourierTestSuite) TestImportBestEffortSavesValidRows() {
+	resp, err := http.Post(COURIER_IMPORT_URL+"?mode=best_effort", "text/csv", strings.NewReader(importCSV))
+	require.NoError(s.T(), err, "HTTP error")
+	defer resp.Body.Close()
+
+	require.Equal(s.T(), http.StatusOK, resp.StatusCode, "HTTP status code")
+
+	var parsedRes importResponse
+	require.NoError(s.T(), tests.ResponseToStruct(resp.Body, &parsedRes), "Unmarshall")
+
+	require.Equal(s.T(), 2, parsedRes.Imported)
+	require.Equal(s.T(), 2, parsedRes.Failed)
+
+	var cnt int
+	err = s.pgSuite.Pgx.QueryRow(s.pgSuite.Ctx, "SELECT COUNT(*) FROM couriers").Scan(&cnt)
+	require.NoError(s.T(), err)
+	require.Equal(s.T(), 2, cnt)
+
+	err = s.pgSuite.Pgx.QueryRow(s.pgSuite.Ctx, "SELECT COUNT(*) FROM courier_working_hours").Scan(&cnt)
+	require.NoError(s.T(), err)
+	require.Equal(s.T(), 3, cnt)
+}
+
+func (s *CourierTestSuite) TestImportNDJSON() {
+	body := `{"courier_type": "FOOT", "regions": [1], "working_hours": ["10:00-12:00"]}
+
+{"courier_type": "AUTO", "regions": [1, 2], "working_hours": ["08:00-20:00"]}
+`
+	resp, err := http.Post(COURIER_IMPORT_URL, "application/x-ndjson", strings.NewReader(body))
+	require.NoError(s.T(), err, "HTTP error")
+	defer resp.Body.Close()
+
+	require.Equal(s.T(), http.StatusOK, resp.StatusCode, "HTTP status code")
+
+	var parsedRes importResponse
+	require.NoError(s.T(), tests.ResponseToStruct(resp.Body, &parsedRes), "Unmarshall")
+	require.Equal(s.T(), 2, parsedRes.Imported)
+	require.Equal(s.T(), 0, parsedRes.Failed)
+}
+
+func (s *CourierTestSuite) TestImportUnsupportedContentType() {
+	resp, err := http.Post(COURIER_IMPORT_URL, "application/json", strings.NewReader(`{}`))
+	require.NoError(s.T(), err, "HTTP error")
+	defer resp.Body.Close()
+
+	require.Equal(s.T(), http.StatusUnsupportedMediaType, resp.StatusCode, "HTTP status code")
+}
@@ -0,0 +1,110 @@
+package courier
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"tests/suites/postgres/fake"
+	"tests/tests"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type courierLive struct {
+	CourierId  uint64     `json:"courier_id"`
+	Status     string     `json:"status"`
+	StatusTime *time.Time `json:"status_time"`
+	Location   *struct {
+		Lat float64 `json:"lat"`
+		Lon float64 `json:"lon"`
+	} `json:"location"`
+	LocationTime *time.Time `json:"location_time"`
+}
+
+func (s *CourierTestSuite) report(courierId uint64, kind, body string) *http.Response {
+	resp, err := http.Post(
+		fmt.Sprintf("%s/%d/%s", COURIER_GET_BY_ID_URL, courierId, kind),
+		"application/json",
+		strings.NewReader(body),
+	)
+	require.NoError(s.T(), err, "HTTP error")
+
+	return resp
+}
+
+func (s *CourierTestSuite) live(courierId uint64) courierLive {
+	resp, err := http.Get(fmt.Sprintf("%s/%d/live", COURIER_GET_BY_ID_URL, courierId))
+	require.NoError(s.T(), err, "HTTP error")
+	defer resp.Body.Close()
+	require.Equal(s.T(), http.StatusOK, resp.StatusCode, "HTTP status code")
+
+	var res courierLive
+	require.NoError(s.T(), tests.ResponseToStruct(resp.Body, &res), "Unmarshall")
+
+	return res
+}
+
+func (s *CourierTestSuite) TestLiveKeepsLatestReport() {
+	courierId := s.pgSuite.InsertCourier(fake.CorrectCouriers(1)[0])
+
+	// never reported
+	live := s.live(courierId)
+	require.Equal(s.T(), "OFFLINE", live.Status)
+	require.Nil(s.T(), live.StatusTime)
+	require.Nil(s.T(), live.Location)
+
+	resp := s.report(courierId, "status", `{"status": "DELIVERING", "timestamp": "2023-08-01T10:05:00Z"}`)
+	defer resp.Body.Close()
+	require.Equal(s.T(), http.StatusOK, resp.StatusCode, "HTTP status code")
+
+	// delivered late, older than the last one
+	resp = s.report(courierId, "status", `{"status": "PICKING", "timestamp": "2023-08-01T10:00:00Z"}`)
+	defer resp.Body.Close()
+	require.Equal(s.T(), http.StatusOK, resp.StatusCode, "HTTP status code")
+
+	resp = s.report(courierId, "location", `{"lat": 55.75, "lon": 37.61, "timestamp": "2023-08-01T10:06:00Z"}`)
+	defer resp.Body.Close()
+	require.Equal(s.T(), http.StatusOK, resp.StatusCode, "HTTP status code")
+
+	live = s.live(courierId)
+	require.Equal(s.T(), courierId, live.CourierId)
+	require.Equal(s.T(), "DELIVERING", live.Status)
+	require.NotNil(s.T(), live.StatusTime)
+	require.True(s.T(), live.StatusTime.Equal(time.Date(2023, 8, 1, 10, 5, 0, 0, time.UTC)))
+	require.NotNil(s.T(), live.Location)
+	require.Equal(s.T(), 55.75, live.Location.Lat)
+	require.Equal(s.T(), 37.61, live.Location.Lon)
+
+	// the whole history is kept
+	var statuses int
+	err := s.pgSuite.Pgx.QueryRow(s.pgSuite.Ctx, `SELECT COUNT(*) FROM courier_statuses WHERE courier_id = $1`, courierId).Scan(&statuses)
+	require.NoError(s.T(), err)
+	require.Equal(s.T(), 2, statuses)
+}
+
+func (s *CourierTestSuite) TestLiveExpectValidationErrors() {
+	courierId := s.pgSuite.InsertCourier(fake.CorrectCouriers(1)[0])
+
+	requests := []struct {
+		kind string
+		body string
+	}{
+		{"status", `{"status": "SLEEPING"}`},
+		{"status", `{}`},
+		{"status", `{"status": "IDLE", "timestamp": "2999-01-01T00:00:00Z"}`},
+		{"location", `{"lat": 91, "lon": 37.61}`},
+		{"location", `{"lat": 55.75}`},
+	}
+
+	for _, req := range requests {
+		resp := s.report(courierId, req.kind, req.body)
+		defer resp.Body.Close()
+
+		require.Equal(s.T(), http.StatusBadRequest, resp.StatusCode, req.body)
+	}
+
+	resp := s.report(courierId+1, "status", `{"status": "IDLE"}`)
+	defer resp.Body.Close()
+	require.Equal(s.T(), http.StatusNotFound, resp.StatusCode, "unknown courier")
+}
@@ -0,0 +1,76 @@
+package tests
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"testing"
+	"tests/suites/postgres"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestHealthzRespondsOk(t *testing.T) {
+	resp, err := http.Get(fmt.Sprintf("%s/healthz", os.Getenv("host")))
+	require.NoError(t, err, "HTTP error")
+	defer resp.Body.Close()
+
+	require.Equal(t, http.StatusOK, resp.StatusCode, "HTTP status code")
+
+	var parsedRes struct {
+		Status string `json:"status"`
+	}
+	require.NoError(t, ResponseToStruct(resp.Body, &parsedRes), "Unmarshall")
+	require.Equal(t, "ok", parsedRes.Status)
+}
+
+type readinessReport struct {
+	Status string                            `json:"status"`
+	Checks map[string]map[string]interface{} `json:"checks"`
+}
+
+func readyz(t *testing.T) (int, readinessReport) {
+	resp, err := http.Get(fmt.Sprintf("%s/readyz", os.Getenv("host")))
+	require.NoError(t, err, "HTTP error")
+	defer resp.Body.Close()
+
+	var parsedRes readinessReport
+	require.NoError(t, ResponseToStruct(resp.Body, &parsedRes), "Unmarshall")
+
+	return resp.StatusCode, parsedRes
+}
+
+func TestReadyzReportsReady(t *testing.T) {
+	pgSuite := postgres.SetupInstance(context.Background())
+	defer pgSuite.TearDownInstance()
+
+	status, report := readyz(t)
+	require.Equal(t, http.StatusOK, status, "HTTP status code")
+	require.Contains(t, report.Checks, "postgres")
+	require.Contains(t, report.Checks, "migrations")
+	require.Contains(t, report.Checks, "pool")
+	for name, check := range report.Checks {
+		require.Equal(t, "ok", check["status"], name)
+	}
+	require.Equal(t, "ok", report.Status)
+}
+
+func TestReadyzReportsUnmigratedSchema(t *testing.T) {
+	pgSuite := postgres.SetupInstance(context.Background())
+	defer pgSuite.TearDownInstance()
+
+	// hide the migrations table for a while, as if the schema was never migrated
+	_, err := pgSuite.Pgx.Exec(pgSuite.Ctx, `ALTER TABLE migrations RENAME TO migrations_hidden`)
+	require.NoError(t, err)
+	defer func() {
+		_, err := pgSuite.Pgx.Exec(pgSuite.Ctx, `ALTER TABLE migrations_hidden RENAME TO migrations`)
+		require.NoError(t, err)
+	}()
+
+	status, report := readyz(t)
+	require.Equal(t, http.StatusServiceUnavailable, status, "HTTP status code")
+	require.Equal(t, "fail", report.Status)
+	require.Equal(t, "fail", report.Checks["migrations"]["status"])
+	require.Equal(t, "ok", report.Checks["postgres"]["status"])
+}
@@ -0,0 +1,68 @@
+package order
+
+import (
+	"net/http"
+	"tests/tests"
+
+	"github.com/stretchr/testify/require"
+)
+
+func (s *OrderTestSuite) TestCreateBatchWiresDeliveryHours() {
+	resp := s.post(ORDER_GET_ALL_URL, `{"orders": [
+		{"weight": 1, "regions": 1, "delivery_hours": ["08:00-09:00", "10:00-11:00"], "cost": 100},
+		{"weight": 2, "regions": 2, "delivery_hours": ["12:00-13:00"], "cost": 200, "items": [{"sku": "A", "quantity": 1, "unit_weight": 1}]},
+		{"weight": 3, "regions": 3, "delivery_hours": ["14:00-15:00", "16:00-17:00", "18:00-19:00"], "cost": 300}
+	]}`)
+	defer resp.Body.Close()
+	require.Equal(s.T(), http.StatusOK, resp.StatusCode, "HTTP status code")
+
+	var created struct {
+		Orders []struct {
+			ID            uint64   `json:"order_id"`
+			Weight        float64  `json:"weight"`
+			Regions       int32    `json:"regions"`
+			DeliveryHours []string `json:"delivery_hours"`
+			Cost          uint32   `json:"cost"`
+		} `json:"orders"`
+	}
+	require.NoError(s.T(), tests.ResponseToStruct(resp.Body, &created), "Unmarshall")
+	require.Len(s.T(), created.Orders, 3)
+
+	expected := [][]string{
+		{"08:00-09:00", "10:00-11:00"},
+		{"12:00-13:00"},
+		{"14:00-15:00", "16:00-17:00", "18:00-19:00"},
+	}
+
+	// returned in input order with IDs of inserted rows
+	for i, o := range created.Orders {
+		require.Equal(s.T(), uint64(i+1), o.ID)
+		require.Equal(s.T(), float64(i+1), o.Weight)
+		require.Equal(s.T(), int32(i+1), o.Regions)
+		require.Equal(s.T(), uint32(100*(i+1)), o.Cost)
+		require.ElementsMatch(s.T(), expected[i], o.DeliveryHours)
+	}
+
+	// every delivery hours row belongs to its order
+	for i, o := range created.Orders {
+		rows, err := s.pgSuite.Pgx.Query(s.pgSuite.Ctx, `
+			SELECT to_char(start_time, 'HH24:MI') || '-' || to_char(end_time, 'HH24:MI')
+			FROM order_delivery_hours WHERE order_id = $1`, o.ID)
+		require.NoError(s.T(), err)
+
+		hours := []string{}
+		for rows.Next() {
+			var h string
+			require.NoError(s.T(), rows.Scan(&h))
+			hours = append(hours, h)
+		}
+		rows.Close()
+
+		require.ElementsMatch(s.T(), expected[i], hours)
+	}
+
+	var itemsOrder uint64
+	err := s.pgSuite.Pgx.QueryRow(s.pgSuite.Ctx, "SELECT order_id FROM order_items").Scan(&itemsOrder)
+	require.NoError(s.T(), err)
+	require.Equal(s.T(), created.Orders[1].ID, itemsOrder)
+}
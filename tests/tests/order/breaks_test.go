@@ -0,0 +1,47 @@
+package order
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func (s *OrderTestSuite) TestBreakPostponesDelivery() {
+	s.createCourier(`{"couriers": [{"courier_type": "AUTO", "regions": [1], "working_hours": ["10:00-12:00"], "breaks": ["10:00-11:00"]}]}`)
+	orders := s.createOrders(`{"orders": [{"weight": 1, "regions": 1, "delivery_hours": ["10:00-12:00"], "cost": 100}]}`)
+	require.Len(s.T(), orders, 1)
+
+	resp := s.post(ORDER_GET_ALL_URL+"/assign?date=2023-08-01", "")
+	defer resp.Body.Close()
+	require.Equal(s.T(), http.StatusOK, resp.StatusCode, "HTTP status code")
+
+	require.Equal(s.T(), time.Date(2023, 8, 1, 11, 8, 0, 0, time.UTC), s.completedTime(orders[0]).UTC())
+}
+
+func (s *OrderTestSuite) TestMaxDeliveriesPerShift() {
+	s.createCourier(`{"couriers": [{"courier_type": "AUTO", "regions": [1], "working_hours": ["10:00-12:00"], "max_deliveries_per_shift": 1}]}`)
+	orders := s.createOrders(`{"orders": [
+		{"weight": 1, "regions": 1, "delivery_hours": ["10:00-12:00"], "cost": 100},
+		{"weight": 1, "regions": 1, "delivery_hours": ["10:00-12:00"], "cost": 100}
+	]}`)
+	require.Len(s.T(), orders, 2)
+
+	resp := s.post(ORDER_GET_ALL_URL+"/assign?date=2023-08-01", "")
+	defer resp.Body.Close()
+	require.Equal(s.T(), http.StatusOK, resp.StatusCode, "HTTP status code")
+
+	assigned := 0
+	for _, id := range orders {
+		if s.completedTime(id) != nil {
+			assigned++
+		}
+	}
+	require.Equal(s.T(), 1, assigned)
+}
+
+func (s *OrderTestSuite) TestBreakOutsideWorkingHours() {
+	resp := s.post(COURIERS_URL, `{"couriers": [{"courier_type": "AUTO", "regions": [1], "working_hours": ["10:00-12:00"], "breaks": ["12:00-13:00"]}]}`)
+	defer resp.Body.Close()
+	require.Equal(s.T(), http.StatusBadRequest, resp.StatusCode, "HTTP status code")
+}
@@ -0,0 +1,181 @@
+package order
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+
+	"github.com/stretchr/testify/require"
+)
+
+var EXPORT_URL string = fmt.Sprintf("%s/export", os.Getenv("host"))
+
+type orderExportItem struct {
+	OrderId      uint64  `json:"order_id"`
+	Regions      int32   `json:"regions"`
+	Status       string  `json:"status"`
+	CourierId    *uint64 `json:"courier_id"`
+	GroupOrderId *uint64 `json:"group_order_id"`
+}
+
+type assignmentExportItem struct {
+	Date         string `json:"date"`
+	CourierId    uint64 `json:"courier_id"`
+	GroupOrderId uint64 `json:"group_order_id"`
+	OrderId      uint64 `json:"order_id"`
+	Regions      int32  `json:"regions"`
+}
+
+// exportNDJSON decodes every line of export at url into a new T.
+func exportNDJSON[T any](s *OrderTestSuite, url string) []T {
+	resp, err := http.Get(url)
+	require.NoError(s.T(), err, "HTTP error")
+	defer resp.Body.Close()
+	require.Equal(s.T(), http.StatusOK, resp.StatusCode, url)
+
+	res := []T{}
+	scanner := bufio.NewScanner(resp.Body)
+	for scanner.Scan() {
+		var item T
+		require.NoError(s.T(), json.Unmarshal(scanner.Bytes(), &item))
+		res = append(res, item)
+	}
+	require.NoError(s.T(), scanner.Err())
+
+	return res
+}
+
+// assignForExport assigns two orders of region 1 to a courier on 2023-08-01
+// and leaves one order of region 2 unassigned.
+func (s *OrderTestSuite) assignForExport() (courierId uint64, orders []uint64) {
+	courierId = s.createCourier(`{"couriers": [{"courier_type": "AUTO", "regions": [1], "working_hours": ["10:00-12:00"]}]}`)
+	orders = s.createOrders(`{"orders": [
+		{"weight": 1, "regions": 1, "delivery_hours": ["10:00-12:00"], "cost": 100},
+		{"weight": 1, "regions": 1, "delivery_hours": ["10:00-12:00"], "cost": 100},
+		{"weight": 1, "regions": 2, "delivery_hours": ["10:00-12:00"], "cost": 100}
+	]}`)
+	require.Len(s.T(), orders, 3)
+
+	resp := s.post(ORDER_GET_ALL_URL+"/assign?date=2023-08-01", "")
+	defer resp.Body.Close()
+	require.Equal(s.T(), http.StatusOK, resp.StatusCode, "HTTP status code")
+
+	return courierId, orders
+}
+
+func (s *OrderTestSuite) TestExportOrdersCSV() {
+	s.assignForExport()
+
+	resp, err := http.Get(EXPORT_URL + "/orders")
+	require.NoError(s.T(), err, "HTTP error")
+	defer resp.Body.Close()
+
+	require.Equal(s.T(), http.StatusOK, resp.StatusCode, "HTTP status code")
+	require.Contains(s.T(), resp.Header.Get("Content-Type"), "text/csv")
+
+	records, err := csv.NewReader(resp.Body).ReadAll()
+	require.NoError(s.T(), err)
+	require.Len(s.T(), records, 4)
+	require.Equal(s.T(), []string{
+		"order_id", "weight", "regions", "delivery_hours", "cost", "status", "courier_id", "group_order_id", "completed_time",
+	}, records[0])
+}
+
+func (s *OrderTestSuite) TestExportOrdersFilters() {
+	courierId, orders := s.assignForExport()
+
+	unassigned := exportNDJSON[orderExportItem](s, EXPORT_URL+"/orders?format=ndjson&status=unassigned")
+	require.Len(s.T(), unassigned, 1)
+	require.Equal(s.T(), orders[2], unassigned[0].OrderId)
+	require.Nil(s.T(), unassigned[0].CourierId)
+
+	ofCourier := exportNDJSON[orderExportItem](s, fmt.Sprintf("%s/orders?format=ndjson&courier_id=%d&region=1", EXPORT_URL, courierId))
+	require.Len(s.T(), ofCourier, 2)
+	for _, o := range ofCourier {
+		require.NotEqual(s.T(), "unassigned", o.Status)
+		require.NotNil(s.T(), o.CourierId)
+		require.Equal(s.T(), courierId, *o.CourierId)
+		require.NotNil(s.T(), o.GroupOrderId)
+	}
+
+	require.Empty(s.T(), exportNDJSON[orderExportItem](s, fmt.Sprintf("%s/orders?format=ndjson&courier_id=%d", EXPORT_URL, courierId+1)))
+	require.Empty(s.T(), exportNDJSON[orderExportItem](s, EXPORT_URL+"/orders?format=ndjson&region=2&status=completed"))
+	require.Empty(s.T(), exportNDJSON[orderExportItem](s, EXPORT_URL+"/orders?format=ndjson&from=2023-08-02"))
+	require.Len(s.T(), exportNDJSON[orderExportItem](s, EXPORT_URL+"/orders?format=ndjson&from=2023-08-01&to=2023-08-01"), 2)
+}
+
+func (s *OrderTestSuite) TestExportOrdersStatusAfterAssign() {
+	courierId, orders := s.assignForExport()
+
+	// assignment plans completion, the orders are not delivered yet
+	assigned := exportNDJSON[orderExportItem](s, EXPORT_URL+"/orders?format=ndjson&status=assigned")
+	require.Len(s.T(), assigned, 2)
+	for i, o := range assigned {
+		require.Equal(s.T(), orders[i], o.OrderId)
+		require.Equal(s.T(), "assigned", o.Status)
+	}
+	require.Empty(s.T(), exportNDJSON[orderExportItem](s, EXPORT_URL+"/orders?format=ndjson&status=completed"))
+
+	resp := s.post(ORDER_GET_ALL_URL+"/complete", fmt.Sprintf(
+		`{"complete_info": [{"courier_id": %d, "order_id": %d, "complete_time": "2023-08-01T11:00:00Z"}]}`,
+		courierId,
+		orders[2],
+	))
+	defer resp.Body.Close()
+	require.Equal(s.T(), http.StatusOK, resp.StatusCode, "HTTP status code")
+
+	completed := exportNDJSON[orderExportItem](s, EXPORT_URL+"/orders?format=ndjson&status=completed")
+	require.Len(s.T(), completed, 1)
+	require.Equal(s.T(), orders[2], completed[0].OrderId)
+	require.Equal(s.T(), "completed", completed[0].Status)
+	require.Len(s.T(), exportNDJSON[orderExportItem](s, EXPORT_URL+"/orders?format=ndjson&status=assigned"), 2)
+	require.Empty(s.T(), exportNDJSON[orderExportItem](s, EXPORT_URL+"/orders?format=ndjson&status=unassigned"))
+}
+
+func (s *OrderTestSuite) TestExportAssignmentsFilters() {
+	courierId, orders := s.assignForExport()
+
+	assignments := exportNDJSON[assignmentExportItem](s, EXPORT_URL+"/assignments?format=ndjson&date=2023-08-01")
+	require.Len(s.T(), assignments, 2)
+	assigned := []uint64{}
+	for _, a := range assignments {
+		require.Equal(s.T(), "2023-08-01", a.Date)
+		require.Equal(s.T(), courierId, a.CourierId)
+		require.NotZero(s.T(), a.GroupOrderId)
+		require.Equal(s.T(), int32(1), a.Regions)
+		assigned = append(assigned, a.OrderId)
+	}
+	require.ElementsMatch(s.T(), orders[:2], assigned)
+
+	require.Len(s.T(), exportNDJSON[assignmentExportItem](s, fmt.Sprintf("%s/assignments?format=ndjson&from=2023-07-31&to=2023-08-01&courier_id=%d", EXPORT_URL, courierId)), 2)
+	require.Empty(s.T(), exportNDJSON[assignmentExportItem](s, EXPORT_URL+"/assignments?format=ndjson&date=2023-08-01&region=2"))
+	require.Empty(s.T(), exportNDJSON[assignmentExportItem](s, EXPORT_URL+"/assignments?format=ndjson&date=2023-08-02"))
+
+	resp, err := http.Get(EXPORT_URL + "/assignments?date=2023-08-01")
+	require.NoError(s.T(), err, "HTTP error")
+	defer resp.Body.Close()
+	records, err := csv.NewReader(resp.Body).ReadAll()
+	require.NoError(s.T(), err)
+	require.Len(s.T(), records, 3)
+}
+
+func (s *OrderTestSuite) TestExportOrdersValidationErrors() {
+	urls := []string{
+		EXPORT_URL + "/orders?from=01.08.2023",
+		EXPORT_URL + "/orders?courier_id=abc",
+		EXPORT_URL + "/orders?format=xml",
+		EXPORT_URL + "/assignments?date=2023-13-01",
+		EXPORT_URL + "/assignments?from=2023-08-01",
+	}
+
+	for _, url := range urls {
+		resp, err := http.Get(url)
+		require.NoError(s.T(), err, "HTTP error")
+		resp.Body.Close()
+
+		require.Equal(s.T(), http.StatusBadRequest, resp.StatusCode, url)
+	}
+}
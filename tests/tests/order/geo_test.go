@@ -0,0 +1,127 @@
+package order
+
+import (
+	"net/http"
+	"tests/tests"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func (s *OrderTestSuite) TestCreateWithCoordinates() {
+	resp := s.post(ORDER_GET_ALL_URL, `{"orders": [
+		{"weight": 1, "regions": 1, "delivery_hours": ["10:00-11:00"], "cost": 100, "lat": 55.75, "lon": 37.62},
+		{"weight": 1, "regions": 1, "delivery_hours": ["10:00-11:00"], "cost": 100}
+	]}`)
+	defer resp.Body.Close()
+	require.Equal(s.T(), http.StatusOK, resp.StatusCode, "HTTP status code")
+
+	var res struct {
+		Orders []struct {
+			Lat *float64 `json:"lat"`
+			Lon *float64 `json:"lon"`
+		} `json:"orders"`
+	}
+	require.NoError(s.T(), tests.ResponseToStruct(resp.Body, &res), "Unmarshall")
+	require.Len(s.T(), res.Orders, 2)
+	require.Equal(s.T(), 55.75, *res.Orders[0].Lat)
+	require.Equal(s.T(), 37.62, *res.Orders[0].Lon)
+	require.Nil(s.T(), res.Orders[1].Lat)
+
+	invalid := []string{
+		`{"orders": [{"weight": 1, "regions": 1, "delivery_hours": ["10:00-11:00"], "cost": 100, "lat": 55.75}]}`,
+		`{"orders": [{"weight": 1, "regions": 1, "delivery_hours": ["10:00-11:00"], "cost": 100, "lat": 95, "lon": 37.62}]}`,
+	}
+	for _, body := range invalid {
+		resp := s.post(ORDER_GET_ALL_URL, body)
+		defer resp.Body.Close()
+
+		require.Equal(s.T(), http.StatusBadRequest, resp.StatusCode, body)
+	}
+}
+
+func (s *OrderTestSuite) TestAssignUsesTravelTimes() {
+	resp := s.post(REGIONS_URL, `{"regions": [{"region_id": 1, "name": "Center", "depot": {"lat": 55.75, "lon": 37.62}}]}`)
+	defer resp.Body.Close()
+	require.Equal(s.T(), http.StatusOK, resp.StatusCode, "HTTP status code")
+
+	resp = s.post(COURIERS_URL, `{"couriers": [{"courier_type": "BIKE", "regions": [1], "working_hours": ["10:00-11:00"]}]}`)
+	defer resp.Body.Close()
+	require.Equal(s.T(), http.StatusOK, resp.StatusCode, "HTTP status code")
+
+	// A is 1.5 km north of depot, B 0.5 km further, C 3 km east of A
+	// and lighter than B, far is 50 km away
+	resp = s.post(ORDER_GET_ALL_URL, `{"orders": [
+		{"weight": 1, "regions": 1, "delivery_hours": ["10:00-11:00"], "cost": 100, "lat": 55.7635, "lon": 37.62},
+		{"weight": 3, "regions": 1, "delivery_hours": ["10:00-11:00"], "cost": 100, "lat": 55.7680, "lon": 37.62},
+		{"weight": 2, "regions": 1, "delivery_hours": ["10:00-11:00"], "cost": 100, "lat": 55.7635, "lon": 37.668},
+		{"weight": 0.5, "regions": 1, "delivery_hours": ["10:00-11:00"], "cost": 100, "lat": 56.2, "lon": 37.62}
+	]}`)
+	defer resp.Body.Close()
+	require.Equal(s.T(), http.StatusOK, resp.StatusCode, "HTTP status code")
+
+	resp = s.post(ORDER_GET_ALL_URL+"/assign?date=2023-08-01", "")
+	defer resp.Body.Close()
+	require.Equal(s.T(), http.StatusOK, resp.StatusCode, "HTTP status code")
+
+	var res []struct {
+		Couriers []struct {
+			Orders []struct {
+				Orders []struct {
+					Weight        float64   `json:"weight"`
+					CompletedTime time.Time `json:"completed_time"`
+				} `json:"orders"`
+			} `json:"orders"`
+		} `json:"couriers"`
+	}
+	require.NoError(s.T(), tests.ResponseToStruct(resp.Body, &res), "Unmarshall")
+	require.Len(s.T(), res[0].Couriers, 1)
+	require.Len(s.T(), res[0].Couriers[0].Orders, 1)
+
+	orders := res[0].Couriers[0].Orders[0].Orders
+	require.Len(s.T(), orders, 3, "far order is unreachable within the shift")
+
+	completed := map[float64]time.Time{}
+	for _, o := range orders {
+		completed[o.Weight] = o.CompletedTime
+	}
+
+	start := time.Date(2023, 8, 1, 10, 0, 0, 0, time.UTC)
+	// 1.5 km by bike at 15 km/h and 4 minutes at the first stop in region
+	require.WithinDuration(s.T(), start.Add(10*time.Minute), completed[1], time.Minute)
+	// nearest B goes before lighter C, next stops in region take 2 minutes
+	require.True(s.T(), completed[3].Before(completed[2]))
+	require.WithinDuration(s.T(), start.Add(14*time.Minute), completed[3], time.Minute)
+}
+
+func (s *OrderTestSuite) TestAssignReturnsToDepotForNextBatch() {
+	resp := s.post(REGIONS_URL, `{"regions": [{"region_id": 1, "name": "Center", "depot": {"lat": 55.75, "lon": 37.62}}]}`)
+	defer resp.Body.Close()
+	require.Equal(s.T(), http.StatusOK, resp.StatusCode, "HTTP status code")
+
+	s.createCourier(`{"couriers": [{"courier_type": "BIKE", "regions": [1], "working_hours": ["10:00-12:00"]}]}`)
+
+	// too heavy to share a batch, 3 km east and 3 km west of depot
+	orders := s.createOrders(`{"orders": [
+		{"weight": 15, "regions": 1, "delivery_hours": ["10:00-12:00"], "cost": 100, "lat": 55.75, "lon": 37.6679},
+		{"weight": 15, "regions": 1, "delivery_hours": ["10:00-12:00"], "cost": 100, "lat": 55.75, "lon": 37.5721}
+	]}`)
+	require.Len(s.T(), orders, 2)
+
+	resp = s.post(ORDER_GET_ALL_URL+"/assign?date=2023-08-01", "")
+	defer resp.Body.Close()
+	require.Equal(s.T(), http.StatusOK, resp.StatusCode, "HTTP status code")
+
+	first, second := s.completedTime(orders[0]), s.completedTime(orders[1])
+	require.NotNil(s.T(), first)
+	require.NotNil(s.T(), second)
+	if second.Before(*first) {
+		first, second = second, first
+	}
+
+	start := time.Date(2023, 8, 1, 10, 0, 0, 0, time.UTC)
+	// 3 km by bike at 15 km/h and 4 minutes at the stop
+	require.WithinDuration(s.T(), start.Add(16*time.Minute), *first, time.Minute)
+	// the next batch starts with 3 km back to depot and 4 minutes there
+	require.WithinDuration(s.T(), start.Add(48*time.Minute), *second, time.Minute)
+}
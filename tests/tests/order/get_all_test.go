@@ -0,0 +1,149 @@
+package order
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"tests/suites/postgres"
+	"tests/tests"
+
+	"github.com/stretchr/testify/require"
+)
+
+var ORDER_GET_ALL_URL string = fmt.Sprintf("%s/orders", os.Getenv("host"))
+
+type orderItem struct {
+	OrderId uint64  `json:"order_id"`
+	Weight  float64 `json:"weight"`
+	Regions int32   `json:"regions"`
+	Cost    uint32  `json:"cost"`
+}
+
+type orderPage struct {
+	Orders     []orderItem `json:"orders"`
+	NextCursor *string     `json:"next_cursor"`
+	Total      *int64      `json:"total"`
+}
+
+func (s *OrderTestSuite) insertOrders() {
+	orders := []postgres.Order{
+		{Weight: 1, Regions: 5, Cost: 100},
+		{Weight: 15, Regions: 5, Cost: 300},
+		{Weight: 25, Regions: 5, Cost: 200},
+		{Weight: 25, Regions: 3, Cost: 500},
+		{Weight: 30, Regions: 5, Cost: 200},
+	}
+	for _, o := range orders {
+		s.pgSuite.InsertOrder(o)
+	}
+}
+
+func (s *OrderTestSuite) getOrders(query string) []orderItem {
+	resp, err := http.Get(ORDER_GET_ALL_URL + "?" + query)
+	require.NoError(s.T(), err, "HTTP error")
+	defer resp.Body.Close()
+
+	require.Equal(s.T(), http.StatusOK, resp.StatusCode, query)
+
+	var res []orderItem
+	require.NoError(s.T(), tests.ResponseToStruct(resp.Body, &res), "Unmarshall")
+
+	return res
+}
+
+func (s *OrderTestSuite) TestGetAllFiltersAndSort() {
+	s.insertOrders()
+
+	res := s.getOrders("limit=10&region=5&min_weight=10&assigned=false&sort=-weight,cost")
+	require.Len(s.T(), res, 3)
+	require.Equal(s.T(), 30.0, res[0].Weight)
+	require.Equal(s.T(), 25.0, res[1].Weight)
+	require.Equal(s.T(), 15.0, res[2].Weight)
+
+	res = s.getOrders("limit=10&min_cost=200&max_cost=300&sort=cost,-weight")
+	require.Len(s.T(), res, 3)
+	require.Equal(s.T(), []uint32{200, 200, 300}, []uint32{res[0].Cost, res[1].Cost, res[2].Cost})
+	require.Equal(s.T(), 30.0, res[0].Weight)
+
+	res = s.getOrders("limit=10&completed=true")
+	require.Len(s.T(), res, 0)
+}
+
+func (s *OrderTestSuite) TestGetAllAssignedAndCompletedAfterAssign() {
+	courierId, orders := s.assignForExport()
+
+	ids := func(res []orderItem) []uint64 {
+		ids := []uint64{}
+		for _, o := range res {
+			ids = append(ids, o.OrderId)
+		}
+		return ids
+	}
+
+	// assigned orders have only planned completion
+	require.Equal(s.T(), orders[:2], ids(s.getOrders("limit=10&assigned=true&completed=false")))
+	require.Empty(s.T(), s.getOrders("limit=10&completed=true"))
+
+	resp := s.post(ORDER_GET_ALL_URL+"/complete", fmt.Sprintf(
+		`{"complete_info": [{"courier_id": %d, "order_id": %d, "complete_time": "2023-08-01T11:00:00Z"}]}`,
+		courierId,
+		orders[2],
+	))
+	defer resp.Body.Close()
+	require.Equal(s.T(), http.StatusOK, resp.StatusCode, "HTTP status code")
+
+	require.Equal(s.T(), orders[2:], ids(s.getOrders("limit=10&completed=true")))
+	require.Equal(s.T(), orders[:2], ids(s.getOrders("limit=10&assigned=true&completed=false")))
+	require.Empty(s.T(), s.getOrders("limit=10&assigned=false"))
+}
+
+func (s *OrderTestSuite) TestGetAllSortedKeysetPagination() {
+	s.insertOrders()
+
+	weights := []float64{}
+	cursor := ""
+	for pages := 0; ; pages++ {
+		require.Less(s.T(), pages, 5, "pagination must stop")
+
+		resp, err := http.Get(fmt.Sprintf("%s?limit=2&sort=-weight&total=true&cursor=%s", ORDER_GET_ALL_URL, cursor))
+		require.NoError(s.T(), err, "HTTP error")
+		defer resp.Body.Close()
+
+		require.Equal(s.T(), http.StatusOK, resp.StatusCode, "HTTP status code")
+
+		var page orderPage
+		require.NoError(s.T(), tests.ResponseToStruct(resp.Body, &page), "Unmarshall")
+		require.EqualValues(s.T(), 5, *page.Total)
+
+		for _, o := range page.Orders {
+			weights = append(weights, o.Weight)
+		}
+
+		if page.NextCursor == nil {
+			break
+		}
+		cursor = *page.NextCursor
+	}
+
+	require.Equal(s.T(), []float64{30, 25, 25, 15, 1}, weights)
+}
+
+func (s *OrderTestSuite) TestGetAllValidationErrors() {
+	queries := []string{
+		"sort=color",
+		"sort=weight,weight",
+		"assigned=maybe",
+		"min_weight=heavy",
+		"delivery_window_overlaps=10:00",
+		"delivery_window_overlaps=10:00-10:00",
+		"completed_from=yesterday",
+	}
+
+	for _, q := range queries {
+		resp, err := http.Get(ORDER_GET_ALL_URL + "?" + q)
+		require.NoError(s.T(), err, "HTTP error")
+		resp.Body.Close()
+
+		require.Equal(s.T(), http.StatusBadRequest, resp.StatusCode, q)
+	}
+}
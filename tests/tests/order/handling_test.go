@@ -0,0 +1,68 @@
+package order
+
+import (
+	"net/http"
+	"tests/tests"
+
+	"github.com/stretchr/testify/require"
+)
+
+func (s *OrderTestSuite) TestAssignMatchesCapabilities() {
+	s.createCourier(`{"couriers": [{"courier_type": "AUTO", "regions": [1], "working_hours": ["10:00-12:00"]}]}`)
+	capable := s.createCourier(`{"couriers": [{"courier_type": "AUTO", "regions": [1], "working_hours": ["10:00-12:00"], "capabilities": ["THERMAL_BAG"]}]}`)
+
+	resp := s.post(ORDER_GET_ALL_URL, `{"orders": [{
+		"weight": 3, "regions": 1, "delivery_hours": ["10:00-12:00"], "cost": 100,
+		"items": [{"sku": "ICE-CREAM-1L", "quantity": 2, "unit_weight": 1.2}],
+		"handling": ["COLD_CHAIN"]
+	}]}`)
+	defer resp.Body.Close()
+	require.Equal(s.T(), http.StatusOK, resp.StatusCode, "HTTP status code")
+
+	var created struct {
+		Orders []struct {
+			ID    uint64 `json:"order_id"`
+			Items []struct {
+				SKU      string `json:"sku"`
+				Quantity uint32 `json:"quantity"`
+			} `json:"items"`
+			Handling []string `json:"handling"`
+		} `json:"orders"`
+	}
+	require.NoError(s.T(), tests.ResponseToStruct(resp.Body, &created), "Unmarshall")
+	require.Len(s.T(), created.Orders, 1)
+	require.Len(s.T(), created.Orders[0].Items, 1)
+	require.Equal(s.T(), "ICE-CREAM-1L", created.Orders[0].Items[0].SKU)
+	require.Equal(s.T(), []string{"COLD_CHAIN"}, created.Orders[0].Handling)
+
+	resp = s.post(ORDER_GET_ALL_URL+"/assign?date=2023-08-01", "")
+	defer resp.Body.Close()
+	require.Equal(s.T(), http.StatusOK, resp.StatusCode, "HTTP status code")
+
+	var res []struct {
+		Couriers []struct {
+			CourierId uint64 `json:"courier_id"`
+		} `json:"couriers"`
+	}
+	require.NoError(s.T(), tests.ResponseToStruct(resp.Body, &res), "Unmarshall")
+	require.Len(s.T(), res, 1)
+
+	// only the courier with a thermal bag takes the cold chain order
+	require.Len(s.T(), res[0].Couriers, 1)
+	require.Equal(s.T(), capable, res[0].Couriers[0].CourierId)
+	require.NotNil(s.T(), s.completedTime(created.Orders[0].ID))
+}
+
+func (s *OrderTestSuite) TestHandlingValidation() {
+	resp := s.post(ORDER_GET_ALL_URL, `{"orders": [{"weight": 1, "regions": 1, "delivery_hours": ["10:00-12:00"], "cost": 100, "handling": ["EXPLOSIVE"]}]}`)
+	defer resp.Body.Close()
+	require.Equal(s.T(), http.StatusBadRequest, resp.StatusCode, "unknown handling flag")
+
+	resp = s.post(ORDER_GET_ALL_URL, `{"orders": [{"weight": 1, "regions": 1, "delivery_hours": ["10:00-12:00"], "cost": 100, "items": [{"sku": "A", "quantity": 2, "unit_weight": 1}]}]}`)
+	defer resp.Body.Close()
+	require.Equal(s.T(), http.StatusBadRequest, resp.StatusCode, "items heavier than order")
+
+	resp = s.post(COURIERS_URL, `{"couriers": [{"courier_type": "AUTO", "regions": [1], "working_hours": ["10:00-12:00"], "capabilities": ["JETPACK"]}]}`)
+	defer resp.Body.Close()
+	require.Equal(s.T(), http.StatusBadRequest, resp.StatusCode, "unknown capability")
+}
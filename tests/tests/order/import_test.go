@@ -0,0 +1,87 @@
+package order
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"tests/tests"
+
+	"github.com/stretchr/testify/require"
+)
+
+var ORDER_IMPORT_URL string = fmt.Sprintf("%s/orders/import", os.Getenv("host"))
+
+type importResponse struct {
+	Mode     string `json:"mode"`
+	Imported int    `json:"imported"`
+	Failed   int    `json:"failed"`
+	Errors   []struct {
+		Line    int    `json:"line"`
+		Message string `json:"message"`
+	} `json:"errors"`
+}
+
+const importOrdersCSV = `weight,regions,delivery_hours,cost
+1.5,1,10:00-12:00;14:00-18:00,100
+x,1,10:00-12:00,100
+2,3,09:00-11:00,250
+3,2,9-11,300
+`
+
+func (s *OrderTestSuite) importOrders(query, contentType, body string) importResponse {
+	resp, err := http.Post(ORDER_IMPORT_URL+query, contentType, strings.NewReader(body))
+	require.NoError(s.T(), err, "HTTP error")
+	defer resp.Body.Close()
+
+	var parsedRes importResponse
+	require.NoError(s.T(), tests.ResponseToStruct(resp.Body, &parsedRes), "Unmarshall")
+
+	if parsedRes.Failed > 0 && parsedRes.Mode == "atomic" {
+		require.Equal(s.T(), http.StatusBadRequest, resp.StatusCode, "HTTP status code")
+	} else {
+		require.Equal(s.T(), http.StatusOK, resp.StatusCode, "HTTP status code")
+	}
+
+	return parsedRes
+}
+
+func (s *OrderTestSuite) TestImportAtomicRejectsAllOnInvalidRow() {
+	res := s.importOrders("", "text/csv", importOrdersCSV)
+
+	require.Equal(s.T(), "atomic", res.Mode)
+	require.Equal(s.T(), 0, res.Imported)
+	require.Equal(s.T(), 2, res.Failed)
+	require.Equal(s.T(), 3, res.Errors[0].Line)
+	require.Equal(s.T(), 5, res.Errors[1].Line)
+
+	require.Equal(s.T(), 0, s.countRows("orders"))
+	require.Equal(s.T(), 0, s.countRows("order_delivery_hours"))
+}
+
+func (s *OrderTestSuite) TestImportBestEffortSavesValidRows() {
+	res := s.importOrders("?mode=best_effort", "text/csv", importOrdersCSV)
+
+	require.Equal(s.T(), "best_effort", res.Mode)
+	require.Equal(s.T(), 2, res.Imported)
+	require.Equal(s.T(), 2, res.Failed)
+
+	require.Equal(s.T(), 2, s.countRows("orders"))
+	require.Equal(s.T(), 3, s.countRows("order_delivery_hours"))
+}
+
+func (s *OrderTestSuite) TestImportNDJSON() {
+	body := `{"weight": 2, "regions": 1, "delivery_hours": ["10:00-12:00"], "cost": 100, "items": [{"sku": "A", "quantity": 2, "unit_weight": 0.5}]}
+
+{"weight": 1, "regions": 2, "delivery_hours": ["08:00-20:00"], "cost": 50, "lat": 55.75, "lon": 37.61}
+{"weight": 1, "regions": 2, "delivery_hours": ["08:00-20:00"], "cost": 50, "lat": 55.75}
+`
+	res := s.importOrders("?mode=best_effort", "application/x-ndjson", body)
+
+	require.Equal(s.T(), 2, res.Imported)
+	require.Equal(s.T(), 1, res.Failed)
+	require.Equal(s.T(), 4, res.Errors[0].Line)
+
+	require.Equal(s.T(), 2, s.countRows("orders"))
+	require.Equal(s.T(), 1, s.countRows("order_items"))
+}
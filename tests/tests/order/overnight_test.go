@@ -0,0 +1,118 @@
+package order
+
+import (
+	"fmt"
+	"net/http"
+	"tests/tests"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func (s *OrderTestSuite) TestOvernightIntervals() {
+	resp := s.post(COURIERS_URL, `{"couriers": [{"courier_type": "AUTO", "regions": [1], "working_hours": ["22:00-02:00"]}]}`)
+	defer resp.Body.Close()
+	require.Equal(s.T(), http.StatusOK, resp.StatusCode, "HTTP status code")
+
+	var couriers struct {
+		Couriers []struct {
+			ID uint64 `json:"courier_id"`
+		} `json:"couriers"`
+	}
+	require.NoError(s.T(), tests.ResponseToStruct(resp.Body, &couriers), "Unmarshall")
+	require.Len(s.T(), couriers.Couriers, 1)
+
+	resp = s.post(ORDER_GET_ALL_URL, `{"orders": [
+		{"weight": 1, "regions": 1, "delivery_hours": ["23:30-00:30"], "cost": 100},
+		{"weight": 2, "regions": 1, "delivery_hours": ["00:30-01:30"], "cost": 100},
+		{"weight": 3, "regions": 1, "delivery_hours": ["10:00-11:00"], "cost": 100}
+	]}`)
+	defer resp.Body.Close()
+	require.Equal(s.T(), http.StatusOK, resp.StatusCode, "HTTP status code")
+
+	var orders struct {
+		Orders []struct {
+			ID uint64 `json:"order_id"`
+		} `json:"orders"`
+	}
+	require.NoError(s.T(), tests.ResponseToStruct(resp.Body, &orders), "Unmarshall")
+	require.Len(s.T(), orders.Orders, 3)
+
+	resp = s.post(ORDER_GET_ALL_URL+"/assign?date=2023-08-01", "")
+	defer resp.Body.Close()
+	require.Equal(s.T(), http.StatusOK, resp.StatusCode, "HTTP status code")
+
+	completed := func(id uint64) *time.Time {
+		resp, err := http.Get(fmt.Sprintf("%s/%d", ORDER_GET_ALL_URL, id))
+		require.NoError(s.T(), err, "HTTP error")
+		defer resp.Body.Close()
+		require.Equal(s.T(), http.StatusOK, resp.StatusCode, "HTTP status code")
+
+		var o struct {
+			CompletedTime *time.Time `json:"completed_time"`
+		}
+		require.NoError(s.T(), tests.ResponseToStruct(resp.Body, &o), "Unmarshall")
+
+		return o.CompletedTime
+	}
+
+	// the shift ends on the next day, the second window is met after midnight
+	require.Equal(s.T(), time.Date(2023, 8, 1, 23, 38, 0, 0, time.UTC), completed(orders.Orders[0].ID).UTC())
+	require.Equal(s.T(), time.Date(2023, 8, 2, 0, 34, 0, 0, time.UTC), completed(orders.Orders[1].ID).UTC())
+	require.Nil(s.T(), completed(orders.Orders[2].ID))
+
+	// completion after midnight matches the overnight working interval
+	resp = s.post(ORDER_GET_ALL_URL+"/complete", fmt.Sprintf(
+		`{"complete_info": [{"courier_id": %d, "order_id": %d, "complete_time": "2023-08-02T01:00:00Z"}]}`,
+		couriers.Couriers[0].ID,
+		orders.Orders[2].ID,
+	))
+	defer resp.Body.Close()
+	require.Equal(s.T(), http.StatusOK, resp.StatusCode, "HTTP status code")
+}
+
+func (s *OrderTestSuite) TestOvernightWindowFilter() {
+	resp := s.post(ORDER_GET_ALL_URL, `{"orders": [
+		{"weight": 1, "regions": 1, "delivery_hours": ["23:30-00:30"], "cost": 100},
+		{"weight": 2, "regions": 1, "delivery_hours": ["10:00-11:00"], "cost": 100}
+	]}`)
+	defer resp.Body.Close()
+	require.Equal(s.T(), http.StatusOK, resp.StatusCode, "HTTP status code")
+
+	for _, q := range []string{"00:00-00:15", "22:00-23:45", "00:15-09:00"} {
+		res := s.getOrders("limit=10&delivery_window_overlaps=" + q)
+		require.Len(s.T(), res, 1, q)
+		require.Equal(s.T(), 1.0, res[0].Weight, q)
+	}
+
+	res := s.getOrders("limit=10&delivery_window_overlaps=01:00-09:00")
+	require.Empty(s.T(), res)
+}
+
+func (s *OrderTestSuite) TestEqualEndsRejected() {
+	bodies := []struct{ url, body string }{
+		{ORDER_GET_ALL_URL, `{"orders": [{"weight": 1, "regions": 1, "delivery_hours": ["10:00-10:00"], "cost": 100}]}`},
+		{COURIERS_URL, `{"couriers": [{"courier_type": "FOOT", "regions": [1], "working_hours": ["00:00-00:00"]}]}`},
+		{COURIERS_URL, `{"couriers": [{"courier_type": "FOOT", "regions": [1], "working_hours": ["09:00-18:00"], "breaks": ["13:00-13:00"]}]}`},
+	}
+	for _, b := range bodies {
+		resp := s.post(b.url, b.body)
+		resp.Body.Close()
+		require.Equal(s.T(), http.StatusBadRequest, resp.StatusCode, b.body)
+	}
+
+	for _, url := range []string{
+		ORDER_GET_ALL_URL + "?delivery_window_overlaps=10:00-10:00",
+		COURIERS_URL + "?available_between=00:00-00:00",
+	} {
+		resp, err := http.Get(url)
+		require.NoError(s.T(), err, "HTTP error")
+		resp.Body.Close()
+		require.Equal(s.T(), http.StatusBadRequest, resp.StatusCode, url)
+	}
+
+	var cnt int
+	err := s.pgSuite.Pgx.QueryRow(s.pgSuite.Ctx, "SELECT (SELECT COUNT(*) FROM orders) + (SELECT COUNT(*) FROM couriers)").Scan(&cnt)
+	require.NoError(s.T(), err)
+	require.Zero(s.T(), cnt)
+}
@@ -0,0 +1,45 @@
+package order
+
+import (
+	"net/http"
+	"tests/tests"
+
+	"github.com/stretchr/testify/require"
+)
+
+func (s *OrderTestSuite) TestAssignUrgentFirst() {
+	s.createCourier(`{"couriers": [{"courier_type": "AUTO", "regions": [1], "working_hours": ["10:00-12:00"], "max_deliveries_per_shift": 1}]}`)
+	orders := s.createOrders(`{"orders": [
+		{"weight": 1, "regions": 1, "delivery_hours": ["10:00-12:00"], "cost": 100, "priority": "ECONOMY"},
+		{"weight": 2, "regions": 1, "delivery_hours": ["10:00-12:00"], "cost": 100, "priority": "EXPRESS"},
+		{"weight": 3, "regions": 1, "delivery_hours": ["10:00-12:00"], "cost": 100, "deadline": "2023-08-01T09:00:00Z"}
+	]}`)
+	require.Len(s.T(), orders, 3)
+
+	resp := s.post(ORDER_GET_ALL_URL+"/assign?date=2023-08-01", "")
+	defer resp.Body.Close()
+	require.Equal(s.T(), http.StatusOK, resp.StatusCode, "HTTP status code")
+
+	var res []struct {
+		SLABreaching []struct {
+			ID uint64 `json:"order_id"`
+		} `json:"sla_breaching"`
+	}
+	require.NoError(s.T(), tests.ResponseToStruct(resp.Body, &res), "Unmarshall")
+	require.Len(s.T(), res, 1)
+
+	// the only delivery of the shift goes to the express order
+	require.Nil(s.T(), s.completedTime(orders[0]))
+	require.NotNil(s.T(), s.completedTime(orders[1]))
+
+	// the deadline passed before the shift
+	require.Nil(s.T(), s.completedTime(orders[2]))
+	require.Len(s.T(), res[0].SLABreaching, 1)
+	require.Equal(s.T(), orders[2], res[0].SLABreaching[0].ID)
+}
+
+func (s *OrderTestSuite) TestCreateInvalidPriority() {
+	resp := s.post(ORDER_GET_ALL_URL, `{"orders": [{"weight": 1, "regions": 1, "delivery_hours": ["10:00-12:00"], "cost": 100, "priority": "URGENT"}]}`)
+	defer resp.Body.Close()
+	require.Equal(s.T(), http.StatusBadRequest, resp.StatusCode, "HTTP status code")
+}
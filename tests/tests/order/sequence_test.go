@@ -0,0 +1,78 @@
+package order
+
+import (
+	"fmt"
+	"net/http"
+	"tests/tests"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type sequencedOrder struct {
+	ID               uint64     `json:"order_id"`
+	Weight           float64    `json:"weight"`
+	CompletedTime    time.Time  `json:"completed_time"`
+	DeliverySequence *int32     `json:"delivery_sequence"`
+	ETA              *time.Time `json:"eta"`
+}
+
+func (s *OrderTestSuite) TestAssignSequencesStops() {
+	resp := s.post(REGIONS_URL, `{"regions": [{"region_id": 1, "name": "Center", "depot": {"lat": 55.75, "lon": 37.62}}]}`)
+	defer resp.Body.Close()
+	require.Equal(s.T(), http.StatusOK, resp.StatusCode, "HTTP status code")
+
+	resp = s.post(COURIERS_URL, `{"couriers": [{"courier_type": "BIKE", "regions": [1], "working_hours": ["10:00-11:00"]}]}`)
+	defer resp.Body.Close()
+	require.Equal(s.T(), http.StatusOK, resp.StatusCode, "HTTP status code")
+
+	// lighter A is picked first, 3 km east of depot; B is 1.5 km west
+	resp = s.post(ORDER_GET_ALL_URL, `{"orders": [
+		{"weight": 1, "regions": 1, "delivery_hours": ["10:00-11:00"], "cost": 100, "lat": 55.75, "lon": 37.6679},
+		{"weight": 2, "regions": 1, "delivery_hours": ["10:00-11:00"], "cost": 100, "lat": 55.75, "lon": 37.5961}
+	]}`)
+	defer resp.Body.Close()
+	require.Equal(s.T(), http.StatusOK, resp.StatusCode, "HTTP status code")
+
+	resp = s.post(ORDER_GET_ALL_URL+"/assign?date=2023-08-01", "")
+	defer resp.Body.Close()
+	require.Equal(s.T(), http.StatusOK, resp.StatusCode, "HTTP status code")
+
+	var res []struct {
+		Couriers []struct {
+			Orders []struct {
+				Orders []sequencedOrder `json:"orders"`
+			} `json:"orders"`
+		} `json:"couriers"`
+	}
+	require.NoError(s.T(), tests.ResponseToStruct(resp.Body, &res), "Unmarshall")
+	require.Len(s.T(), res[0].Couriers, 1)
+	require.Len(s.T(), res[0].Couriers[0].Orders, 1)
+
+	orders := res[0].Couriers[0].Orders[0].Orders
+	require.Len(s.T(), orders, 2)
+
+	start := time.Date(2023, 8, 1, 10, 0, 0, 0, time.UTC)
+
+	// B first: 1.5 km and then 4.5 km to A instead of 3 km and 4.5 km back,
+	// plus 4 minutes at the first stop and 2 minutes at the next one
+	require.Equal(s.T(), 2.0, orders[0].Weight)
+	require.Equal(s.T(), int32(1), *orders[0].DeliverySequence)
+	require.WithinDuration(s.T(), start.Add(10*time.Minute), *orders[0].ETA, time.Minute)
+
+	require.Equal(s.T(), 1.0, orders[1].Weight)
+	require.Equal(s.T(), int32(2), *orders[1].DeliverySequence)
+	require.WithinDuration(s.T(), start.Add(30*time.Minute), *orders[1].ETA, time.Minute)
+	require.True(s.T(), orders[1].ETA.Equal(orders[1].CompletedTime))
+
+	// persisted on the order
+	resp, err := http.Get(fmt.Sprintf("%s/%d", ORDER_GET_ALL_URL, orders[1].ID))
+	require.NoError(s.T(), err, "HTTP error")
+	defer resp.Body.Close()
+	require.Equal(s.T(), http.StatusOK, resp.StatusCode, "HTTP status code")
+
+	var stored sequencedOrder
+	require.NoError(s.T(), tests.ResponseToStruct(resp.Body, &stored), "Unmarshall")
+	require.Equal(s.T(), int32(2), *stored.DeliverySequence)
+	require.True(s.T(), orders[1].ETA.Equal(*stored.ETA))
+}
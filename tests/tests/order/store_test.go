@@ -0,0 +1,200 @@
+package order
+
+import (
+	"fmt"
+	"net/http"
+	"tests/tests"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func (s *OrderTestSuite) TestAssignBatchesOrdersOfOneStore() {
+	stores := s.createStores(`{"stores": [
+		{"name": "Center", "region": 1, "location": {"lat": 55.75, "lon": 37.61}, "pickup_minutes": 30},
+		{"name": "North", "region": 1, "location": {"lat": 55.85, "lon": 37.61}}
+	]}`)
+	require.Len(s.T(), stores, 2)
+
+	s.createCourier(fmt.Sprintf(`{"couriers": [{"courier_type": "AUTO", "regions": [1], "working_hours": ["10:00-14:00"], "shift_stores": {"10:00-14:00": %d}}]}`, stores[0]))
+	orders := s.createOrders(fmt.Sprintf(`{"orders": [
+		{"weight": 1, "regions": 1, "delivery_hours": ["10:00-14:00"], "cost": 100, "store_id": %d},
+		{"weight": 1, "regions": 1, "delivery_hours": ["10:00-14:00"], "cost": 100, "store_id": %d},
+		{"weight": 1, "regions": 1, "delivery_hours": ["10:00-14:00"], "cost": 100, "store_id": %d}
+	]}`, stores[0], stores[1], stores[0]))
+	require.Len(s.T(), orders, 3)
+
+	resp := s.post(ORDER_GET_ALL_URL+"/assign?date=2023-08-01", "")
+	defer resp.Body.Close()
+	require.Equal(s.T(), http.StatusOK, resp.StatusCode, "HTTP status code")
+
+	var res []struct {
+		Couriers []struct {
+			Orders []struct {
+				Orders []struct {
+					ID      uint64 `json:"order_id"`
+					StoreID uint64 `json:"store_id"`
+				} `json:"orders"`
+			} `json:"orders"`
+		} `json:"couriers"`
+	}
+	require.NoError(s.T(), tests.ResponseToStruct(resp.Body, &res), "Unmarshall")
+	require.Len(s.T(), res, 1)
+	require.Len(s.T(), res[0].Couriers, 1)
+
+	// orders of different stores never share a delivery group
+	require.Len(s.T(), res[0].Couriers[0].Orders, 2)
+	for _, g := range res[0].Couriers[0].Orders {
+		for _, o := range g.Orders {
+			require.Equal(s.T(), g.Orders[0].StoreID, o.StoreID)
+		}
+	}
+
+	// the shift starts at the courier store, orders are collected first
+	for _, id := range orders {
+		completed := s.completedTime(id)
+		require.NotNil(s.T(), completed)
+		require.False(s.T(), completed.Before(time.Date(2023, 8, 1, 10, 30, 0, 0, time.UTC)))
+	}
+}
+
+func (s *OrderTestSuite) TestEachShiftStartsAtItsStore() {
+	stores := s.createStores(`{"stores": [
+		{"name": "Center", "region": 1, "location": {"lat": 55.75, "lon": 37.61}},
+		{"name": "North", "region": 1, "location": {"lat": 55.85, "lon": 37.61}, "pickup_minutes": 30}
+	]}`)
+	require.Len(s.T(), stores, 2)
+
+	courierId := s.createCourier(fmt.Sprintf(`{"couriers": [{"courier_type": "AUTO", "regions": [1], "working_hours": ["10:00-12:00", "14:00-16:00"], "shift_stores": {"10:00-12:00": %d, "14:00-16:00": %d}}]}`, stores[0], stores[1]))
+	require.Equal(s.T(), 2, s.countRows("courier_working_hours"))
+
+	// the courier is returned with the stores it was created with
+	resp, err := http.Get(fmt.Sprintf("%s/%d", COURIERS_URL, courierId))
+	require.NoError(s.T(), err, "HTTP error")
+	defer resp.Body.Close()
+	require.Equal(s.T(), http.StatusOK, resp.StatusCode, "HTTP status code")
+
+	var courier struct {
+		ShiftStores map[string]uint64 `json:"shift_stores"`
+	}
+	require.NoError(s.T(), tests.ResponseToStruct(resp.Body, &courier), "Unmarshall")
+	require.Equal(s.T(), map[string]uint64{"10:00-12:00": stores[0], "14:00-16:00": stores[1]}, courier.ShiftStores)
+
+	var atNorth int
+	err = s.pgSuite.Pgx.QueryRow(s.pgSuite.Ctx, `SELECT COUNT(*) FROM courier_working_hours WHERE store_id = $1`, stores[1]).Scan(&atNorth)
+	require.NoError(s.T(), err)
+	require.Equal(s.T(), 1, atNorth)
+
+	orders := s.createOrders(fmt.Sprintf(`{"orders": [
+		{"weight": 1, "regions": 1, "delivery_hours": ["14:00-16:00"], "cost": 100, "store_id": %d}
+	]}`, stores[1]))
+	require.Len(s.T(), orders, 1)
+
+	resp = s.post(ORDER_GET_ALL_URL+"/assign?date=2023-08-01", "")
+	defer resp.Body.Close()
+	require.Equal(s.T(), http.StatusOK, resp.StatusCode, "HTTP status code")
+
+	// the afternoon shift starts at North and waits for its pickup
+	completed := s.completedTime(orders[0])
+	require.NotNil(s.T(), completed)
+	require.False(s.T(), completed.Before(time.Date(2023, 8, 1, 14, 30, 0, 0, time.UTC)))
+}
+
+func (s *OrderTestSuite) TestCreateWithUnknownStore() {
+	resp := s.post(ORDER_GET_ALL_URL, `{"orders": [{"weight": 1, "regions": 1, "delivery_hours": ["10:00-12:00"], "cost": 100, "store_id": 42}]}`)
+	defer resp.Body.Close()
+	require.Equal(s.T(), http.StatusBadRequest, resp.StatusCode, "unknown order store")
+
+	resp = s.post(COURIERS_URL, `{"couriers": [{"courier_type": "AUTO", "regions": [1], "working_hours": ["10:00-12:00"], "shift_stores": {"10:00-12:00": 42}}]}`)
+	defer resp.Body.Close()
+	require.Equal(s.T(), http.StatusBadRequest, resp.StatusCode, "unknown shift store")
+
+	stores := s.createStores(`{"stores": [{"name": "Center", "region": 2, "location": {"lat": 55.75, "lon": 37.61}}]}`)
+	require.Len(s.T(), stores, 1)
+
+	resp = s.post(ORDER_GET_ALL_URL, fmt.Sprintf(`{"orders": [{"weight": 1, "regions": 1, "delivery_hours": ["10:00-12:00"], "cost": 100, "store_id": %d}]}`, stores[0]))
+	defer resp.Body.Close()
+	require.Equal(s.T(), http.StatusBadRequest, resp.StatusCode, "store in another region")
+
+	resp = s.post(COURIERS_URL, fmt.Sprintf(`{"couriers": [{"courier_type": "AUTO", "regions": [2], "working_hours": ["10:00-12:00"], "shift_stores": {"14:00-16:00": %d}}]}`, stores[0]))
+	defer resp.Body.Close()
+	require.Equal(s.T(), http.StatusBadRequest, resp.StatusCode, "shift store for unknown working hours")
+}
+
+type storeItem struct {
+	StoreId  uint64 `json:"store_id"`
+	Name     string `json:"name"`
+	Region   int32  `json:"region"`
+	Location struct {
+		Lat float64 `json:"lat"`
+		Lon float64 `json:"lon"`
+	} `json:"location"`
+	PickupMinutes uint32 `json:"pickup_minutes"`
+}
+
+func (s *OrderTestSuite) TestStoreUpdateAndDelete() {
+	stores := s.createStores(`{"stores": [{"name": "Center", "region": 1, "location": {"lat": 55.75, "lon": 37.61}}]}`)
+	require.Len(s.T(), stores, 1)
+	url := fmt.Sprintf("%s/%d", STORES_URL, stores[0])
+
+	s.createCourier(fmt.Sprintf(`{"couriers": [{"courier_type": "AUTO", "regions": [1], "working_hours": ["10:00-14:00"], "shift_stores": {"10:00-14:00": %d}}]}`, stores[0]))
+	orders := s.createOrders(fmt.Sprintf(`{"orders": [{"weight": 1, "regions": 1, "delivery_hours": ["10:00-14:00"], "cost": 100, "store_id": %d}]}`, stores[0]))
+	require.Len(s.T(), orders, 1)
+
+	// PUT replaces all fields of the store
+	resp := s.send(http.MethodPut, url, `{"name": "Downtown", "region": 1, "location": {"lat": 55.76, "lon": 37.62}, "pickup_minutes": 15}`)
+	defer resp.Body.Close()
+	require.Equal(s.T(), http.StatusOK, resp.StatusCode, "HTTP status code")
+
+	resp, err := http.Get(url)
+	require.NoError(s.T(), err, "HTTP error")
+	defer resp.Body.Close()
+	require.Equal(s.T(), http.StatusOK, resp.StatusCode, "HTTP status code")
+
+	var st storeItem
+	require.NoError(s.T(), tests.ResponseToStruct(resp.Body, &st), "Unmarshall")
+	require.Equal(s.T(), stores[0], st.StoreId)
+	require.Equal(s.T(), "Downtown", st.Name)
+	require.Equal(s.T(), 55.76, st.Location.Lat)
+	require.Equal(s.T(), 37.62, st.Location.Lon)
+	require.Equal(s.T(), uint32(15), st.PickupMinutes)
+
+	invalid := []struct {
+		url    string
+		body   string
+		status int
+	}{
+		{url, `{"name": "Nowhere", "region": 1}`, http.StatusBadRequest},
+		{STORES_URL + "/0", `{"name": "Zero", "region": 1, "location": {"lat": 55.75, "lon": 37.61}}`, http.StatusBadRequest},
+		{fmt.Sprintf("%s/%d", STORES_URL, stores[0]+1), `{"name": "Lost", "region": 1, "location": {"lat": 55.75, "lon": 37.61}}`, http.StatusNotFound},
+	}
+	for _, req := range invalid {
+		resp := s.send(http.MethodPut, req.url, req.body)
+		defer resp.Body.Close()
+		require.Equal(s.T(), req.status, resp.StatusCode, req.body)
+	}
+
+	resp = s.send(http.MethodDelete, url, "")
+	defer resp.Body.Close()
+	require.Equal(s.T(), http.StatusNoContent, resp.StatusCode, "HTTP status code")
+
+	resp, err = http.Get(url)
+	require.NoError(s.T(), err, "HTTP error")
+	defer resp.Body.Close()
+	require.Equal(s.T(), http.StatusNotFound, resp.StatusCode, "HTTP status code")
+
+	resp = s.send(http.MethodDelete, url, "")
+	defer resp.Body.Close()
+	require.Equal(s.T(), http.StatusNotFound, resp.StatusCode, "already deleted")
+
+	// orders and shifts of the deleted store are kept without a store
+	require.Equal(s.T(), 1, s.countRows("orders"))
+	require.Equal(s.T(), 1, s.countRows("courier_working_hours"))
+
+	var withStore int
+	err = s.pgSuite.Pgx.QueryRow(s.pgSuite.Ctx, `
+		SELECT (SELECT COUNT(*) FROM orders WHERE store_id IS NOT NULL)
+			+ (SELECT COUNT(*) FROM courier_working_hours WHERE store_id IS NOT NULL)`).Scan(&withStore)
+	require.NoError(s.T(), err)
+	require.Equal(s.T(), 0, withStore)
+}
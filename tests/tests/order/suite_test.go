@@ -0,0 +1,141 @@
+package order
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"testing"
+	"tests/suites/postgres"
+	"tests/tests"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+var REGIONS_URL string = fmt.Sprintf("%s/regions", os.Getenv("host"))
+var COURIERS_URL string = fmt.Sprintf("%s/couriers", os.Getenv("host"))
+var STORES_URL string = fmt.Sprintf("%s/stores", os.Getenv("host"))
+
+type OrderTestSuite struct {
+	suite.Suite
+	pgSuite   *postgres.Suite
+	ctx       context.Context
+	ctxCancel context.CancelFunc
+}
+
+func (s *OrderTestSuite) SetupSuite() {
+	s.ctx, s.ctxCancel = context.WithCancel(context.Background())
+	s.pgSuite = postgres.SetupInstance(s.ctx)
+}
+
+func (s *OrderTestSuite) TearDownSuite() {
+	s.pgSuite.TearDownInstance()
+	s.ctxCancel()
+}
+
+func (s *OrderTestSuite) TearDownTest() {
+	s.pgSuite.TruncateAll()
+}
+
+func TestOrderTestSuite(t *testing.T) {
+	suite.Run(t, new(OrderTestSuite))
+}
+
+func (s *OrderTestSuite) post(url, body string) *http.Response {
+	resp, err := http.Post(url, "application/json", strings.NewReader(body))
+	require.NoError(s.T(), err, "HTTP error")
+
+	return resp
+}
+
+func (s *OrderTestSuite) send(method, url, body string) *http.Response {
+	req, err := http.NewRequest(method, url, strings.NewReader(body))
+	require.NoError(s.T(), err)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(s.T(), err, "HTTP error")
+
+	return resp
+}
+
+func (s *OrderTestSuite) createStores(body string) []uint64 {
+	resp := s.post(STORES_URL, body)
+	defer resp.Body.Close()
+	require.Equal(s.T(), http.StatusOK, resp.StatusCode, "HTTP status code")
+
+	var stores struct {
+		Stores []struct {
+			ID uint64 `json:"store_id"`
+		} `json:"stores"`
+	}
+	require.NoError(s.T(), tests.ResponseToStruct(resp.Body, &stores), "Unmarshall")
+
+	res := []uint64{}
+	for _, st := range stores.Stores {
+		res = append(res, st.ID)
+	}
+
+	return res
+}
+
+func (s *OrderTestSuite) createCourier(body string) uint64 {
+	resp := s.post(COURIERS_URL, body)
+	defer resp.Body.Close()
+	require.Equal(s.T(), http.StatusOK, resp.StatusCode, "HTTP status code")
+
+	var couriers struct {
+		Couriers []struct {
+			ID uint64 `json:"courier_id"`
+		} `json:"couriers"`
+	}
+	require.NoError(s.T(), tests.ResponseToStruct(resp.Body, &couriers), "Unmarshall")
+	require.Len(s.T(), couriers.Couriers, 1)
+
+	return couriers.Couriers[0].ID
+}
+
+func (s *OrderTestSuite) createOrders(body string) []uint64 {
+	resp := s.post(ORDER_GET_ALL_URL, body)
+	defer resp.Body.Close()
+	require.Equal(s.T(), http.StatusOK, resp.StatusCode, "HTTP status code")
+
+	var orders struct {
+		Orders []struct {
+			ID uint64 `json:"order_id"`
+		} `json:"orders"`
+	}
+	require.NoError(s.T(), tests.ResponseToStruct(resp.Body, &orders), "Unmarshall")
+
+	res := []uint64{}
+	for _, o := range orders.Orders {
+		res = append(res, o.ID)
+	}
+
+	return res
+}
+
+func (s *OrderTestSuite) completedTime(id uint64) *time.Time {
+	resp, err := http.Get(fmt.Sprintf("%s/%d", ORDER_GET_ALL_URL, id))
+	require.NoError(s.T(), err, "HTTP error")
+	defer resp.Body.Close()
+	require.Equal(s.T(), http.StatusOK, resp.StatusCode, "HTTP status code")
+
+	var o struct {
+		CompletedTime *time.Time `json:"completed_time"`
+	}
+	require.NoError(s.T(), tests.ResponseToStruct(resp.Body, &o), "Unmarshall")
+
+	return o.CompletedTime
+}
+
+func (s *OrderTestSuite) countRows(table string) int {
+	var cnt int
+	err := s.pgSuite.Pgx.QueryRow(s.pgSuite.Ctx, "SELECT COUNT(*) FROM "+table).Scan(&cnt)
+	require.NoError(s.T(), err)
+
+	return cnt
+}
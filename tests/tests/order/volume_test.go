@@ -0,0 +1,64 @@
+package order
+
+import (
+	"net/http"
+	"tests/tests"
+
+	"github.com/stretchr/testify/require"
+)
+
+func (s *OrderTestSuite) TestAssignRespectsVolume() {
+	s.createCourier(`{"couriers": [{"courier_type": "FOOT", "regions": [1], "working_hours": ["10:00-12:00"]}]}`)
+
+	resp := s.post(ORDER_GET_ALL_URL, `{"orders": [
+		{"weight": 1, "regions": 1, "delivery_hours": ["10:00-12:00"], "cost": 100, "volume": 30},
+		{"weight": 1, "regions": 1, "delivery_hours": ["10:00-12:00"], "cost": 100, "volume": 30},
+		{"weight": 1, "regions": 1, "delivery_hours": ["10:00-12:00"], "cost": 100, "dimensions": {"length": 50, "width": 50, "height": 50}}
+	]}`)
+	defer resp.Body.Close()
+	require.Equal(s.T(), http.StatusOK, resp.StatusCode, "HTTP status code")
+
+	var created struct {
+		Orders []struct {
+			ID     uint64   `json:"order_id"`
+			Volume *float64 `json:"volume"`
+		} `json:"orders"`
+	}
+	require.NoError(s.T(), tests.ResponseToStruct(resp.Body, &created), "Unmarshall")
+	require.Len(s.T(), created.Orders, 3)
+
+	// volume of the box
+	require.NotNil(s.T(), created.Orders[2].Volume)
+	require.Equal(s.T(), 125.0, *created.Orders[2].Volume)
+
+	resp = s.post(ORDER_GET_ALL_URL+"/assign?date=2023-08-01", "")
+	defer resp.Body.Close()
+	require.Equal(s.T(), http.StatusOK, resp.StatusCode, "HTTP status code")
+
+	var res []struct {
+		Couriers []struct {
+			Orders []struct {
+				Orders []struct {
+					ID uint64 `json:"order_id"`
+				} `json:"orders"`
+			} `json:"orders"`
+		} `json:"couriers"`
+	}
+	require.NoError(s.T(), tests.ResponseToStruct(resp.Body, &res), "Unmarshall")
+	require.Len(s.T(), res, 1)
+	require.Len(s.T(), res[0].Couriers, 1)
+
+	// two orders of 30 l don't fit a 40 l backpack together
+	require.Len(s.T(), res[0].Couriers[0].Orders, 2)
+	for _, g := range res[0].Couriers[0].Orders {
+		require.Len(s.T(), g.Orders, 1)
+	}
+
+	require.Nil(s.T(), s.completedTime(created.Orders[2].ID))
+}
+
+func (s *OrderTestSuite) TestCreateInvalidDimensions() {
+	resp := s.post(ORDER_GET_ALL_URL, `{"orders": [{"weight": 1, "regions": 1, "delivery_hours": ["10:00-12:00"], "cost": 100, "dimensions": {"length": 10, "width": 0, "height": 10}}]}`)
+	defer resp.Body.Close()
+	require.Equal(s.T(), http.StatusBadRequest, resp.StatusCode, "HTTP status code")
+}
@@ -0,0 +1,62 @@
+package order
+
+import (
+	"fmt"
+	"net/http"
+	"tests/tests"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func (s *OrderTestSuite) TestAssignChoosesDeliveryWindow() {
+	resp := s.post(COURIERS_URL, `{"couriers": [{"courier_type": "BIKE", "regions": [1], "working_hours": ["10:00-11:00"]}]}`)
+	defer resp.Body.Close()
+	require.Equal(s.T(), http.StatusOK, resp.StatusCode, "HTTP status code")
+
+	// the first order fits only its second window, the second one closes
+	// before a bike can get there
+	resp = s.post(ORDER_GET_ALL_URL, `{"orders": [
+		{"weight": 1, "regions": 1, "delivery_hours": ["12:00-13:00", "10:00-10:30"], "cost": 100},
+		{"weight": 1, "regions": 1, "delivery_hours": ["10:00-10:05"], "cost": 100}
+	]}`)
+	defer resp.Body.Close()
+	require.Equal(s.T(), http.StatusOK, resp.StatusCode, "HTTP status code")
+
+	var created struct {
+		Orders []struct {
+			ID uint64 `json:"order_id"`
+		} `json:"orders"`
+	}
+	require.NoError(s.T(), tests.ResponseToStruct(resp.Body, &created), "Unmarshall")
+	require.Len(s.T(), created.Orders, 2)
+
+	resp = s.post(ORDER_GET_ALL_URL+"/assign?date=2023-08-01", "")
+	defer resp.Body.Close()
+	require.Equal(s.T(), http.StatusOK, resp.StatusCode, "HTTP status code")
+
+	type order struct {
+		CompletedTime  *time.Time `json:"completed_time"`
+		DeliveryWindow *string    `json:"delivery_window"`
+	}
+	get := func(id uint64) order {
+		resp, err := http.Get(fmt.Sprintf("%s/%d", ORDER_GET_ALL_URL, id))
+		require.NoError(s.T(), err, "HTTP error")
+		defer resp.Body.Close()
+		require.Equal(s.T(), http.StatusOK, resp.StatusCode, "HTTP status code")
+
+		var o order
+		require.NoError(s.T(), tests.ResponseToStruct(resp.Body, &o), "Unmarshall")
+
+		return o
+	}
+
+	assigned := get(created.Orders[0].ID)
+	require.NotNil(s.T(), assigned.DeliveryWindow)
+	require.Equal(s.T(), "10:00-10:30", *assigned.DeliveryWindow)
+	require.Equal(s.T(), time.Date(2023, 8, 1, 10, 12, 0, 0, time.UTC), assigned.CompletedTime.UTC())
+
+	missed := get(created.Orders[1].ID)
+	require.Nil(s.T(), missed.CompletedTime)
+	require.Nil(s.T(), missed.DeliveryWindow)
+}
@@ -0,0 +1,186 @@
+package region
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"tests/tests"
+
+	"github.com/stretchr/testify/require"
+)
+
+var REGIONS_URL string = fmt.Sprintf("%s/regions", os.Getenv("host"))
+var COURIERS_URL string = fmt.Sprintf("%s/couriers", os.Getenv("host"))
+var ORDERS_URL string = fmt.Sprintf("%s/orders", os.Getenv("host"))
+
+type regionItem struct {
+	RegionId   int32   `json:"region_id"`
+	Name       string  `json:"name"`
+	Active     bool    `json:"active"`
+	Neighbours []int32 `json:"neighbours"`
+}
+
+type regionsResponse struct {
+	Regions []regionItem `json:"regions"`
+}
+
+func (s *RegionTestSuite) send(method, url, body string) *http.Response {
+	req, err := http.NewRequest(method, url, strings.NewReader(body))
+	require.NoError(s.T(), err)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(s.T(), err, "HTTP error")
+
+	return resp
+}
+
+func (s *RegionTestSuite) createRegions(body string) {
+	resp := s.send(http.MethodPost, REGIONS_URL, body)
+	defer resp.Body.Close()
+
+	require.Equal(s.T(), http.StatusOK, resp.StatusCode, "HTTP status code")
+}
+
+func (s *RegionTestSuite) TestCrud() {
+	s.createRegions(`{"regions": [
+		{"region_id": 1, "name": "Center"},
+		{"region_id": 2, "name": "North", "neighbours": [1]},
+		{"region_id": 3, "name": "South", "active": false, "neighbours": [1, 2]}
+	]}`)
+
+	resp, err := http.Get(REGIONS_URL)
+	require.NoError(s.T(), err, "HTTP error")
+	defer resp.Body.Close()
+
+	var all regionsResponse
+	require.NoError(s.T(), tests.ResponseToStruct(resp.Body, &all), "Unmarshall")
+	require.Equal(s.T(), []regionItem{
+		{RegionId: 1, Name: "Center", Active: true, Neighbours: []int32{}},
+		{RegionId: 2, Name: "North", Active: true, Neighbours: []int32{1}},
+		{RegionId: 3, Name: "South", Active: false, Neighbours: []int32{1, 2}},
+	}, all.Regions)
+
+	resp = s.send(http.MethodPut, REGIONS_URL+"/1", `{"name": "Downtown", "active": true, "neighbours": [3]}`)
+	defer resp.Body.Close()
+	require.Equal(s.T(), http.StatusOK, resp.StatusCode, "HTTP status code")
+
+	resp = s.send(http.MethodDelete, REGIONS_URL+"/3", "")
+	defer resp.Body.Close()
+	require.Equal(s.T(), http.StatusNoContent, resp.StatusCode, "HTTP status code")
+
+	resp, err = http.Get(REGIONS_URL + "/1")
+	require.NoError(s.T(), err, "HTTP error")
+	defer resp.Body.Close()
+
+	var r regionItem
+	require.NoError(s.T(), tests.ResponseToStruct(resp.Body, &r), "Unmarshall")
+	require.Equal(s.T(), regionItem{RegionId: 1, Name: "Downtown", Active: true, Neighbours: []int32{}}, r)
+
+	resp, err = http.Get(REGIONS_URL + "/3")
+	require.NoError(s.T(), err, "HTTP error")
+	defer resp.Body.Close()
+	require.Equal(s.T(), http.StatusNotFound, resp.StatusCode, "HTTP status code")
+}
+
+func (s *RegionTestSuite) TestCreateExpectValidationErrors() {
+	s.createRegions(`{"regions": [{"region_id": 1, "name": "Center"}]}`)
+
+	requests := []string{
+		`{"regions": [{"region_id": 1, "name": "Again"}]}`,
+		`{"regions": [{"region_id": 2, "name": "Self", "neighbours": [2]}]}`,
+		`{"regions": [{"region_id": 2, "name": "Lost", "neighbours": [42]}]}`,
+		`{"regions": [{"region_id": -2, "name": "Negative"}]}`,
+		`{"regions": [{"region_id": 2}]}`,
+	}
+
+	for _, body := range requests {
+		resp := s.send(http.MethodPost, REGIONS_URL, body)
+		defer resp.Body.Close()
+
+		require.Equal(s.T(), http.StatusBadRequest, resp.StatusCode, body)
+	}
+}
+
+func (s *RegionTestSuite) TestCreationValidatesActiveRegions() {
+	// empty catalog accepts any region
+	resp := s.send(http.MethodPost, ORDERS_URL, `{"orders": [{"weight": 1, "regions": 7, "delivery_hours": ["10:00-12:00"], "cost": 100}]}`)
+	defer resp.Body.Close()
+	require.Equal(s.T(), http.StatusOK, resp.StatusCode, "HTTP status code")
+
+	s.createRegions(`{"regions": [
+		{"region_id": 1, "name": "Center"},
+		{"region_id": 2, "name": "Closed", "active": false}
+	]}`)
+
+	requests := []struct {
+		url    string
+		body   string
+		status int
+	}{
+		{ORDERS_URL, `{"orders": [{"weight": 1, "regions": 1, "delivery_hours": ["10:00-12:00"], "cost": 100}]}`, http.StatusOK},
+		{ORDERS_URL, `{"orders": [{"weight": 1, "regions": 2, "delivery_hours": ["10:00-12:00"], "cost": 100}]}`, http.StatusBadRequest},
+		{ORDERS_URL, `{"orders": [{"weight": 1, "regions": 7, "delivery_hours": ["10:00-12:00"], "cost": 100}]}`, http.StatusBadRequest},
+		{COURIERS_URL, `{"couriers": [{"courier_type": "BIKE", "regions": [1], "working_hours": ["10:00-12:00"]}]}`, http.StatusOK},
+		{COURIERS_URL, `{"couriers": [{"courier_type": "BIKE", "regions": [1, 2], "working_hours": ["10:00-12:00"]}]}`, http.StatusBadRequest},
+	}
+
+	for _, r := range requests {
+		resp := s.send(http.MethodPost, r.url, r.body)
+		defer resp.Body.Close()
+
+		require.Equal(s.T(), r.status, resp.StatusCode, r.body)
+	}
+}
+
+func (s *RegionTestSuite) TestAssignCombinesNeighbouringRegions() {
+	s.createRegions(`{"regions": [
+		{"region_id": 1, "name": "Center"},
+		{"region_id": 2, "name": "North", "neighbours": [1]},
+		{"region_id": 3, "name": "Far"}
+	]}`)
+
+	resp := s.send(http.MethodPost, COURIERS_URL, `{"couriers": [{"courier_type": "BIKE", "regions": [1, 2, 3], "working_hours": ["10:00-11:00"]}]}`)
+	defer resp.Body.Close()
+	require.Equal(s.T(), http.StatusOK, resp.StatusCode, "HTTP status code")
+
+	resp = s.send(http.MethodPost, ORDERS_URL, `{"orders": [
+		{"weight": 5, "regions": 1, "delivery_hours": ["10:00-11:00"], "cost": 100},
+		{"weight": 5, "regions": 2, "delivery_hours": ["10:00-11:00"], "cost": 100},
+		{"weight": 5, "regions": 3, "delivery_hours": ["10:00-11:00"], "cost": 100}
+	]}`)
+	defer resp.Body.Close()
+	require.Equal(s.T(), http.StatusOK, resp.StatusCode, "HTTP status code")
+
+	resp = s.send(http.MethodPost, ORDERS_URL+"/assign?date=2023-08-01", "")
+	defer resp.Body.Close()
+	require.Equal(s.T(), http.StatusOK, resp.StatusCode, "HTTP status code")
+
+	var res []struct {
+		Couriers []struct {
+			Orders []struct {
+				Orders []struct {
+					Regions int32 `json:"regions"`
+				} `json:"orders"`
+			} `json:"orders"`
+		} `json:"couriers"`
+	}
+	require.NoError(s.T(), tests.ResponseToStruct(resp.Body, &res), "Unmarshall")
+	require.Len(s.T(), res[0].Couriers, 1)
+
+	// the first group stays within adjacent regions 1 and 2
+	groups := res[0].Couriers[0].Orders
+	require.NotEmpty(s.T(), groups)
+
+	regions := map[int32]bool{}
+	for _, g := range groups {
+		if len(g.Orders) < 2 {
+			continue
+		}
+		for _, o := range g.Orders {
+			regions[o.Regions] = true
+		}
+	}
+	require.Equal(s.T(), map[int32]bool{1: true, 2: true}, regions)
+}
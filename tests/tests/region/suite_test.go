@@ -0,0 +1,34 @@
+package region
+
+import (
+	"context"
+	"testing"
+	"tests/suites/postgres"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type RegionTestSuite struct {
+	suite.Suite
+	pgSuite   *postgres.Suite
+	ctx       context.Context
+	ctxCancel context.CancelFunc
+}
+
+func (s *RegionTestSuite) SetupSuite() {
+	s.ctx, s.ctxCancel = context.WithCancel(context.Background())
+	s.pgSuite = postgres.SetupInstance(s.ctx)
+}
+
+func (s *RegionTestSuite) TearDownSuite() {
+	s.pgSuite.TearDownInstance()
+	s.ctxCancel()
+}
+
+func (s *RegionTestSuite) TearDownTest() {
+	s.pgSuite.TruncateAll()
+}
+
+func TestRegionTestSuite(t *testing.T) {
+	suite.Run(t, new(RegionTestSuite))
+}
@@ -0,0 +1,131 @@
+package region
+
+import (
+	"fmt"
+	"net/http"
+	"tests/tests"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func (s *RegionTestSuite) TestTimeZones() {
+	s.createRegions(`{"regions": [
+		{"region_id": 1, "name": "Moscow", "time_zone": "Europe/Moscow"},
+		{"region_id": 2, "name": "Yekaterinburg", "time_zone": "Asia/Yekaterinburg"},
+		{"region_id": 3, "name": "Default"}
+	]}`)
+
+	resp := s.send(http.MethodGet, REGIONS_URL+"/3", "")
+	defer resp.Body.Close()
+	var r struct {
+		TimeZone string `json:"time_zone"`
+	}
+	require.NoError(s.T(), tests.ResponseToStruct(resp.Body, &r), "Unmarshall")
+	require.Equal(s.T(), "UTC", r.TimeZone)
+
+	invalid := []struct {
+		url  string
+		body string
+	}{
+		{REGIONS_URL, `{"regions": [{"region_id": 4, "name": "Mars", "time_zone": "Mars/Olympus"}]}`},
+		{REGIONS_URL, `{"regions": [{"region_id": 4, "name": "Local", "time_zone": "Local"}]}`},
+		// working hours must have one meaning
+		{COURIERS_URL, `{"couriers": [{"courier_type": "BIKE", "regions": [1, 2], "working_hours": ["10:00-11:00"]}]}`},
+	}
+	for _, i := range invalid {
+		resp := s.send(http.MethodPost, i.url, i.body)
+		defer resp.Body.Close()
+		require.Equal(s.T(), http.StatusBadRequest, resp.StatusCode, i.body)
+	}
+
+	resp = s.send(http.MethodPost, COURIERS_URL, `{"couriers": [{"courier_type": "BIKE", "regions": [1], "working_hours": ["10:00-11:00"]}]}`)
+	defer resp.Body.Close()
+	require.Equal(s.T(), http.StatusOK, resp.StatusCode, "HTTP status code")
+	var couriers struct {
+		Couriers []struct {
+			ID uint64 `json:"courier_id"`
+		} `json:"couriers"`
+	}
+	require.NoError(s.T(), tests.ResponseToStruct(resp.Body, &couriers), "Unmarshall")
+
+	resp = s.send(http.MethodPost, ORDERS_URL, `{"orders": [
+		{"weight": 1, "regions": 1, "delivery_hours": ["10:00-11:00"], "cost": 100},
+		{"weight": 50, "regions": 1, "delivery_hours": ["10:00-11:00"], "cost": 100}
+	]}`)
+	defer resp.Body.Close()
+	require.Equal(s.T(), http.StatusOK, resp.StatusCode, "HTTP status code")
+	var orders struct {
+		Orders []struct {
+			ID uint64 `json:"order_id"`
+		} `json:"orders"`
+	}
+	require.NoError(s.T(), tests.ResponseToStruct(resp.Body, &orders), "Unmarshall")
+
+	resp = s.send(http.MethodPost, ORDERS_URL+"/assign?date=2023-08-01", "")
+	defer resp.Body.Close()
+	require.Equal(s.T(), http.StatusOK, resp.StatusCode, "HTTP status code")
+
+	completedTime := func(id uint64) string {
+		resp := s.send(http.MethodGet, fmt.Sprintf("%s/%d", ORDERS_URL, id), "")
+		defer resp.Body.Close()
+		require.Equal(s.T(), http.StatusOK, resp.StatusCode, "HTTP status code")
+
+		var o struct {
+			CompletedTime *string `json:"completed_time"`
+		}
+		require.NoError(s.T(), tests.ResponseToStruct(resp.Body, &o), "Unmarshall")
+		require.NotNil(s.T(), o.CompletedTime)
+
+		return *o.CompletedTime
+	}
+
+	// working hours and window are Moscow clock, shown back in Moscow time
+	require.Equal(s.T(), "2023-08-01T10:12:00+03:00", completedTime(orders.Orders[0].ID))
+
+	// 07:30 UTC is within 10:00-11:00 in Moscow
+	resp = s.send(http.MethodPost, ORDERS_URL+"/complete", fmt.Sprintf(
+		`{"complete_info": [{"courier_id": %d, "order_id": %d, "complete_time": "2023-08-01T07:30:00Z"}]}`,
+		couriers.Couriers[0].ID,
+		orders.Orders[1].ID,
+	))
+	defer resp.Body.Close()
+	require.Equal(s.T(), http.StatusOK, resp.StatusCode, "HTTP status code")
+
+	completed, err := time.Parse(time.RFC3339, completedTime(orders.Orders[1].ID))
+	require.NoError(s.T(), err)
+	require.Equal(s.T(), time.Date(2023, 8, 1, 7, 30, 0, 0, time.UTC), completed.UTC())
+}
+
+func (s *RegionTestSuite) TestTimeZoneChangeKeepsCouriersInOneZone() {
+	s.createRegions(`{"regions": [
+		{"region_id": 1, "name": "Moscow", "time_zone": "Europe/Moscow"},
+		{"region_id": 2, "name": "Khimki", "time_zone": "Europe/Moscow"},
+		{"region_id": 3, "name": "Tver", "time_zone": "Europe/Moscow"}
+	]}`)
+
+	resp := s.send(http.MethodPost, COURIERS_URL, `{"couriers": [
+		{"courier_type": "BIKE", "regions": [1, 2], "working_hours": ["10:00-11:00"]},
+		{"courier_type": "AUTO", "regions": [3], "working_hours": ["10:00-11:00"]}
+	]}`)
+	defer resp.Body.Close()
+	require.Equal(s.T(), http.StatusOK, resp.StatusCode, "HTTP status code")
+
+	// courier of region 2 also works in region 1, left in Moscow time
+	resp = s.send(http.MethodPut, REGIONS_URL+"/2", `{"name": "Khimki", "active": true, "time_zone": "Asia/Yekaterinburg"}`)
+	defer resp.Body.Close()
+	require.Equal(s.T(), http.StatusBadRequest, resp.StatusCode, "zone of a courier with other-zone regions")
+
+	// all couriers of region 3 move with it
+	resp = s.send(http.MethodPut, REGIONS_URL+"/3", `{"name": "Tver", "active": true, "time_zone": "Asia/Yekaterinburg"}`)
+	defer resp.Body.Close()
+	require.Equal(s.T(), http.StatusOK, resp.StatusCode, "HTTP status code")
+
+	resp = s.send(http.MethodGet, REGIONS_URL+"/2", "")
+	defer resp.Body.Close()
+	var r struct {
+		TimeZone string `json:"time_zone"`
+	}
+	require.NoError(s.T(), tests.ResponseToStruct(resp.Body, &r), "Unmarshall")
+	require.Equal(s.T(), "Europe/Moscow", r.TimeZone)
+}